@@ -0,0 +1,439 @@
+// Package abnf imports grammars written in ABNF, the notation of RFC 5234 used by most protocol
+// specs, as goparsify parsers, so protocol parsers can be bootstrapped straight from the spec.
+//
+//	g, err := abnf.Import(`
+//		version = 1*DIGIT "." 1*DIGIT
+//	`, nil)
+//	version, _ := g.Rule("version")
+//
+// All of RFC 5234 is supported: repetition (*, 1*, 2*3, 4), groups and options, quoted strings
+// (case insensitive, or sensitive when written %s"...", RFC 7405), numeric values in binary,
+// decimal and hex including ranges and concatenations, incremental alternatives (=/), comments
+// and the core rules like ALPHA, DIGIT and CRLF of appendix B. Rule names are case insensitive.
+//
+// There are two things to keep in mind:
+//   - Alternatives are tried in order and the first that matches wins, like Any. ABNF allows
+//     any alternative to match, so a few grammars need their alternatives reordering, eg so
+//     longer literals come before their prefixes.
+//   - Prose values like <a host name> can't be compiled. Rules that use them must be given an
+//     override, which can also replace rules that are more convenient to write by hand.
+//
+// Rules don't have to start in the first column, so grammars can be indented in Go string
+// literals. Whitespace is significant in the input though, so the imported rules never skip it.
+package abnf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/ijt/goparsify"
+)
+
+// Overrides maps rule names to parsers to use instead of the rule definitions.
+type Overrides map[string]Parserish
+
+// Grammar is a set of imported rules.
+type Grammar struct {
+	rules map[string]*Parser
+}
+
+// Rule returns the parser for the named rule.
+func (g *Grammar) Rule(name string) (Parser, bool) {
+	p, ok := g.rules[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return *p, true
+}
+
+// coreRules are the rules of RFC 5234 appendix B.1, available to every grammar.
+const coreRules = `
+ALPHA  = %x41-5A / %x61-7A
+BIT    = "0" / "1"
+CHAR   = %x01-7F
+CR     = %x0D
+CRLF   = CR LF
+CTL    = %x00-1F / %x7F
+DIGIT  = %x30-39
+DQUOTE = %x22
+HEXDIG = DIGIT / "A" / "B" / "C" / "D" / "E" / "F"
+HTAB   = %x09
+LF     = %x0A
+LWSP   = *(WSP / CRLF WSP)
+OCTET  = %x00-FF
+SP     = %x20
+VCHAR  = %x21-7E
+WSP    = SP / HTAB
+`
+
+// Import compiles the ABNF rules in src. Rules named in overrides use the given parser in place
+// of their definition, and don't need to be defined at all.
+func Import(src string, overrides Overrides) (*Grammar, error) {
+	core, err := parseRules(coreRules)
+	if err != nil {
+		panic(err)
+	}
+	defs, err := parseRules(src)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := map[string]*rule{}
+	var order []*rule
+	for _, def := range core {
+		r := &rule{name: def.name, alternatives: def.alternatives, core: true}
+		rules[strings.ToLower(def.name)] = r
+		order = append(order, r)
+	}
+	for _, def := range defs {
+		key := strings.ToLower(def.name)
+		existing, ok := rules[key]
+		switch {
+		case def.incremental && !ok:
+			return nil, fmt.Errorf("abnf: offset %d: %s =/ comes before %s is defined", def.pos, def.name, def.name)
+		case def.incremental:
+			existing.alternatives = append(existing.alternatives, def.alternatives...)
+		case ok && !existing.core:
+			return nil, fmt.Errorf("abnf: offset %d: rule %s is defined more than once", def.pos, def.name)
+		case ok:
+			// Grammars often repeat the core rules, or define their own variant.
+			*existing = rule{name: def.name, alternatives: def.alternatives}
+		default:
+			r := &rule{name: def.name, alternatives: def.alternatives}
+			rules[key] = r
+			order = append(order, r)
+		}
+	}
+
+	g := &Grammar{rules: map[string]*Parser{}}
+	for key := range rules {
+		g.rules[key] = new(Parser)
+	}
+	for name, p := range overrides {
+		key := strings.ToLower(name)
+		if _, ok := g.rules[key]; !ok {
+			g.rules[key] = new(Parser)
+		}
+		*g.rules[key] = Rule(name, p)
+	}
+
+	c := &compiler{grammar: g}
+	for _, r := range order {
+		key := strings.ToLower(r.name)
+		if *g.rules[key] != nil {
+			// Overridden.
+			continue
+		}
+		c.rule = r.name
+		body, err := c.compile(expr{kind: alternationExpr, children: r.alternatives})
+		if err != nil {
+			return nil, err
+		}
+		*g.rules[key] = Rule(r.name, NoAutoWS(body))
+	}
+	return g, nil
+}
+
+type rule struct {
+	name         string
+	alternatives []expr
+	core         bool
+}
+
+type compiler struct {
+	grammar *Grammar
+	// rule is the rule being compiled, for error messages.
+	rule string
+}
+
+func (c *compiler) compile(e expr) (Parser, error) {
+	var children []Parserish
+	for _, child := range e.children {
+		p, err := c.compile(child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, p)
+	}
+
+	switch e.kind {
+	case alternationExpr:
+		if len(children) == 1 {
+			return children[0].(Parser), nil
+		}
+		return Any(children...), nil
+	case concatenationExpr:
+		if len(children) == 1 {
+			return children[0].(Parser), nil
+		}
+		return Seq(children...), nil
+	case optionExpr:
+		return Maybe(children[0]), nil
+	case repetitionExpr:
+		return repeat(children[0].(Parser), e.min, e.max), nil
+	case stringExpr:
+		if e.caseSensitive {
+			return Exact(e.text), nil
+		}
+		return Insensitive(e.text), nil
+	case rangeExpr:
+		return runeRange(e.lo, e.hi), nil
+	case ruleExpr:
+		p, ok := c.grammar.rules[strings.ToLower(e.text)]
+		if !ok {
+			return nil, fmt.Errorf("abnf: offset %d: undefined rule %s", e.pos, e.text)
+		}
+		return Parsify(p), nil
+	case proseExpr:
+		return nil, fmt.Errorf("abnf: offset %d: rule %s uses the prose value %s, give it an override", e.pos, c.rule, e.text)
+	}
+	panic(fmt.Errorf("unknown expression kind %d", e.kind))
+}
+
+// repeat matches p between min and max times, or any number of times over min if max is -1,
+// and returns the matches as .Child[n].
+func repeat(p Parser, min, max int) Parser {
+	return NewParser("repeat", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		node.Child = nil
+		for max == -1 || len(node.Child) < max {
+			var child Result
+			before := ps.Mark()
+			p(ps, &child)
+			if ps.Errored() {
+				if len(node.Child) < min || ps.Committed(before) {
+					ps.Pos = startpos
+					return
+				}
+				ps.Recover()
+				break
+			}
+			node.Child = append(node.Child, child)
+			if ps.Pos == before.Pos() && len(node.Child) >= min {
+				// Matching empty again and again would never end.
+				break
+			}
+		}
+		node.Token = ps.Input[startpos:ps.Pos]
+		node.Start, node.End = startpos, ps.Pos
+	})
+}
+
+// runeRange matches a single rune between lo and hi inclusive.
+func runeRange(lo, hi rune) Parser {
+	expected := fmt.Sprintf("%%x%X-%X", lo, hi)
+	if lo == hi {
+		expected = fmt.Sprintf("%%x%X", lo)
+	}
+
+	return NewParser(expected, func(ps *State, node *Result) {
+		r, w := utf8.DecodeRuneInString(ps.Get())
+		if w == 0 || r < lo || r > hi {
+			ps.ErrorHere(expected)
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+w]
+		node.Start, node.End = ps.Pos, ps.Pos+w
+		ps.Advance(w)
+	})
+}
+
+type exprKind int
+
+const (
+	alternationExpr exprKind = iota
+	concatenationExpr
+	optionExpr
+	repetitionExpr
+	stringExpr
+	rangeExpr
+	ruleExpr
+	proseExpr
+)
+
+// expr is a node of a parsed ABNF grammar.
+type expr struct {
+	kind          exprKind
+	text          string
+	caseSensitive bool
+	lo, hi        rune
+	min, max      int
+	pos           int
+	children      []expr
+}
+
+type definition struct {
+	name         string
+	pos          int
+	incremental  bool
+	alternatives []expr
+}
+
+func parseRules(src string) ([]definition, error) {
+	result, _, err := Run(_rulelist, src, WithWhitespace(abnfWhitespace))
+	if err != nil {
+		return nil, fmt.Errorf("abnf: %w", err)
+	}
+	return result.([]definition), nil
+}
+
+// abnfWhitespace skips whitespace and ; comments.
+func abnfWhitespace(ps *State) {
+	for {
+		ASCIIWhitespace(ps)
+		if !strings.HasPrefix(ps.Get(), ";") {
+			return
+		}
+		end := strings.IndexByte(ps.Get(), '\n')
+		if end < 0 {
+			ps.Pos = len(ps.Input)
+			return
+		}
+		ps.Advance(end)
+	}
+}
+
+var (
+	_alternation Parser
+
+	_rulename  = NamedRegex("rule name", `[A-Za-z][A-Za-z0-9-]*`)
+	_definedAs = Any("=/", "=")
+
+	// _ruleRef is a rule name that isn't the start of the next rule.
+	_ruleRef = NewParser("rule name", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		_rulename(ps, node)
+		if ps.Errored() {
+			return
+		}
+		endpos := ps.Pos
+		var definedAs Result
+		_definedAs(ps, &definedAs)
+		if !ps.Errored() {
+			ps.Pos = startpos
+			ps.ErrorHere("rule name")
+			return
+		}
+		ps.Recover()
+		ps.Pos = endpos
+		node.Result = expr{kind: ruleExpr, text: node.Token, pos: node.Start}
+	})
+
+	_charVal = Map(NamedRegex("quoted string", `(%[sSiI])?"[ !#-~]*"`), func(n *Result) {
+		e := expr{kind: stringExpr, pos: n.Start}
+		tok := n.Token
+		if tok[0] == '%' {
+			e.caseSensitive = tok[1] == 's' || tok[1] == 'S'
+			tok = tok[2:]
+		}
+		e.text = tok[1 : len(tok)-1]
+		n.Result = e
+	})
+
+	_numVal = Map(NamedRegex("numeric value", `%(?:[bB][01]+(?:-[01]+|(?:\.[01]+)+)?|[dD][0-9]+(?:-[0-9]+|(?:\.[0-9]+)+)?|[xX][0-9A-Fa-f]+(?:-[0-9A-Fa-f]+|(?:\.[0-9A-Fa-f]+)+)?)`), func(n *Result) {
+		n.Result = numVal(n.Token, n.Start)
+	})
+
+	_proseVal = Map(NamedRegex("prose value", `<[ -=?-~]*>`), func(n *Result) {
+		n.Result = expr{kind: proseExpr, text: n.Token, pos: n.Start}
+	})
+
+	_group = Seq("(", Cut(), &_alternation, ")").Map(func(n *Result) {
+		n.Result = n.Child[2].Result
+	})
+
+	_option = Seq("[", Cut(), &_alternation, "]").Map(func(n *Result) {
+		n.Result = expr{kind: optionExpr, children: []expr{n.Child[2].Result.(expr)}}
+	})
+
+	_element = Any(_ruleRef, _group, _option, _charVal, _numVal, _proseVal)
+
+	_repeat = Regex(`[0-9]*\*[0-9]*|[0-9]+`)
+
+	_repetition = Seq(Maybe(_repeat), _element).Map(func(n *Result) {
+		e := n.Child[1].Result.(expr)
+		if n.Child[0].Token != "" {
+			min, max := parseRepeat(n.Child[0].Token)
+			e = expr{kind: repetitionExpr, min: min, max: max, children: []expr{e}}
+		}
+		n.Result = e
+	})
+
+	_concatenation = Some(_repetition).Map(func(n *Result) {
+		e := expr{kind: concatenationExpr}
+		for _, child := range n.Child {
+			e.children = append(e.children, child.Result.(expr))
+		}
+		n.Result = e
+	})
+
+	_rule = Seq(_rulename, _definedAs, Cut(), &_alternation).Map(func(n *Result) {
+		n.Result = definition{
+			name:         n.Child[0].Token,
+			pos:          n.Child[0].Start,
+			incremental:  n.Child[1].Token == "=/",
+			alternatives: n.Child[3].Result.(expr).children,
+		}
+	})
+
+	_rulelist = Some(_rule).Map(func(n *Result) {
+		var defs []definition
+		for _, child := range n.Child {
+			defs = append(defs, child.Result.(definition))
+		}
+		n.Result = defs
+	})
+)
+
+func init() {
+	_alternation = Seq(_concatenation, Many(Seq("/", Cut(), _concatenation))).Map(func(n *Result) {
+		e := expr{kind: alternationExpr, children: []expr{n.Child[0].Result.(expr)}}
+		for _, alternative := range n.Child[1].Child {
+			e.children = append(e.children, alternative.Child[2].Result.(expr))
+		}
+		n.Result = e
+	})
+}
+
+// parseRepeat parses the repeat in front of an element, eg 1*2, * or 3.
+func parseRepeat(s string) (min, max int) {
+	star := strings.IndexByte(s, '*')
+	if star < 0 {
+		n, _ := strconv.Atoi(s)
+		return n, n
+	}
+	max = -1
+	if star > 0 {
+		min, _ = strconv.Atoi(s[:star])
+	}
+	if star < len(s)-1 {
+		max, _ = strconv.Atoi(s[star+1:])
+	}
+	return min, max
+}
+
+// numVal turns a numeric value like %x41-5A or %d13.10 into an expression.
+func numVal(s string, pos int) expr {
+	base := map[byte]int{'b': 2, 'd': 10, 'x': 16}[s[1]|0x20]
+	parse := func(digits string) rune {
+		n, _ := strconv.ParseInt(digits, base, 32)
+		return rune(n)
+	}
+
+	digits := s[2:]
+	if dash := strings.IndexByte(digits, '-'); dash >= 0 {
+		return expr{kind: rangeExpr, lo: parse(digits[:dash]), hi: parse(digits[dash+1:]), pos: pos}
+	}
+	parts := strings.Split(digits, ".")
+	if len(parts) == 1 {
+		r := parse(parts[0])
+		return expr{kind: rangeExpr, lo: r, hi: r, pos: pos}
+	}
+	var runes []rune
+	for _, part := range parts {
+		runes = append(runes, parse(part))
+	}
+	return expr{kind: stringExpr, text: string(runes), caseSensitive: true, pos: pos}
+}
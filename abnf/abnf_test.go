@@ -0,0 +1,132 @@
+package abnf
+
+import (
+	"testing"
+
+	"github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	g, err := Import(`
+		; from RFC 7230
+		HTTP-version = HTTP-name "/" DIGIT "." DIGIT
+		HTTP-name    = %x48.54.54.50 ; "HTTP", case-sensitive
+	`, nil)
+	require.NoError(t, err)
+
+	version, ok := g.Rule("http-version")
+	require.True(t, ok)
+
+	_, _, err = goparsify.Run(version, "HTTP/1.1")
+	require.NoError(t, err)
+
+	_, _, err = goparsify.Run(version, "http/1.1")
+	require.Error(t, err)
+
+	_, _, err = goparsify.Run(version, "HTTP / 1.1")
+	require.Error(t, err)
+
+	_, ok = g.Rule("missing")
+	require.False(t, ok)
+}
+
+func TestElements(t *testing.T) {
+	tests := []struct {
+		grammar string
+		input   string
+		ok      bool
+	}{
+		{`a = "x" "y"`, "xy", true},
+		{`a = "x" "y"`, "XY", true},
+		{`a = %s"x"`, "X", false},
+		{`a = %i"x"`, "X", true},
+		{`a = "x" / "y"`, "y", true},
+		{`a = "x" / "y"`, "z", false},
+		{`a = ["x"] "y"`, "y", true},
+		{`a = ("x" / "y") "z"`, "yz", true},
+		{`a = *"x" "y"`, "xxxy", true},
+		{`a = 1*"x"`, "", false},
+		{`a = 2*3"x"`, "x", false},
+		{`a = 2*3"x"`, "xxx", true},
+		{`a = 2*3"x"`, "xxxx", false},
+		{`a = 2"x"`, "xx", true},
+		{`a = *2"x"`, "xxx", false},
+		{`a = %x41-43`, "B", true},
+		{`a = %x41-43`, "D", false},
+		{`a = %d65`, "A", true},
+		{`a = %b1000001`, "A", true},
+		{`a = %x3B1`, "α", true},
+		{`a = 1*ALPHA`, "abcXYZ", true},
+		{`a = 2HEXDIG`, "fF", true},
+		{`a = *WSP CRLF`, " \t\r\n", true},
+		{"a = \"x\"\n  \"y\"", "xy", true},
+		{"a = \"x\"\na =/ \"y\"", "y", true},
+		{"a = b\nb = \"x\"", "x", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.grammar+" "+test.input, func(t *testing.T) {
+			g, err := Import(test.grammar, nil)
+			require.NoError(t, err)
+			a, _ := g.Rule("a")
+
+			_, _, err = goparsify.Run(a, test.input)
+			if test.ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	g, err := Import(`
+		greeting = "hello" SP name
+		name     = <a name, as people write it>
+	`, Overrides{"name": goparsify.Chars("a-zA-Z")})
+	require.NoError(t, err)
+
+	greeting, _ := g.Rule("greeting")
+	_, _, err = goparsify.Run(greeting, "hello World")
+	require.NoError(t, err)
+
+	g, err = Import(`a = 1*DIGIT`, Overrides{"DIGIT": goparsify.Exact("1")})
+	require.NoError(t, err)
+	a, _ := g.Rule("a")
+	_, _, err = goparsify.Run(a, "12")
+	require.Error(t, err)
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		grammar string
+		err     string
+	}{
+		{`a = <prose>`, "abnf: offset 4: rule a uses the prose value <prose>, give it an override"},
+		{`a = b`, "abnf: offset 4: undefined rule b"},
+		{"a = \"x\"\nA = \"y\"", "abnf: offset 8: rule A is defined more than once"},
+		{`a =/ "x"`, "abnf: offset 0: a =/ comes before a is defined"},
+		{`a = ("x"`, `abnf: offset 8: unexpected end of input, expected )`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.grammar, func(t *testing.T) {
+			_, err := Import(test.grammar, nil)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestRedefineCoreRule(t *testing.T) {
+	g, err := Import(`
+		a     = DIGIT
+		DIGIT = "0" / "1"
+	`, nil)
+	require.NoError(t, err)
+
+	a, _ := g.Rule("a")
+	_, _, err = goparsify.Run(a, "2")
+	require.Error(t, err)
+}
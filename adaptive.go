@@ -0,0 +1,299 @@
+package goparsify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// adaptiveReorderEvery is how many matches AdaptiveAny counts between reorderings.
+const adaptiveReorderEvery = 128
+
+// AdaptiveAny matches the first successful parser and returns its result, like Any, but only
+// tries the parsers that can start with the next byte of the input, going straight to the one
+// that matches in grammars where each alternative starts differently. Parsers that are all
+// Exact and can't match at the same time, eg keywords of which none is a prefix of another, are
+// also tried in the order of how often they have matched so far, so the one that matches most
+// of the time is tried first wherever it is listed.
+//
+// The alternatives are looked into the way ExportEBNF does the first time it runs. Parsers
+// written by hand can't be, so they are tried whatever the next byte is. When nothing matches
+// it goes through all of the parsers the way Any would, so it fails with the same error. Under
+// WithLongestMatch, WithBranchBudget, RunAllParses and RunTokens it is just Any.
+func AdaptiveAny(parsers ...Parserish) Parser {
+	if len(parsers) == 0 {
+		panic(fmt.Errorf("AdaptiveAny() needs at least one parser"))
+	}
+	return NewParser("AdaptiveAny()", newAdaptiveAny(parsers).parse)
+}
+
+type adaptiveAny struct {
+	parsers []Parser
+	rule    *grammarRule
+	// all is the Any of the parsers, to fall back on.
+	all Parser
+
+	once sync.Once
+	// table holds the indexes of the parsers that can start with each byte, in the order to try
+	// them. It is replaced as a whole when reordered, so parses can read it while that happens.
+	table atomic.Pointer[[256][]int]
+	// exclusive holds whether at most one of the parsers in the table of each byte can match at
+	// a time, so they can be tried in any order.
+	exclusive [256]bool
+	wins      []atomic.Int64
+	matches   atomic.Int64
+}
+
+func newAdaptiveAny(parsers []Parserish) *adaptiveAny {
+	parserfied := ParsifyAll(parsers...)
+	return &adaptiveAny{
+		parsers: parserfied,
+		rule:    &grammarRule{kind: "AdaptiveAny()"},
+		all:     Any(parsers...),
+		wins:    make([]atomic.Int64, len(parserfied)),
+	}
+}
+
+func (a *adaptiveAny) parse(ps *State, node *Result) {
+	if ps.analysis != nil {
+		ps.analysis.any(ps, a.rule, a.parsers)
+		return
+	}
+	if ps.cfg().longest || ps.cfg().ambiguity != nil || ps.tokens != nil || ps.cfg().branchBudget > 0 {
+		a.all(ps, node)
+		return
+	}
+	ps.WS(ps)
+	if ps.Pos >= len(ps.Input) || ps.cutSince(ps.Pos) {
+		a.all(ps, node)
+		return
+	}
+	startpos, before := ps.Pos, ps.Error
+	ps.Recover()
+
+	for _, i := range a.candidates(ps.Input[startpos]) {
+		a.parsers[i](ps, node)
+		if !ps.Errored() {
+			a.won(i)
+			return
+		}
+		if ps.cutSince(startpos) {
+			return
+		}
+		ps.Recover()
+	}
+
+	ps.Pos, ps.Error = startpos, before
+	a.all(ps, node)
+}
+
+// candidates returns the parsers to try when the input starts with b, working them out the
+// first time it's called.
+func (a *adaptiveAny) candidates(b byte) []int {
+	a.once.Do(a.build)
+	return a.table.Load()[b]
+}
+
+func (a *adaptiveAny) build() {
+	firsts := make([]firstSet, len(a.parsers))
+	w := &firstSetWalker{done: map[*grammarRule]firstSet{}}
+	ps := NewState("")
+	ps.analysis = w
+	for i, p := range a.parsers {
+		firsts[i] = w.first(ps, p)
+	}
+
+	var table [256][]int
+	for b := range table {
+		var literals []string
+		exclusive := true
+		for i, f := range firsts {
+			if !f.any && !f.nullable && !f.bytes[b] {
+				continue
+			}
+			table[b] = append(table[b], i)
+			if !f.literal {
+				exclusive = false
+			}
+			literals = append(literals, f.match)
+		}
+		a.exclusive[b] = exclusive && !anyPrefix(literals)
+	}
+	a.table.Store(&table)
+}
+
+// won counts a match of parser i, reordering the table every so often.
+func (a *adaptiveAny) won(i int) {
+	a.wins[i].Add(1)
+	if a.matches.Add(1)%adaptiveReorderEvery != 0 {
+		return
+	}
+	table := *a.table.Load()
+	for b := range table {
+		if !a.exclusive[b] || len(table[b]) < 2 {
+			continue
+		}
+		order := append([]int(nil), table[b]...)
+		sort.SliceStable(order, func(i, j int) bool { return a.wins[order[i]].Load() > a.wins[order[j]].Load() })
+		table[b] = order
+	}
+	a.table.Store(&table)
+}
+
+// anyPrefix returns whether one of the literals is a prefix of another, in which case both
+// can match at the same place.
+func anyPrefix(literals []string) bool {
+	for i, l := range literals {
+		for j, other := range literals {
+			if i != j && strings.HasPrefix(other, l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstSet is what firstSetWalker found out about the start of a parser.
+type firstSet struct {
+	// bytes holds the bytes a match can start with.
+	bytes [256]bool
+	// any is set when a match might start with anything, because the parser couldn't be
+	// looked into.
+	any bool
+	// nullable is set when the parser can match without consuming input.
+	nullable bool
+	// literal is set when the parser is just Exact(match).
+	literal bool
+	match   string
+}
+
+func (f *firstSet) add(other firstSet) {
+	for b, ok := range other.bytes {
+		f.bytes[b] = f.bytes[b] || ok
+	}
+	f.any = f.any || other.any
+}
+
+// firstSetWalker works out the bytes a parser can start with as the grammar is walked. Like
+// ebnfWriter each combinator pushes what it found onto out for its parent to pick up.
+type firstSetWalker struct {
+	out   []firstSet
+	stack []*grammarRule
+	done  map[*grammarRule]firstSet
+}
+
+// first runs p and returns the firstSet it pushed.
+func (w *firstSetWalker) first(ps *State, p Parser) firstSet {
+	n := len(w.out)
+	p(ps, &Result{})
+	ps.Recover()
+	if len(w.out) == n {
+		return firstSet{any: true}
+	}
+	f := w.out[n]
+	w.out = w.out[:n]
+	return f
+}
+
+// node pushes the firstSet of rule. Rules that refer back to themselves are taken to start
+// with anything, which is never wrong.
+func (w *firstSetWalker) node(rule *grammarRule, find func() firstSet) {
+	if f, ok := w.done[rule]; ok {
+		w.out = append(w.out, f)
+		return
+	}
+	for _, r := range w.stack {
+		if r == rule {
+			w.out = append(w.out, firstSet{any: true})
+			return
+		}
+	}
+	w.stack = append(w.stack, rule)
+	f := find()
+	w.stack = w.stack[:len(w.stack)-1]
+	w.done[rule] = f
+	w.out = append(w.out, f)
+}
+
+func (w *firstSetWalker) seq(ps *State, rule *grammarRule, parsers []Parser) {
+	w.node(rule, func() firstSet {
+		f := firstSet{nullable: true}
+		for _, p := range parsers {
+			c := w.first(ps, p)
+			f.add(c)
+			if !c.nullable {
+				f.nullable = false
+				break
+			}
+		}
+		return f
+	})
+}
+
+func (w *firstSetWalker) any(ps *State, rule *grammarRule, parsers []Parser) {
+	w.node(rule, func() firstSet {
+		var f firstSet
+		for _, p := range parsers {
+			c := w.first(ps, p)
+			f.add(c)
+			f.nullable = f.nullable || c.nullable
+		}
+		return f
+	})
+}
+
+func (w *firstSetWalker) many(ps *State, rule *grammarRule, min int, op, sep Parser) {
+	w.node(rule, func() firstSet {
+		var f firstSet
+		c := w.first(ps, op)
+		f.add(c)
+		// An item that can be empty lets the separator come first.
+		f.any = f.any || c.nullable && sep != nil
+		f.nullable = min == 0 || c.nullable
+		return f
+	})
+}
+
+func (w *firstSetWalker) signalSeq(ps *State, rule *grammarRule, noise Parser, signals []Parser) {
+	w.node(rule, func() firstSet { return firstSet{any: true} })
+}
+
+func (w *firstSetWalker) wrap(ps *State, rule *grammarRule, p Parser, nullable bool) {
+	w.node(rule, func() firstSet {
+		if rule.withoutChild {
+			return firstSet{any: true, nullable: true}
+		}
+		f := w.first(ps, p)
+		if nullable {
+			f.nullable, f.literal = true, false
+		}
+		return f
+	})
+}
+
+func (w *firstSetWalker) exact(match string) {
+	f := firstSet{nullable: match == "", literal: true, match: match}
+	if match != "" {
+		f.bytes[match[0]] = true
+	}
+	w.out = append(w.out, f)
+}
+
+func (w *firstSetWalker) chars(description string, first func(b byte) bool, nullable bool) {
+	f := firstSet{nullable: nullable}
+	for b := range f.bytes {
+		f.bytes[b] = first(byte(b))
+	}
+	w.out = append(w.out, f)
+}
+
+func (w *firstSetWalker) terminal(description string) {
+	if description == "" {
+		// Markers like Cut match nothing at all.
+		w.out = append(w.out, firstSet{nullable: true})
+		return
+	}
+	w.out = append(w.out, firstSet{any: true})
+}
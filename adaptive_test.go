@@ -0,0 +1,137 @@
+package goparsify
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveAny(t *testing.T) {
+	number := Chars("0-9")
+	call := Seq(Chars("a-z"), "(", ")")
+
+	t.Run("matches like Any", func(t *testing.T) {
+		for _, input := range []string{"for", "func", "123", "f()", "x()", "fo", "f(", ""} {
+			any := Any("for", "func", number, call)
+			adaptive := AdaptiveAny("for", "func", number, call)
+
+			want, wantPS := runParser(input, any)
+			got, gotPS := runParser(input, adaptive)
+			require.Equal(t, want, got, input)
+			require.Equal(t, wantPS.Pos, gotPS.Pos, input)
+			require.Equal(t, wantPS.Error, gotPS.Error, input)
+		}
+	})
+
+	t.Run("keeps the order of overlapping alternatives", func(t *testing.T) {
+		parser := AdaptiveAny(Seq("in", "x"), "in", Chars("a-z"))
+		for i := 0; i < 2*adaptiveReorderEvery; i++ {
+			node, ps := runParser("in", parser)
+			require.False(t, ps.Errored())
+			require.Equal(t, "in", node.Token)
+		}
+		node, _ := runParser("in x", parser)
+		require.Equal(t, "in x", node.Token)
+	})
+
+	t.Run("tries branches skipping whitespace of their own", func(t *testing.T) {
+		comments := func(ps *State) {
+			ASCIIWhitespace(ps)
+			for strings.HasPrefix(ps.Get(), "#") {
+				for ps.Pos < len(ps.Input) && ps.Input[ps.Pos] != '\n' {
+					ps.Pos++
+				}
+				ASCIIWhitespace(ps)
+			}
+		}
+		embedded := Embed(Seq("x", "y"), WithWhitespace(comments))
+		parser := AdaptiveAny(embedded, Chars("#a-z"))
+
+		node, ps := runParser("#c\nx y", parser)
+		require.False(t, ps.Errored())
+		assertSequence(t, node, "x", "y")
+	})
+
+	t.Run("tries wrappers that can match without their child whatever the next byte", func(t *testing.T) {
+		recovering := Recovering(Seq("let", Chars("a-z")), ";")
+		want, err := RunTree(Seq(Any(recovering, Chars("a-z")), ";"), "foo;")
+		require.NoError(t, err)
+		require.Len(t, RecoveredErrors(&want), 1)
+
+		parser := Seq(AdaptiveAny(recovering, Chars("a-z")), ";")
+		for i := 0; i < 2*adaptiveReorderEvery; i++ {
+			got, err := RunTree(parser, "foo;")
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		}
+	})
+
+	t.Run("respects cuts", func(t *testing.T) {
+		parser := AdaptiveAny(Seq("var", Cut(), "x"), "var y")
+		_, ps := runParser("var y", parser)
+		require.Equal(t, "offset 4: expected x", ps.Error.Error())
+	})
+
+	t.Run("works with recursion", func(t *testing.T) {
+		var value Parser
+		list := Seq("[", Some(&value, ","), "]")
+		value = AdaptiveAny(list, number)
+
+		node, ps := runParser("[1,[2,3]]", value)
+		require.False(t, ps.Errored())
+		require.Equal(t, "[1,[2,3]]", node.Token)
+	})
+}
+
+func TestAdaptiveAnyReorders(t *testing.T) {
+	a := newAdaptiveAny([]Parserish{"for", "func", Chars("0-9")})
+	require.Equal(t, []int{0, 1}, a.candidates('f'))
+	require.Equal(t, []int{2}, a.candidates('1'))
+	require.Empty(t, a.candidates('x'))
+
+	for i := 0; i < adaptiveReorderEvery; i++ {
+		node, ps := runParser("func", a.parse)
+		require.False(t, ps.Errored())
+		require.Equal(t, "func", node.Token)
+	}
+	require.Equal(t, []int{1, 0}, a.candidates('f'))
+
+	node, ps := runParser("for", a.parse)
+	require.False(t, ps.Errored())
+	require.Equal(t, "for", node.Token)
+
+	t.Run("not when both can match", func(t *testing.T) {
+		a := newAdaptiveAny([]Parserish{"in", "int", Chars("a-z")})
+		for i := 0; i < adaptiveReorderEvery; i++ {
+			runParser("int", a.parse)
+		}
+		require.Equal(t, []int{0, 1, 2}, a.candidates('i'))
+	})
+}
+
+// TestAdaptiveAnyConcurrent reorders the alternatives while other goroutines parse. Run it
+// with -race to check that they can.
+func TestAdaptiveAnyConcurrent(t *testing.T) {
+	parser := AdaptiveAny("for", "func", Chars("0-9"))
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < adaptiveReorderEvery; i++ {
+				if _, _, err := Run(parser, "func"); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
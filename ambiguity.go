@@ -0,0 +1,104 @@
+package goparsify
+
+import "bytes"
+
+// RunAllParses is like Run but returns every way parser can match the whole input, rather than
+// just the first, so ambiguities in a grammar can be found and inspected.
+//
+// Every Any explores all of its branches instead of committing to the first that matches, so
+// parses Run would miss by not backtracking into an Any are found too. Maybe, Many and Some stay
+// greedy, so only ambiguities between Any branches are found. Parses with the same tree are
+// only returned once, ordered by the branches taken. The number of parses to look for is set
+// with WithMaxParses.
+//
+// Exploring every branch takes time exponential in the number of Any along a parse, so it is
+// meant for tests and tools rather than production parsing. The grammar is run once for each
+// combination of branches, up to the number set with WithMaxExplorations, and on input from
+// users WithBranchBudget keeps each branch from scanning far. The options apply to each run as
+// they do to Run. The error is the one Run returns when there are no parses at all.
+func RunAllParses(parser Parserish, input string, opts ...Option) ([]Result, error) {
+	p := Parsify(parser)
+	cfg := newRunConfig(opts)
+	maxParses := cfg.maxParses
+	if maxParses == 0 {
+		maxParses = defaultMaxParses
+	}
+	maxExplorations := cfg.maxExplorations
+	if maxExplorations == 0 {
+		maxExplorations = defaultMaxExplorations
+	}
+	text, offsets, err := prepareInput(input, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	seen := map[string]bool{}
+	pending := [][]int{nil}
+	for runs := 0; len(pending) > 0 && len(results) < maxParses && runs < maxExplorations; runs++ {
+		prefix := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		choices := &choiceExplorer{prefix: prefix}
+		cfg.ambiguity = choices
+		result, _, err := parseInput(p, text, cfg, input, offsets)
+		if err == nil {
+			offsets.mapResult(result)
+			var key bytes.Buffer
+			result.Dump(&key)
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				results = append(results, *result)
+			}
+		}
+
+		// Every Any after the prefix took its first branch, queue up the others so that the
+		// earliest branches are explored first.
+		for i, branches := range choices.branches {
+			for j := branches - 1; j > 0; j-- {
+				next := make([]int, len(prefix)+i+1)
+				copy(next, prefix)
+				next[len(next)-1] = j
+				pending = append(pending, next)
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		_, _, err := Run(p, input, opts...)
+		return nil, err
+	}
+	return results, nil
+}
+
+const (
+	defaultMaxParses       = 100
+	defaultMaxExplorations = 10000
+)
+
+// choiceExplorer makes every Any take a single branch during one of the runs of RunAllParses.
+type choiceExplorer struct {
+	// prefix holds the branches taken by the first Any run, then the second and so on.
+	prefix []int
+	// branches holds the number of branches of each Any run after the prefix, which all took
+	// their first branch.
+	branches []int
+	next     int
+}
+
+// choose returns the branch of parsers the next Any should take, and the index in it of the
+// last of parsers, which it is past unless it is the one taken.
+func (e *choiceExplorer) choose(parsers []Parser) ([]Parser, int) {
+	i := e.next
+	e.next++
+	branch := 0
+	if i < len(e.prefix) {
+		branch = e.prefix[i]
+	} else {
+		e.branches = append(e.branches, len(parsers))
+	}
+	if len(parsers) == 0 {
+		return nil, -1
+	}
+	return parsers[branch : branch+1], len(parsers) - 1 - branch
+}
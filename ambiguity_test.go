@@ -0,0 +1,87 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAllParses(t *testing.T) {
+	t.Run("finds every split", func(t *testing.T) {
+		words := Some(Any("a", "b", "ab"))
+
+		parses, err := RunAllParses(words, "ab")
+		require.NoError(t, err)
+		require.Len(t, parses, 2)
+		require.Len(t, parses[0].Child, 2)
+		require.Equal(t, "a", parses[0].Child[0].Token)
+		require.Equal(t, "b", parses[0].Child[1].Token)
+		require.Len(t, parses[1].Child, 1)
+		require.Equal(t, "ab", parses[1].Child[0].Token)
+	})
+
+	t.Run("backtracks into Any", func(t *testing.T) {
+		p := Seq(Any("a", "ab"), "c")
+
+		_, _, err := Run(p, "abc")
+		require.Error(t, err)
+
+		parses, err := RunAllParses(p, "abc")
+		require.NoError(t, err)
+		require.Len(t, parses, 1)
+		require.Equal(t, "ab", parses[0].Child[0].Token)
+	})
+
+	t.Run("drops duplicate trees", func(t *testing.T) {
+		parses, err := RunAllParses(Seq(Maybe(Any("x", "y")), "z"), "z")
+		require.NoError(t, err)
+		require.Len(t, parses, 1)
+	})
+
+	t.Run("stops at the maximum", func(t *testing.T) {
+		parses, err := RunAllParses(Some(Any("a", "aa")), "aaaa", WithMaxParses(3))
+		require.NoError(t, err)
+		require.Len(t, parses, 3)
+
+		parses, err = RunAllParses(Some(Any("a", "aa")), "aaaa")
+		require.NoError(t, err)
+		require.Len(t, parses, 5)
+	})
+
+	t.Run("stops exploring at the maximum", func(t *testing.T) {
+		words := Seq(Some(Any("a", "aa")), "b")
+		_, err := RunAllParses(words, "aaaaaaaaaaaaaaaaaaaa", WithMaxExplorations(50))
+		require.EqualError(t, err, "offset 20: unexpected end of input, expected b")
+
+		parses, err := RunAllParses(Some(Any("a", "aa")), "aaaa", WithMaxExplorations(1))
+		require.NoError(t, err)
+		require.Len(t, parses, 1)
+	})
+
+	t.Run("bounds each branch by the budget", func(t *testing.T) {
+		p := Seq(Any(Seq(Chars("a"), "!"), Chars("a")), Maybe("!"))
+		parses, err := RunAllParses(p, "aaaaaaaaaa!")
+		require.NoError(t, err)
+		require.Len(t, parses, 2)
+
+		parses, err = RunAllParses(p, "aaaaaaaaaa!", WithBranchBudget(4))
+		require.NoError(t, err)
+		require.Len(t, parses, 1)
+		require.Equal(t, "aaaaaaaaaa", parses[0].Child[0].Token)
+	})
+
+	t.Run("applies the options like Run", func(t *testing.T) {
+		_, err := RunAllParses(Any("a", "b"), "aaaa", WithMaxInputSize(2))
+		require.EqualError(t, err, "offset 2: expected input of at most 2 bytes")
+
+		parses, err := RunAllParses(Seq("a", Any("b", "b")), "\r\na\r\nb", WithNormalizedNewlines())
+		require.NoError(t, err)
+		require.Len(t, parses, 1)
+		require.Equal(t, 5, parses[0].Child[1].Start)
+	})
+
+	t.Run("returns the error from Run", func(t *testing.T) {
+		_, err := RunAllParses(Seq("a", "b"), "ac")
+		require.EqualError(t, err, "offset 1: expected b")
+	})
+}
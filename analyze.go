@@ -0,0 +1,308 @@
+package goparsify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FindingKind is the kind of problem reported by Analyze.
+type FindingKind int
+
+const (
+	// LeftRecursion is a rule that can reach itself without consuming any input. It never
+	// terminates when run.
+	LeftRecursion FindingKind = iota
+	// EmptyLoop is a Many or Some whose parser (and separator, if any) can match without
+	// consuming input. It loops forever once that happens.
+	EmptyLoop
+	// UnreachableBranch is an Any branch that can never be chosen because an earlier branch
+	// always matches first.
+	UnreachableBranch
+)
+
+func (k FindingKind) String() string {
+	switch k {
+	case LeftRecursion:
+		return "left recursion"
+	case EmptyLoop:
+		return "empty loop"
+	case UnreachableBranch:
+		return "unreachable branch"
+	}
+	return fmt.Sprintf("FindingKind(%d)", int(k))
+}
+
+// Finding is a problem in a grammar reported by Analyze.
+type Finding struct {
+	Kind FindingKind
+	// Rule is the name given with Named to the innermost rule containing the problem,
+	// or the combinator it is made of if it has no name.
+	Rule    string
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s in %s: %s", f.Kind, f.Rule, f.Message)
+}
+
+// Analyze walks the grammar of p without parsing anything and reports rules that will hang
+// or can never match. Only the combinators in this package are understood, parsers written
+// by hand are treated as terminals and are assumed to consume input unless they can match
+// the empty string.
+func Analyze(p Parserish) []Finding {
+	a := &analyzer{
+		done:     map[*grammarRule]bool{},
+		reported: map[string]bool{},
+	}
+	ps := NewState("")
+	ps.analysis = a
+	a.child(ps, Parsify(p), true)
+	return a.findings
+}
+
+// grammarWalker is told how combinators are arranged while walking a grammar, see
+// State.analysis. Terminals are still run, on an empty input, after reporting themselves.
+type grammarWalker interface {
+	seq(ps *State, rule *grammarRule, parsers []Parser)
+	any(ps *State, rule *grammarRule, parsers []Parser)
+	many(ps *State, rule *grammarRule, min int, op, sep Parser)
+	signalSeq(ps *State, rule *grammarRule, noise Parser, signals []Parser)
+	wrap(ps *State, rule *grammarRule, p Parser, nullable bool)
+	exact(match string)
+	// chars reports a terminal that matches a run of runes, like Chars, along with the bytes
+	// it can start with and whether it can match none.
+	chars(description string, first func(b byte) bool, nullable bool)
+	// terminal reports any other terminal, described the way an EBNF special sequence would.
+	terminal(description string)
+}
+
+// grammarRule identifies a combinator while analyzing. Each combinator allocates one when it
+// is created and hands it to the analyzer instead of parsing while State.analysis is set.
+type grammarRule struct {
+	kind string
+	name string
+	// leftRecursive is set by LeftRecursive, which makes left recursion through it safe.
+	leftRecursive bool
+	// longest is set by Longest, whose branches are all tried whatever their order.
+	longest bool
+	// withoutChild is set by wrappers that can succeed without their child matching where they
+	// start, eg Recovering, Find and Embed, so they can start with anything.
+	withoutChild bool
+	// named is set by Named, for Rules and WriteCoverage.
+	named *namedRule
+}
+
+type analyzerFrame struct {
+	rule *grammarRule
+	// left is set when the rule starts at the same position as its parent, ie every parser
+	// before it in its parent can match empty.
+	left bool
+}
+
+// analyzer walks a grammar by calling parsers on an empty input. Combinators report to it how
+// their children are arranged, and everything else reports whether it can match empty by
+// succeeding or failing as usual.
+type analyzer struct {
+	findings []Finding
+	// done holds whether each fully explored rule can match empty.
+	done     map[*grammarRule]bool
+	reported map[string]bool
+	stack    []analyzerFrame
+	// left is whether the next rule visited is entered at the start of its parent.
+	left bool
+
+	// visits counts the parsers reported to the analyzer. Along with literalAt it tells
+	// whether an Any branch was a bare Exact.
+	visits    int
+	literal   string
+	literalAt int
+}
+
+// child visits p in the position described by left and returns whether it can match empty.
+func (a *analyzer) child(ps *State, p Parser, left bool) bool {
+	a.left = left
+	p(ps, &Result{})
+	if ps.Errored() {
+		ps.Recover()
+		return false
+	}
+	return true
+}
+
+// visit explores rule, unless it has been already, and reports whether it can match empty
+// back to the parent the way a parser would, by failing if it can't.
+func (a *analyzer) visit(ps *State, rule *grammarRule, explore func() bool) {
+	left := a.left
+	a.visits++
+	nullable, done := a.done[rule]
+	if !done {
+		if i := a.onStack(rule); i >= 0 {
+			if left && a.leftFrom(i+1) && !a.handlesLeftRecursion(i) {
+				a.report(LeftRecursion, a.ruleName(i), "reaches itself without consuming input through "+a.path(i))
+			}
+			// Rules still being explored are assumed to consume input, which stops the walk.
+			nullable = false
+		} else {
+			a.stack = append(a.stack, analyzerFrame{rule: rule, left: left})
+			nullable = explore()
+			a.stack = a.stack[:len(a.stack)-1]
+			a.done[rule] = nullable
+		}
+	}
+	if !nullable {
+		ps.ErrorHere(rule.kind)
+	}
+}
+
+func (a *analyzer) seq(ps *State, rule *grammarRule, parsers []Parser) {
+	a.visit(ps, rule, func() bool {
+		nullable := true
+		for _, p := range parsers {
+			if !a.child(ps, p, nullable) {
+				nullable = false
+			}
+		}
+		return nullable
+	})
+}
+
+func (a *analyzer) any(ps *State, rule *grammarRule, parsers []Parser) {
+	a.visit(ps, rule, func() bool {
+		var literals []string
+		nullable := false
+		for i, p := range parsers {
+			before := a.visits
+			branchNullable := a.child(ps, p, true)
+			if rule.longest {
+				nullable = nullable || branchNullable
+				continue
+			}
+			if nullable {
+				a.report(UnreachableBranch, a.ruleName(len(a.stack)-1), fmt.Sprintf("branch %d comes after a branch that always matches", i+1))
+				continue
+			}
+			if a.visits == before+1 && a.literalAt == before {
+				for _, earlier := range literals {
+					if strings.HasPrefix(a.literal, earlier) {
+						a.report(UnreachableBranch, a.ruleName(len(a.stack)-1), fmt.Sprintf("branch %d %q is shadowed by the earlier branch %q", i+1, a.literal, earlier))
+						break
+					}
+				}
+				literals = append(literals, a.literal)
+			}
+			nullable = nullable || branchNullable
+		}
+		return nullable
+	})
+}
+
+func (a *analyzer) many(ps *State, rule *grammarRule, min int, op, sep Parser) {
+	a.visit(ps, rule, func() bool {
+		opNullable := a.child(ps, op, true)
+		sepNullable := true
+		if sep != nil {
+			sepNullable = a.child(ps, sep, opNullable)
+		}
+		if opNullable && sepNullable {
+			a.report(EmptyLoop, a.ruleName(len(a.stack)-1), rule.kind+" repeats a parser that can match without consuming input")
+		}
+		return min == 0 || opNullable
+	})
+}
+
+func (a *analyzer) signalSeq(ps *State, rule *grammarRule, noise Parser, signals []Parser) {
+	a.visit(ps, rule, func() bool {
+		if a.child(ps, noise, false) {
+			a.report(EmptyLoop, a.ruleName(len(a.stack)-1), "SignalSeq() skips noise that can match without consuming input")
+		}
+		nullable := true
+		for _, p := range signals {
+			if !a.child(ps, p, nullable) {
+				nullable = false
+			}
+		}
+		return nullable
+	})
+}
+
+// wrap visits a combinator with a single child at the same position, like Maybe or Named.
+func (a *analyzer) wrap(ps *State, rule *grammarRule, p Parser, nullable bool) {
+	a.visit(ps, rule, func() bool {
+		return a.child(ps, p, true) || nullable
+	})
+}
+
+// exact records that a literal was visited, see analyzer.any.
+func (a *analyzer) exact(match string) {
+	a.literal = match
+	a.literalAt = a.visits
+	a.visits++
+}
+
+func (a *analyzer) chars(description string, first func(b byte) bool, nullable bool) {}
+
+func (a *analyzer) terminal(description string) {}
+
+func (a *analyzer) onStack(rule *grammarRule) int {
+	for i := len(a.stack) - 1; i >= 0; i-- {
+		if a.stack[i].rule == rule {
+			return i
+		}
+	}
+	return -1
+}
+
+// leftFrom returns whether every frame from i up was entered at the start of its parent.
+func (a *analyzer) leftFrom(i int) bool {
+	for _, frame := range a.stack[i:] {
+		if !frame.left {
+			return false
+		}
+	}
+	return true
+}
+
+// handlesLeftRecursion returns whether any frame from i up is a LeftRecursive parser.
+func (a *analyzer) handlesLeftRecursion(i int) bool {
+	for _, frame := range a.stack[i:] {
+		if frame.rule.leftRecursive {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleName returns the name of the innermost named rule at or below frame i.
+func (a *analyzer) ruleName(i int) string {
+	for j := i; j >= 0; j-- {
+		if a.stack[j].rule.name != "" {
+			return a.stack[j].rule.name
+		}
+	}
+	if i < 0 {
+		return ""
+	}
+	return a.stack[i].rule.kind
+}
+
+func (a *analyzer) path(i int) string {
+	var names []string
+	for _, frame := range a.stack[i:] {
+		if frame.rule.name != "" {
+			names = append(names, frame.rule.name)
+		} else {
+			names = append(names, frame.rule.kind)
+		}
+	}
+	names = append(names, names[0])
+	return strings.Join(names, " -> ")
+}
+
+func (a *analyzer) report(kind FindingKind, rule, message string) {
+	key := fmt.Sprintf("%d %s %s", kind, rule, message)
+	if a.reported[key] {
+		return
+	}
+	a.reported[key] = true
+	a.findings = append(a.findings, Finding{Kind: kind, Rule: rule, Message: message})
+}
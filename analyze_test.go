@@ -0,0 +1,80 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Run("clean grammars have no findings", func(t *testing.T) {
+		var value Parser
+		array := Named("array", Seq("[", Cut(), Many(&value, ","), "]"))
+		value = Named("value", Any(NumberLit(), StringLit(`"`), array))
+
+		require.Empty(t, Analyze(value))
+	})
+
+	t.Run("direct left recursion", func(t *testing.T) {
+		var expr Parser
+		expr = Named("expr", Any(Seq(&expr, "+", NumberLit()), NumberLit()))
+
+		findings := Analyze(expr)
+		require.Len(t, findings, 1)
+		require.Equal(t, LeftRecursion, findings[0].Kind)
+		require.Equal(t, "expr", findings[0].Rule)
+		require.Equal(t, "reaches itself without consuming input through expr -> Any() -> Seq() -> expr", findings[0].Message)
+	})
+
+	t.Run("indirect left recursion through empty parsers", func(t *testing.T) {
+		var a, b Parser
+		a = Named("a", Seq(Maybe("-"), &b, "x"))
+		b = Named("b", Any(Seq(&a, "y"), "z"))
+
+		findings := Analyze(a)
+		require.Len(t, findings, 1)
+		require.Equal(t, LeftRecursion, findings[0].Kind)
+		require.Equal(t, "a", findings[0].Rule)
+	})
+
+	t.Run("recursion after consuming input is fine", func(t *testing.T) {
+		var group Parser
+		group = Seq("(", Maybe(&group), ")")
+
+		require.Empty(t, Analyze(group))
+	})
+
+	t.Run("empty loops", func(t *testing.T) {
+		findings := Analyze(Named("list", Many(Maybe("a"))))
+		require.Equal(t, []Finding{{
+			Kind:    EmptyLoop,
+			Rule:    "list",
+			Message: "Many() repeats a parser that can match without consuming input",
+		}}, findings)
+
+		require.Empty(t, Analyze(Many(Maybe("a"), ",")))
+		require.Len(t, Analyze(Some(Maybe("a"), Maybe(","))), 1)
+	})
+
+	t.Run("unreachable branches", func(t *testing.T) {
+		findings := Analyze(Named("op", Any("<", "<=", Maybe("!"), ">")))
+		require.Equal(t, []Finding{
+			{Kind: UnreachableBranch, Rule: "op", Message: `branch 2 "<=" is shadowed by the earlier branch "<"`},
+			{Kind: UnreachableBranch, Rule: "op", Message: "branch 4 comes after a branch that always matches"},
+		}, findings)
+
+		require.Empty(t, Analyze(Any("<=", "<", Seq("<", ">"))))
+	})
+
+	t.Run("map callbacks are not run", func(t *testing.T) {
+		p := Seq("a", "b").Map(func(n *Result) {
+			n.Result = n.Child[1].Token
+		})
+		require.Empty(t, Analyze(p))
+	})
+}
+
+func TestFinding_String(t *testing.T) {
+	f := Finding{Kind: EmptyLoop, Rule: "list", Message: "oops"}
+	require.Equal(t, "empty loop in list: oops", f.String())
+}
@@ -0,0 +1,128 @@
+package goparsify
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Attempt is a single run of a parser, as recorded by WithAttempts.
+type Attempt struct {
+	// Parser is what the parser matches, as passed to NewParser.
+	Parser string `json:"parser"`
+	// Var is the name of the variable the parser was assigned to, if it could be found.
+	Var string `json:"var,omitempty"`
+	// Start and End are the offsets into the input before and after the parser ran.
+	Start int  `json:"start"`
+	End   int  `json:"end"`
+	OK    bool `json:"ok"`
+}
+
+// ReadAttempts reads attempts written by WithAttempts, eg to pass them to RenderAttempts.
+func ReadAttempts(r io.Reader) ([]Attempt, error) {
+	var attempts []Attempt
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var a Attempt
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, scanner.Err()
+}
+
+// heatLevels are the characters RenderAttempts uses for increasing numbers of attempts.
+const heatLevels = " .:-=+*#%@"
+
+// RenderAttempts draws a heatmap of where parsers were tried over the input they ran against.
+// Each line of input is followed by a line shading how many attempts started at each byte,
+// and the positions with the most failed attempts are listed at the end. Those are where
+// the parser backtracks the most and are good places to consider a Cut.
+func RenderAttempts(w io.Writer, input string, attempts []Attempt) error {
+	starts := make([]int, len(input)+1)
+	failures := make([]int, len(input)+1)
+	most := 0
+	for _, a := range attempts {
+		if a.Start < 0 || a.Start > len(input) {
+			continue
+		}
+		starts[a.Start]++
+		if !a.OK {
+			failures[a.Start]++
+		}
+		if starts[a.Start] > most {
+			most = starts[a.Start]
+		}
+	}
+
+	const width = 64
+	bw := bufio.NewWriter(w)
+	for lineStart := 0; lineStart < len(input) || lineStart == 0; lineStart += width {
+		lineEnd := lineStart + width
+		if lineEnd > len(input) {
+			lineEnd = len(input)
+		}
+		text := []byte(input[lineStart:lineEnd])
+		heat := make([]byte, len(text))
+		for i := range text {
+			if text[i] < ' ' || text[i] == 0x7f {
+				text[i] = ' '
+			}
+			heat[i] = heatLevels[heatLevel(starts[lineStart+i], most)]
+		}
+		fmt.Fprintf(bw, "%6d | %s\n", lineStart, text)
+		fmt.Fprintln(bw, strings.TrimRight("       | "+string(heat), " "))
+		if lineEnd == len(input) {
+			break
+		}
+	}
+	if starts[len(input)] > 0 {
+		fmt.Fprintf(bw, "%6d | %d attempts at the end of the input\n", len(input), starts[len(input)])
+	}
+
+	positions := make([]int, 0, len(failures))
+	for pos, n := range failures {
+		if n > 0 {
+			positions = append(positions, pos)
+		}
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return failures[positions[i]] > failures[positions[j]]
+	})
+	if len(positions) > 5 {
+		positions = positions[:5]
+	}
+	if len(positions) > 0 {
+		fmt.Fprintf(bw, "\nmost failed attempts:\n")
+	}
+	for _, pos := range positions {
+		fmt.Fprintf(bw, "%6d | %d of %d failed %s\n", pos, failures[pos], starts[pos], truncatedQuote(input[pos:], 20))
+	}
+	return bw.Flush()
+}
+
+// heatLevel scales n attempts out of the most at any position to an index into heatLevels.
+func heatLevel(n, most int) int {
+	return (n*(len(heatLevels)-1) + most - 1) / most
+}
+
+// attemptRecorder writes each attempt as a line of JSON.
+type attemptRecorder struct {
+	enc *json.Encoder
+}
+
+func newAttemptRecorder(w io.Writer) *attemptRecorder {
+	return &attemptRecorder{enc: json.NewEncoder(w)}
+}
+
+func (r *attemptRecorder) record(a Attempt) {
+	// Errors writing the log shouldn't fail the parse.
+	_ = r.enc.Encode(a)
+}
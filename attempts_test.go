@@ -0,0 +1,58 @@
+package goparsify
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAttempts(t *testing.T) {
+	attempts, err := ReadAttempts(strings.NewReader(`{"parser":"hello","start":0,"end":5,"ok":true}
+
+{"parser":"Seq()","var":"greeting","start":0,"end":0,"ok":false}
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Attempt{
+		{Parser: "hello", Start: 0, End: 5, OK: true},
+		{Parser: "Seq()", Var: "greeting", Start: 0, End: 0, OK: false},
+	}, attempts)
+
+	_, err = ReadAttempts(strings.NewReader("nope\n"))
+	require.Error(t, err)
+}
+
+func TestRenderAttempts(t *testing.T) {
+	attempts := []Attempt{
+		{Parser: "a", Start: 0, End: 1, OK: true},
+		{Parser: "b", Start: 1, End: 1},
+		{Parser: "c", Start: 1, End: 1},
+		{Parser: "d", Start: 1, End: 2, OK: true},
+		{Parser: "e", Start: 3, End: 3},
+		{Parser: "EOF", Start: 5, End: 5, OK: true},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, RenderAttempts(buf, "ab\ncd", attempts))
+	require.Equal(t, ""+
+		"     0 | ab cd\n"+
+		"       | -@ -\n"+
+		"     5 | 1 attempts at the end of the input\n"+
+		"\n"+
+		"most failed attempts:\n"+
+		"     1 | 2 of 3 failed \"b\\ncd\"\n"+
+		"     3 | 1 of 1 failed \"cd\"\n", buf.String())
+
+	t.Run("long input wraps", func(t *testing.T) {
+		buf.Reset()
+		input := strings.Repeat("x", 70)
+		require.NoError(t, RenderAttempts(buf, input, []Attempt{{Start: 65, OK: true}, {Start: 70, OK: true}}))
+		require.Equal(t, ""+
+			"     0 | "+strings.Repeat("x", 64)+"\n"+
+			"       |\n"+
+			"    64 | xxxxxx\n"+
+			"       |  @\n"+
+			"    70 | 1 attempts at the end of the input\n", buf.String())
+	})
+}
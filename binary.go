@@ -0,0 +1,168 @@
+package goparsify
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// HexBytes matches bytes written as pairs of hex digits in either case, like deadBEEF, and
+// binds them into .Result as a []byte. A separator can be given for the bytes to be written
+// apart, eg " " for hex dumps or ":" for fingerprints, in which case a separator after the
+// last byte isn't part of the match. Without one an odd number of digits doesn't match, as it
+// can't be told which ones pair up.
+func HexBytes(separator ...string) Parser {
+	sep := ""
+	if len(separator) > 0 {
+		sep = separator[0]
+	}
+	description := "hex bytes"
+	if sep != "" {
+		description = fmt.Sprintf("hex bytes separated by %q", sep)
+	}
+
+	return NewParser("hex bytes", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
+		ps.WS(ps)
+		input := ps.Get()
+		var bytes []byte
+		n := 0
+		for {
+			start := n
+			if len(bytes) > 0 && sep != "" {
+				if !strings.HasPrefix(input[n:], sep) {
+					break
+				}
+				start += len(sep)
+			}
+			if start+2 > len(input) {
+				break
+			}
+			b, ok := unhex(input[start : start+2])
+			if !ok {
+				break
+			}
+			bytes = append(bytes, byte(b))
+			n = start + 2
+		}
+		if len(bytes) == 0 || sep == "" && n < len(input) && isHexDigit(input[n]) {
+			ps.ErrorHere(description)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, n) {
+			return
+		}
+		node.Token = input[:n]
+		node.Result = bytes
+		node.Start, node.End = ps.Pos, ps.Pos+n
+		ps.Advance(n)
+	})
+}
+
+// Base64 matches text in the standard base64 encoding of RFC 4648, with or without its =
+// padding, and binds the bytes it decodes to into .Result as a []byte.
+func Base64() Parser {
+	return NewParser("base64", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("base64")
+		}
+		ps.WS(ps)
+		input := ps.Get()
+		n := 0
+		for n < len(input) && isBase64(input[n]) {
+			n++
+		}
+		data := n
+		for n < len(input) && n-data < 2 && input[n] == '=' {
+			n++
+		}
+		if data == 0 {
+			ps.ErrorHere("base64")
+			return
+		}
+
+		encoding := base64.StdEncoding
+		if n == data {
+			encoding = base64.RawStdEncoding
+		}
+		decoded, err := encoding.DecodeString(input[:n])
+		if err != nil {
+			ps.ErrorHere("base64")
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, n) {
+			return
+		}
+		node.Token = input[:n]
+		node.Result = decoded
+		node.Start, node.End = ps.Pos, ps.Pos+n
+		ps.Advance(n)
+	})
+}
+
+func isBase64(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '+' || c == '/'
+}
+
+// FixedBytes matches exactly the next n bytes of the input, whatever they are, into .Token.
+// Like FixedWidth it doesn't skip whitespace first, as in fixed width formats it is part of the
+// data.
+func FixedBytes(n int) Parser {
+	description := fmt.Sprintf("%d bytes", n)
+
+	return NewParser(description, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
+		if ps.Pos+n > len(ps.Input) {
+			ps.ErrorHere(description)
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+n]
+		node.Start, node.End = ps.Pos, ps.Pos+n
+		ps.Advance(n)
+	})
+}
+
+// FixedWidth matches p against a field of exactly the next n bytes of the input, for fixed
+// width formats like mainframe records or the columns of a report:
+//
+//	record := Seq(FixedWidth(8, Chars("A-Z")), FixedWidth(6, NumberLit()))
+//
+// p sees the field as if the input ended with it, and has to match all of it but the whitespace
+// padding it. The field starts right where FixedWidth does, without skipping whitespace first.
+func FixedWidth(n int, p Parserish) Parser {
+	parser := Parsify(p)
+	rule := &grammarRule{kind: "FixedWidth()"}
+	description := fmt.Sprintf("field of %d bytes", n)
+
+	return NewParser(description, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parser, false)
+			return
+		}
+		start, end := ps.Pos, ps.Pos+n
+		if end > len(ps.Input) {
+			ps.ErrorHere(description)
+			return
+		}
+
+		input := ps.Input
+		ps.Input = input[:end]
+		parser(ps, node)
+		if !ps.Errored() {
+			ps.WS(ps)
+			if ps.Pos != end {
+				ps.ErrorHere("end of " + description)
+			}
+		}
+		ps.Input = input
+		if ps.Errored() {
+			ps.Pos = start
+			return
+		}
+		node.Start, node.End = start, end
+	})
+}
@@ -0,0 +1,92 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexBytes(t *testing.T) {
+	t.Run("matches pairs of digits", func(t *testing.T) {
+		result, p := runParser("deadBEEF rest", HexBytes())
+		require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, result.Result)
+		require.Equal(t, "deadBEEF", result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("matches separated bytes", func(t *testing.T) {
+		result, p := runParser("de:ad:be:", HexBytes(":"))
+		require.Equal(t, []byte{0xde, 0xad, 0xbe}, result.Result)
+		require.Equal(t, ":", p.Get())
+
+		result, p = runParser("00 01 02  |...|", HexBytes(" "))
+		require.Equal(t, []byte{0, 1, 2}, result.Result)
+		require.Equal(t, "  |...|", p.Get())
+	})
+
+	for _, input := range []string{"", "abc", "g0", "a"} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, p := runParser(input, HexBytes())
+			require.Equal(t, "offset 0: expected hex bytes", p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+}
+
+func TestBase64(t *testing.T) {
+	for input, want := range map[string]string{
+		"aGVsbG8=": "hello",
+		"aGVsbG8":  "hello",
+		"aGk=":     "hi",
+		"aGk":      "hi",
+		"aGVsbA==": "hell",
+		"aGVs":     "hel",
+	} {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input+" rest", Base64())
+			require.Equal(t, []byte(want), result.Result)
+			require.Equal(t, input, result.Token)
+			require.Equal(t, " rest", p.Get())
+		})
+	}
+
+	for _, input := range []string{"", "a", "aGk==", "aGVsbG8=="} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, p := runParser(input, Base64())
+			require.Equal(t, "offset 0: expected base64", p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+}
+
+func TestFixedWidth(t *testing.T) {
+	record := Seq(FixedWidth(6, Chars("A-Z")), FixedWidth(4, NumberLit()), FixedBytes(3))
+
+	t.Run("matches fields", func(t *testing.T) {
+		result, p := runParser("ABC     42  x", record)
+		require.False(t, p.Errored())
+		require.Equal(t, "ABC", result.Child[0].Token)
+		require.Equal(t, 0, result.Child[0].Start)
+		require.Equal(t, 6, result.Child[0].End)
+		require.Equal(t, int64(42), result.Child[1].Result)
+		require.Equal(t, "  x", result.Child[2].Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("doesn't let a field run over", func(t *testing.T) {
+		_, p := runParser("ABC DE  42  x", record)
+		require.Equal(t, "offset 4: expected end of field of 6 bytes", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		_, p = runParser("ABC   1 2  x", record)
+		require.Equal(t, "offset 8: expected end of field of 4 bytes", p.Error.Error())
+	})
+
+	t.Run("needs the whole field", func(t *testing.T) {
+		_, p := runParser("ABC   42", record)
+		require.Equal(t, "offset 6: expected field of 4 bytes", p.Error.Error())
+
+		_, p = runParser("ABC     42 ", record)
+		require.Equal(t, "offset 10: expected 3 bytes", p.Error.Error())
+	})
+}
@@ -0,0 +1,33 @@
+package goparsify
+
+// WordBoundary matches the empty string between a word character and something else, like \b
+// in a regex, eg to keep Seq("in", WordBoundary()) from matching the start of "int". Word
+// characters are the ASCII letters, digits and underscore, as in regexes. It doesn't skip
+// whitespace first, as the boundary is with what came before.
+func WordBoundary() Parser {
+	return boundary("word boundary", true)
+}
+
+// NotWordBoundary matches the empty string anywhere WordBoundary doesn't, like \B in a regex.
+func NotWordBoundary() Parser {
+	return boundary("no word boundary", false)
+}
+
+func boundary(expected string, want bool) Parser {
+	return NewParser(expected, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(expected)
+		}
+		before := ps.Pos > 0 && ps.Pos <= len(ps.Input) && isWordByte(ps.Input[ps.Pos-1])
+		after := ps.Pos < len(ps.Input) && isWordByte(ps.Input[ps.Pos])
+		if (before != after) != want {
+			ps.ErrorHere(expected)
+			return
+		}
+		node.Start, node.End = ps.offset(ps.Pos), ps.offset(ps.Pos)
+	})
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
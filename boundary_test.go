@@ -0,0 +1,34 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordBoundary(t *testing.T) {
+	keyword := Seq("in", WordBoundary())
+
+	for _, input := range []string{"in", "in x", "in(x)", "in;"} {
+		_, ps := runParser(input, keyword)
+		require.False(t, ps.Errored(), input)
+	}
+
+	_, ps := runParser("int", keyword)
+	require.Equal(t, "offset 2: expected word boundary", ps.Error.Error())
+	require.Equal(t, 0, ps.Pos)
+
+	node, ps := runParser("int", Seq("in", NotWordBoundary(), "t"))
+	require.False(t, ps.Errored())
+	require.Equal(t, 2, node.Child[1].Start)
+	require.Equal(t, 2, node.Child[1].End)
+
+	_, ps = runParser("in t", Seq("in", NotWordBoundary()))
+	require.Equal(t, "offset 2: expected no word boundary", ps.Error.Error())
+
+	// At the start and end of the input there is a boundary only next to a word character.
+	_, ps = runParser("x", WordBoundary())
+	require.False(t, ps.Errored())
+	_, ps = runParser("", WordBoundary())
+	require.True(t, ps.Errored())
+}
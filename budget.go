@@ -0,0 +1,78 @@
+package goparsify
+
+import "fmt"
+
+// WithBranchBudget limits each alternative that an Any or Longest tries before its last one to
+// the next n bytes of the input, so on input from users one bad alternative can't scan all the
+// way to the end of it before being backtracked out of. An alternative that would need more
+// than that is abandoned the same way one that doesn't match is, and the next one is tried.
+// The last alternative has no budget of its own, as there is no other to take instead.
+//
+// A Cut, or State.Commit, lifts the budget of the alternatives being taken, so a value that
+// commits early, eg a list after its "[", can be as long as it needs to be:
+//
+//	value = Any(NumberLit(), Seq("[", Cut(), Many(&value, ","), "]"), Chars("a-z"))
+//	Run(value, input, WithBranchBudget(64))
+//
+// Branches only see input up to their budget, so lookahead right at its end, eg NotFollowedBy,
+// can't see past it. Zero, the default, means no limit. RunTokens doesn't limit the branches, as
+// the budget is in bytes of the input.
+func WithBranchBudget(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.branchBudget = n
+	}
+}
+
+// branchWindow is the input a branch of an Any was limited to by WithBranchBudget.
+type branchWindow struct {
+	start  int
+	window string
+	// input is the Input from before the branch was limited, which it goes back to after.
+	input  string
+	lifted bool
+	next   *branchWindow
+}
+
+// limitBranch limits the input of the branch about to be tried at the current position to the
+// budget, returning the window to pass to endBranch, or nil if there is no budget or the input
+// ends within it anyway.
+func (s *State) limitBranch() *branchWindow {
+	if s.cfg().branchBudget == 0 || s.tokens != nil || s.Pos+s.cfg().branchBudget >= len(s.Input) {
+		return nil
+	}
+	w := &branchWindow{start: s.Pos, window: s.Input[:s.Pos+s.cfg().branchBudget], input: s.Input, next: s.branches}
+	s.branches, s.Input = w, w.window
+	return w
+}
+
+// endBranch puts back the input from before w, failing the branch if it ran into the end of
+// its window, as it couldn't tell whether the input went on past it.
+func (s *State) endBranch(w *branchWindow) {
+	if w == nil {
+		return
+	}
+	s.Input, s.branches = w.input, w.next
+	if w.lifted {
+		return
+	}
+	end := len(w.window)
+	if s.Errored() && s.Error.pos >= end || !s.Errored() && s.Pos >= end {
+		s.Error = Error{pos: end, expected: fmt.Sprintf("alternative within %d bytes", s.cfg().branchBudget)}
+		s.Pos = w.start
+	}
+}
+
+// liftBranches lifts the windows of the branches a Commit has taken, as long as nothing else,
+// like FixedWidth or Include, has changed the input since.
+func (s *State) liftBranches() {
+	n := 0
+	for w := s.branches; w != nil && !w.lifted && s.Input == w.window; w = w.next {
+		w.lifted = true
+		s.Input = w.input
+		n++
+	}
+	// Each of them goes back to the input from before the outermost one once it ends.
+	for w := s.branches; n > 0; w, n = w.next, n-1 {
+		w.input = s.Input
+	}
+}
@@ -0,0 +1,57 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBranchBudget(t *testing.T) {
+	long := strings.Repeat("a", 20)
+	parser := Any(Seq(Chars("a"), "!"), Chars("a"))
+
+	tree, err := RunTree(parser, long+"!")
+	require.NoError(t, err)
+	assertSequence(t, tree, long, "!")
+
+	tree, err = RunTree(parser, long, WithBranchBudget(8))
+	require.NoError(t, err)
+	require.Equal(t, long, tree.Token)
+
+	t.Run("abandons branches that would go past it", func(t *testing.T) {
+		_, _, err := Run(parser, long+"!", WithBranchBudget(8))
+		require.EqualError(t, err, "left unparsed: !")
+
+		_, _, err = Run(Any(Seq(Chars("a"), "!"), "b"), long+"!", WithBranchBudget(8))
+		require.EqualError(t, err, "offset 8: expected alternative within 8 bytes")
+
+		_, _, err = Run(Longest(Seq(Chars("a"), "!"), Chars("a")), long+"!", WithBranchBudget(8))
+		require.EqualError(t, err, "left unparsed: !")
+
+		_, _, err = Run(AdaptiveAny(Seq(Chars("a"), "!"), Chars("a")), long+"!", WithBranchBudget(8))
+		require.EqualError(t, err, "left unparsed: !")
+	})
+
+	t.Run("lets branches within it match", func(t *testing.T) {
+		tree, err := RunTree(parser, "aaa!", WithBranchBudget(8))
+		require.NoError(t, err)
+		assertSequence(t, tree, "aaa", "!")
+
+		tree, err = RunTree(Seq(Any(Seq("a", "!"), "a"), Chars("a")), long, WithBranchBudget(4))
+		require.NoError(t, err)
+		assertSequence(t, tree, "a", long[1:])
+	})
+
+	t.Run("is lifted by a cut", func(t *testing.T) {
+		var value Parser
+		value = Any(NumberLit(), Seq("[", Cut(), Many(&value, ","), "]"), Chars("a-z"))
+		input := "[" + strings.TrimSuffix(strings.Repeat("1,", 20), ",") + "]"
+
+		_, _, err := Run(&value, input, WithBranchBudget(8))
+		require.NoError(t, err)
+
+		_, _, err = Run(Seq("(", Any(Seq("[", Many(&value, ","), "]"), "x"), ")"), "("+input+")", WithBranchBudget(8))
+		require.EqualError(t, err, "offset 9: expected alternative within 8 bytes")
+	})
+}
@@ -3,6 +3,8 @@ package calc
 import (
 	"testing"
 
+	"github.com/ijt/goparsify"
+	"github.com/ijt/goparsify/parsifytest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -52,3 +54,20 @@ func TestRecursive(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, 5.4, result)
 }
+
+func TestAnalyze(t *testing.T) {
+	require.Empty(t, goparsify.Analyze(sum))
+}
+
+func TestTable(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		want  float64
+	}{
+		{"2*3-1", 5},
+		{"8/(3-1)", 4},
+		{"-1+3", 2},
+	} {
+		parsifytest.AssertParses(t, y, test.input, test.want)
+	}
+}
@@ -0,0 +1,72 @@
+package goparsify
+
+import "strings"
+
+// capture is an entry in the list of text captured so far. The list is never changed in
+// place, so a Seq can put back the one it started with when it fails.
+type capture struct {
+	name string
+	text string
+	next *capture
+}
+
+// Capture saves the input matched by p under name, for a later MatchCaptured to require the
+// same text, eg the name of an opening tag for the closing one. Captures with the same name
+// stack up, so nested elements each match their own.
+func Capture(name string, p Parserish) Parser {
+	parser := Parsify(p)
+
+	return func(ps *State, node *Result) {
+		startpos := ps.Pos
+		parser(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+		text := node.Token
+		if node.Start >= ps.offset(startpos) && node.Start < node.End && node.End <= ps.offset(ps.Pos) {
+			text = ps.Input[node.Start:node.End]
+		}
+		ps.captures = &capture{name: name, text: text, next: ps.captures}
+	}
+}
+
+// MatchCaptured matches the text last saved under name by Capture and stores it in .Token.
+// It takes the capture off the stack, so the one before it is matched next, eg by the
+// closing tag of the enclosing element.
+func MatchCaptured(name string) Parser {
+	return NewParser("MatchCaptured("+name+")", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("text captured as " + name)
+			return
+		}
+		ps.WS(ps)
+		c := ps.captures
+		for c != nil && c.name != name {
+			c = c.next
+		}
+		if c == nil {
+			ps.ErrorHere("text captured as " + name)
+			return
+		}
+		if !strings.HasPrefix(ps.Get(), c.text) {
+			ps.ErrorHere(c.text)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, len(c.text)) {
+			return
+		}
+		node.Token = c.text
+		node.Start, node.End = ps.Pos, ps.Pos+len(c.text)
+		ps.Advance(len(c.text))
+		ps.captures = withoutCapture(ps.captures, c)
+	})
+}
+
+// withoutCapture returns the list starting at head with c left out, copying the entries in
+// front of it.
+func withoutCapture(head, c *capture) *capture {
+	if head == c {
+		return c.next
+	}
+	return &capture{name: head.name, text: head.text, next: withoutCapture(head.next, c)}
+}
@@ -0,0 +1,58 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	name := Regex(`[a-z]+`)
+	var element Parser
+	element = Seq(
+		"<", Capture("tag", name), ">",
+		Many(Any(&element, Regex(`[^<]+`))),
+		"</", MatchCaptured("tag"), ">",
+	)
+
+	t.Run("closing tags", func(t *testing.T) {
+		node, ps := runParser("<a>x<b>y</b><b></b>z</a>", element)
+		require.False(t, ps.Errored())
+		require.Equal(t, "", ps.Get())
+		require.Equal(t, "a", node.Child[5].Token)
+		require.Nil(t, ps.captures)
+	})
+
+	t.Run("mismatched tags", func(t *testing.T) {
+		_, ps := runParser("<a>x</b>", element)
+		require.Equal(t, "offset 6: expected a", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+
+		_, ps = runParser("<a><b>y</a></b>", element)
+		require.True(t, ps.Errored())
+	})
+
+	t.Run("quotes", func(t *testing.T) {
+		quote := Capture("quote", Any(`"`, `'`))
+		str := Seq(quote, Regex(`[^"']*`), MatchCaptured("quote"))
+
+		node, ps := runParser(`'it"s'`, Seq(quote, Regex(`[^']*`), MatchCaptured("quote")))
+		require.False(t, ps.Errored())
+		require.Equal(t, `it"s`, node.Child[1].Token)
+
+		_, ps = runParser(`"abc'`, str)
+		require.Equal(t, `offset 4: expected "`, ps.Error.Error())
+	})
+
+	t.Run("nothing captured", func(t *testing.T) {
+		_, ps := runParser("a", MatchCaptured("tag"))
+		require.Equal(t, "offset 0: expected text captured as tag", ps.Error.Error())
+	})
+
+	t.Run("undone when backtracking", func(t *testing.T) {
+		p := Seq(Any(Seq(Capture("x", "a"), "!"), "a"), Maybe(MatchCaptured("x")))
+		_, ps := runParser("a a", p)
+		require.False(t, ps.Errored())
+		require.Equal(t, "a", ps.Get())
+	})
+}
@@ -0,0 +1,124 @@
+package goparsify
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// charClass is the set of runes a Chars matcher stands for.
+type charClass struct {
+	// runes holds the single runes and ranges of the matcher.
+	runes *unicode.RangeTable
+	// in and notIn hold the unicode classes given with \p and \P.
+	in    []*unicode.RangeTable
+	notIn []*unicode.RangeTable
+}
+
+func (c *charClass) contains(r rune) bool {
+	if unicode.Is(c.runes, r) || unicode.IsOneOf(c.in, r) {
+		return true
+	}
+	for _, table := range c.notIn {
+		if !unicode.Is(table, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCharClass turns a string in the format a-f01234A-F\p{Greek} into a charClass, made of:
+//   - single runes, eg 01234, with \ escaping the next rune, eg \- for a hyphen
+//   - ranges of runes, eg a-f or α-ω
+//   - unicode classes, eg \p{L} or \pL for letters, \p{Nd} for digits or \p{Greek}, named as in the
+//     Categories, Scripts and Properties tables of the unicode package
+//   - negated unicode classes, eg \P{L} for anything but letters
+func parseCharClass(matcher string) *charClass {
+	c := &charClass{}
+	var ranges [][2]rune
+	runes := []rune(matcher)
+	for i := 0; i < len(runes); {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == 'p' || runes[i+1] == 'P'):
+			name, n := unicodeClassName(runes[i+2:], matcher)
+			table := unicodeClass(name, matcher)
+			if runes[i+1] == 'p' {
+				c.in = append(c.in, table)
+			} else {
+				c.notIn = append(c.notIn, table)
+			}
+			i += 2 + n
+		case i+2 < len(runes) && runes[i+1] == '-' && runes[i] != '\\':
+			start, end := runes[i], runes[i+2]
+			if start > end {
+				start, end = end, start
+			}
+			ranges = append(ranges, [2]rune{start, end})
+			i += 3 // we just consumed 3 runes: range start, hyphen, and range end
+		case i+1 < len(runes) && runes[i] == '\\':
+			ranges = append(ranges, [2]rune{runes[i+1], runes[i+1]})
+			i += 2 // we just consumed 2 runes: escape and the char
+		default:
+			ranges = append(ranges, [2]rune{runes[i], runes[i]})
+			i++
+		}
+	}
+	c.runes = rangeTable(ranges)
+	return c
+}
+
+// unicodeClassName reads the name of a class after \p, either a single letter or a name in
+// braces, returning it and the number of runes it took.
+func unicodeClassName(runes []rune, matcher string) (string, int) {
+	if len(runes) > 0 && runes[0] == '{' {
+		for i := 1; i < len(runes); i++ {
+			if runes[i] == '}' {
+				return string(runes[1:i]), i + 1
+			}
+		}
+	} else if len(runes) > 0 {
+		return string(runes[:1]), 1
+	}
+	panic(fmt.Errorf("unterminated unicode class in %q", matcher))
+}
+
+func unicodeClass(name, matcher string) *unicode.RangeTable {
+	for _, tables := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts, unicode.Properties} {
+		if table, ok := tables[name]; ok {
+			return table
+		}
+	}
+	panic(fmt.Errorf("unknown unicode class %s in %q", name, matcher))
+}
+
+// rangeTable builds a table of the runes in ranges, which may overlap.
+func rangeTable(ranges [][2]rune) *unicode.RangeTable {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var merged [][2]rune
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1]+1 {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	table := &unicode.RangeTable{}
+	for _, r := range merged {
+		if r[1] <= 0xFFFF {
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(r[0]), Hi: uint16(r[1]), Stride: 1})
+			if r[1] <= unicode.MaxLatin1 {
+				table.LatinOffset++
+			}
+			continue
+		}
+		if r[0] <= 0xFFFF {
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(r[0]), Hi: 0xFFFF, Stride: 1})
+			r[0] = 0x10000
+		}
+		table.R32 = append(table.R32, unicode.Range32{Lo: uint32(r[0]), Hi: uint32(r[1]), Stride: 1})
+	}
+	return table
+}
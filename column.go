@@ -0,0 +1,89 @@
+package goparsify
+
+import (
+	"fmt"
+)
+
+// columnScope is a column recorded by ColumnScope, the innermost first.
+type columnScope struct {
+	name   string
+	column int
+	next   *columnScope
+}
+
+// Column returns the column of Pos, or under RunTokens of the token at Pos, counting runes from
+// 1 at the start of its line.
+func (s *State) Column() int {
+	return s.Position().Column
+}
+
+// AtColumn matches the empty string after any whitespace if the next token starts at column n,
+// counting from 1, eg for fixed column formats where a field has to start in column 8.
+func AtColumn(n int) Parser {
+	expected := fmt.Sprintf("column %d", n)
+
+	return NewParser(expected, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(expected)
+			return
+		}
+		ps.WS(ps)
+		if ps.Column() != n {
+			ps.ErrorHere(expected)
+			return
+		}
+		node.Start, node.End = ps.offset(ps.Pos), ps.offset(ps.Pos)
+	})
+}
+
+// ColumnScope records the column the match of p starts at under name, for AlignedWith inside
+// p to line things up with, eg the items of an indented block:
+//
+//	block := ColumnScope("item", Some(Seq(AlignedWith("item"), item)))
+//
+// Scopes nest, and AlignedWith uses the innermost with the name it is given.
+func ColumnScope(name string, p Parserish) Parser {
+	parser := Parsify(p)
+	rule := &grammarRule{kind: "ColumnScope()"}
+
+	return NewParser("ColumnScope("+name+")", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parser, false)
+			return
+		}
+		m := ps.Mark()
+		ps.WS(ps)
+		outer := ps.columns
+		ps.columns = &columnScope{name: name, column: ps.Column(), next: outer}
+		parser(ps, node)
+		ps.columns = outer
+		if ps.Errored() {
+			ps.Restore(m)
+		}
+	})
+}
+
+// AlignedWith matches the empty string after any whitespace if the next token starts at the
+// column recorded by the innermost ColumnScope with the given name.
+func AlignedWith(name string) Parser {
+	return NewParser("AlignedWith("+name+")", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("aligned with " + name)
+			return
+		}
+		ps.WS(ps)
+		scope := ps.columns
+		for scope != nil && scope.name != name {
+			scope = scope.next
+		}
+		if scope == nil {
+			ps.ErrorHere("column scope " + name)
+			return
+		}
+		if ps.Column() != scope.column {
+			ps.ErrorHere(fmt.Sprintf("column %d, aligned with %s", scope.column, name))
+			return
+		}
+		node.Start, node.End = ps.offset(ps.Pos), ps.offset(ps.Pos)
+	})
+}
@@ -0,0 +1,69 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumn(t *testing.T) {
+	ps := NewState("ab\nπc")
+	require.Equal(t, 1, ps.Column())
+	ps.Pos = 2
+	require.Equal(t, 3, ps.Column())
+	ps.Pos = 3
+	require.Equal(t, 1, ps.Column())
+	ps.Pos = 5
+	require.Equal(t, 2, ps.Column())
+
+	ps = NewState("int\n  x")
+	ps.tokens = []Token{{Kind: "ident", Text: "int", Offset: 0}, {Kind: "ident", Text: "x", Offset: 6}}
+	ps.Pos = 1
+	require.Equal(t, 3, ps.Column())
+}
+
+func TestAtColumn(t *testing.T) {
+	record := Seq(Chars("A-Z"), AtColumn(8), Chars("0-9"))
+
+	node, ps := runParser("ABC    123", record)
+	require.False(t, ps.Errored())
+	require.Equal(t, 7, node.Child[1].Start)
+	require.Equal(t, 7, node.Child[1].End)
+	require.Equal(t, "123", node.Child[2].Token)
+
+	_, ps = runParser("ABC   123", record)
+	require.Equal(t, "offset 6: expected column 8", ps.Error.Error())
+	require.Equal(t, 0, ps.Pos)
+}
+
+func TestAlignedWith(t *testing.T) {
+	item := Seq(AlignedWith("item"), Chars("a-z"))
+	block := ColumnScope("item", Some(item))
+
+	node, ps := runParser("  foo\n  bar\n  baz", block)
+	require.False(t, ps.Errored())
+	require.Len(t, node.Child, 3)
+	require.Equal(t, "baz", node.Child[2].Child[1].Token)
+
+	// An item out of line ends the block.
+	node, ps = runParser("  foo\n  bar\n baz", block)
+	require.Equal(t, "\n baz", ps.Get())
+	require.Len(t, node.Child, 2)
+
+	t.Run("nested scopes", func(t *testing.T) {
+		var list Parser
+		entry := Seq(AlignedWith("entry"), Chars("a-z"), Maybe(Seq(":", &list)))
+		list = ColumnScope("entry", Some(entry))
+
+		_, ps := runParser("a:\n  b\n  c\nd", list)
+		require.False(t, ps.Errored())
+
+		_, ps = runParser("a:\n  b\n c", list)
+		require.Equal(t, "\n c", ps.Get())
+	})
+
+	t.Run("without a scope", func(t *testing.T) {
+		_, ps := runParser("foo", item)
+		require.Equal(t, "offset 0: expected column scope item", ps.Error.Error())
+	})
+}
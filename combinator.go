@@ -2,6 +2,7 @@ package goparsify
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 )
 
@@ -9,38 +10,139 @@ import (
 // as .Child[n]. It skips over inputs that do not match the expected parsers
 // but do match the given noise parser.
 func SignalSeq(noise Parserish, signals ...Parserish) Parser {
+	return signalSeqImpl(SignalOptions{}, noise, signals)
+}
+
+// SignalOptions configures BoundedSignalSeq.
+type SignalOptions struct {
+	// MaxNoise is the most chunks of noise, ie matches of the noise parser, allowed between
+	// one signal and the next. Zero means no limit.
+	MaxNoise int
+	// MaxNoiseBytes is the most bytes of input noise can take up between one signal and the
+	// next. Zero means no limit.
+	MaxNoiseBytes int
+	// KeepNoise returns every chunk of noise as a child between the signals, rather than only
+	// those the noise parser gave a result, so callers can see what was skipped, eg to
+	// highlight the signals in the input or look for more in the gaps.
+	KeepNoise bool
+}
+
+// NoiseKey is the annotation in Result.Meta set to true on the children of SignalSeq and
+// SignalSet that are noise rather than signals.
+const NoiseKey = "noise"
+
+// BoundedSignalSeq is like SignalSeq but fails if there is more noise between two signals than
+// opts allow, so "12 ... 5000 words later ... eggs" isn't taken as 12 eggs. Noise before the
+// first signal isn't limited. With the limits left zero it can be used to only keep the noise.
+func BoundedSignalSeq(opts SignalOptions, noise Parserish, signals ...Parserish) Parser {
+	return signalSeqImpl(opts, noise, signals)
+}
+
+func signalSeqImpl(opts SignalOptions, noise Parserish, signals []Parserish) Parser {
 	noiseParser := Parsify(noise)
 	signalParsers := ParsifyAll(signals...)
+	optional, required := optionalSignals(signals)
+	rule := &grammarRule{kind: "SignalSeq()"}
+
+	// nextRequired[i] is the first required signal after signal i, where looking for an
+	// optional signal i stops.
+	nextRequired := make([]Parser, len(signals))
+	var next Parser
+	for i := len(signals) - 1; i >= 0; i-- {
+		nextRequired[i] = next
+		if !optional[i] {
+			next = required[i]
+		}
+	}
+
+	var limit string
+	switch {
+	case opts.MaxNoise > 0 && opts.MaxNoiseBytes > 0:
+		limit = fmt.Sprintf(" within %d chunks or %d bytes of noise", opts.MaxNoise, opts.MaxNoiseBytes)
+	case opts.MaxNoise > 0:
+		limit = fmt.Sprintf(" within %d chunks of noise", opts.MaxNoise)
+	case opts.MaxNoiseBytes > 0:
+		limit = fmt.Sprintf(" within %d bytes of noise", opts.MaxNoiseBytes)
+	}
 
 	return NewParser("SignalSeq()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.signalSeq(ps, rule, noiseParser, signalParsers)
+			return
+		}
 		node.Child = nil
 		startpos := ps.Pos
-		for _, signalParser := range signalParsers {
+		// The span of the signals and how much of it they take up, for the confidence.
+		found, signalStart, signalEnd, signalBytes := false, 0, 0, 0
+		for i, signalParser := range required {
+			noiseStart, chunks, children := ps.Pos, 0, len(node.Child)
+			missing := false
 			for {
 				var c Result
+				signalPos := ps.Pos
 				signalParser(ps, &c)
 				if !ps.Errored() {
 					node.Child = append(node.Child, c)
+					if !found {
+						signalStart = signalPos
+						found = true
+					}
+					signalEnd = ps.Pos
+					signalBytes += ps.Pos - signalPos
 					break
 				}
 				// There is no signal here.
 				// Try parsing a chunk of noise instead.
 				expectedForSignal := ps.Error.expected
+				if optional[i] && nextRequired[i] != nil && lookingAt(ps, nextRequired[i]) {
+					missing = true
+					break
+				}
+				if found && opts.MaxNoise > 0 && chunks >= opts.MaxNoise {
+					if optional[i] {
+						missing = true
+						break
+					}
+					ps.Pos = startpos
+					ps.Error.expected = expectedForSignal + limit
+					return
+				}
+				chunks++
 				ps.Recover()
 				var noiseChild Result
 				noiseParser(ps, &noiseChild)
 				if ps.Errored() {
+					if optional[i] {
+						missing = true
+						break
+					}
 					// Parsing noise didn't work so give up.
 					ps.Pos = startpos
 					ps.Error.expected = expectedForSignal + " or noise"
 					return
 				}
+				if found && opts.MaxNoiseBytes > 0 && ps.Pos-noiseStart > opts.MaxNoiseBytes {
+					if optional[i] {
+						missing = true
+						break
+					}
+					ps.Error = Error{pos: noiseStart, expected: expectedForSignal + limit}
+					ps.Pos = startpos
+					return
+				}
 				// Include noise if it is requested by having its result set
 				// to non-nil.
-				if noiseChild.Result != nil {
+				if noiseChild.Result != nil || opts.KeepNoise {
+					noiseChild.SetMeta(NoiseKey, true)
 					node.Child = append(node.Child, noiseChild)
 				}
 			}
+			if missing {
+				// Leave the noise skipped looking for it to the signals after it.
+				ps.Recover()
+				ps.Pos = noiseStart
+				node.Child = append(node.Child[:children], Result{})
+			}
 		}
 
 		// Run the noise parser until there's nothing left to consume.
@@ -51,38 +153,234 @@ func SignalSeq(noise Parserish, signals ...Parserish) Parser {
 				ps.Recover()
 				break
 			}
-			if noiseChild.Result != nil {
+			if noiseChild.Result != nil || opts.KeepNoise {
+				noiseChild.SetMeta(NoiseKey, true)
 				node.Child = append(node.Child, noiseChild)
 			}
 		}
 
 		var toks []string
 		for _, c := range node.Child {
+			if c.Token != "" {
+				toks = append(toks, c.Token)
+			}
+		}
+		node.Token = strings.Join(toks, " ")
+		node.spanChildren(ps.offset(ps.Pos))
+		node.SetMeta(ConfidenceKey, noiseConfidence(signalBytes, signalStart, signalEnd))
+	})
+}
+
+// OptionalSignal marks a signal of SignalSeq, BoundedSignalSeq or SignalSet that doesn't have to
+// be there, eg the unit in "12 (dozen) eggs". SignalSeq looks for it past the noise up to where
+// the next required signal is, and leaves an empty result in its place if it isn't found.
+// Anywhere else it acts like Maybe.
+func OptionalSignal(signal Parserish) Parserish {
+	return optionalSignal{Parsify(signal)}
+}
+
+type optionalSignal struct {
+	parser Parser
+}
+
+// optionalSignals returns which of signals are optional and the parsers to look for them with.
+func optionalSignals(signals []Parserish) (optional []bool, parsers []Parser) {
+	for _, signal := range signals {
+		o, ok := signal.(optionalSignal)
+		if ok {
+			parsers = append(parsers, o.parser)
+		} else {
+			parsers = append(parsers, Parsify(signal))
+		}
+		optional = append(optional, ok)
+	}
+	return optional, parsers
+}
+
+// lookingAt reports whether p matches at the current position, without moving past it.
+func lookingAt(ps *State, p Parser) bool {
+	pos, cut, err := ps.Pos, ps.Cut, ps.Error
+	ps.Recover()
+	var r Result
+	p(ps, &r)
+	ok := !ps.Errored()
+	ps.Pos, ps.Cut, ps.Error = pos, cut, err
+	return ok
+}
+
+// SignalSet is like SignalSeq but the signals can come in any order, eg "eggs, 12 of them" as
+// well as "12 eggs". Each signal is matched once, the first time it is found between the noise,
+// and is returned as .Child[n] in the order the signals were given. Noise with a result follows
+// them. It fails if the noise runs out before every signal not marked with OptionalSignal has
+// been found.
+func SignalSet(noise Parserish, signals ...Parserish) Parser {
+	noiseParser := Parsify(noise)
+	signalParsers := ParsifyAll(signals...)
+	optional, parsers := optionalSignals(signals)
+	rule := &grammarRule{kind: "SignalSet()"}
+
+	return NewParser("SignalSet()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.signalSeq(ps, rule, noiseParser, signalParsers)
+			return
+		}
+		startpos := ps.Pos
+		found := make([]bool, len(signalParsers))
+		signalResults := make([]Result, len(signalParsers))
+		var noiseResults []Result
+		// inOrder holds the signals in the order they were found, for the token.
+		var inOrder []*Result
+		remaining, requiredRemaining := len(signalParsers), 0
+		for _, o := range optional {
+			if !o {
+				requiredRemaining++
+			}
+		}
+		// The span of the signals and how much of it they take up, for the confidence.
+		signalStart, signalEnd, signalBytes := 0, 0, 0
+
+		for remaining > 0 {
+			var expected []string
+			matched := false
+			for i, signalParser := range parsers {
+				if found[i] {
+					continue
+				}
+				var c Result
+				signalPos := ps.Pos
+				signalParser(ps, &c)
+				if ps.Errored() {
+					expected = append(expected, ps.Error.expected)
+					ps.Recover()
+					continue
+				}
+				if len(inOrder) == 0 {
+					signalStart = signalPos
+				}
+				signalEnd = ps.Pos
+				signalBytes += ps.Pos - signalPos
+				signalResults[i] = c
+				found[i] = true
+				inOrder = append(inOrder, &signalResults[i])
+				remaining--
+				if !optional[i] {
+					requiredRemaining--
+				}
+				matched = true
+				break
+			}
+			if matched {
+				continue
+			}
+
+			// None of the missing signals are here, skip a chunk of noise instead.
+			var noiseChild Result
+			noiseParser(ps, &noiseChild)
+			if ps.Errored() && requiredRemaining == 0 {
+				// Only optional signals are missing, the noise after the last signal has been
+				// skipped looking for them.
+				ps.Recover()
+				break
+			}
+			if ps.Errored() {
+				ps.Pos = startpos
+				ps.Error.expected = strings.Join(expected, " or ") + " or noise"
+				return
+			}
+			if noiseChild.Result != nil {
+				noiseChild.SetMeta(NoiseKey, true)
+				noiseResults = append(noiseResults, noiseChild)
+			}
+		}
+
+		// Run the noise parser until there's nothing left to consume.
+		for {
+			var noiseChild Result
+			noiseParser(ps, &noiseChild)
+			if ps.Errored() {
+				ps.Recover()
+				break
+			}
+			if noiseChild.Result != nil {
+				noiseChild.SetMeta(NoiseKey, true)
+				noiseResults = append(noiseResults, noiseChild)
+			}
+		}
+
+		var toks []string
+		for _, c := range inOrder {
 			toks = append(toks, c.Token)
 		}
 		node.Token = strings.Join(toks, " ")
+		node.Child = append(signalResults, noiseResults...)
+		node.spanChildren(ps.offset(ps.Pos))
+		node.SetMeta(ConfidenceKey, noiseConfidence(signalBytes, signalStart, signalEnd))
 	})
 }
 
 // Seq matches all of the given parsers in order and returns their result as
 // .Child[n]
 func Seq(parsers ...Parserish) Parser {
+	return seq("Seq()", false, parsers)
+}
+
+// SeqPartial is like Seq, but when one of the parsers fails the children matched before it
+// are kept on the node along with the error, with their positions, and the node spans them.
+// With RunTree this gives editors and other tools what could be made of the input up to a
+// mistake, eg the name of a function whose body doesn't parse yet.
+func SeqPartial(parsers ...Parserish) Parser {
+	return seq("SeqPartial()", true, parsers)
+}
+
+func seq(name string, partial bool, parsers []Parserish) Parser {
 	parserfied := ParsifyAll(parsers...)
+	rule := &grammarRule{kind: name}
 
-	return NewParser("Seq()", func(ps *State, node *Result) {
-		node.Child = make([]Result, len(parserfied))
+	return NewParser(name, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.seq(ps, rule, parserfied)
+			return
+		}
+		node.Child = ps.children(len(parserfied), len(parserfied))
+		start := ps.Mark()
 		startpos := ps.Pos
 		for i, parser := range parserfied {
+			pos := ps.Pos
 			parser(ps, &node.Child[i])
 			if ps.Errored() {
-				ps.Pos = startpos
+				if partial {
+					node.Child = node.Child[:i]
+					node.Token = ps.text(startpos, pos)
+					node.spanChildren(ps.offset(pos))
+				}
+				ps.Restore(start)
 				return
 			}
 		}
-		node.Token = ps.Input[startpos:ps.Pos]
+		node.Token = ps.text(startpos, ps.Pos)
+		if ps.cfg().prune {
+			node.pruneEmptyChildren()
+		}
+		node.spanChildren(ps.offset(ps.Pos))
 	})
 }
 
+// PruneEmpty removes the children of the result of parser that matched nothing but
+// whitespace and carry no name, result or annotations, like a Maybe that didn't match. This way
+// the remaining children can be iterated over without checking for them. WithPruneEmpty does
+// the same for every Seq.
+func PruneEmpty(parser Parserish) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		p(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+		node.pruneEmptyChildren()
+	}
+}
+
 // NoAutoWS disables automatically ignoring whitespace between tokens for all parsers underneath
 func NoAutoWS(parser Parserish) Parser {
 	parserfied := Parsify(parser)
@@ -94,30 +392,87 @@ func NoAutoWS(parser Parserish) Parser {
 	}
 }
 
+// Glued is like Seq but doesn't skip whitespace between its parsers, only before the first, so
+// they have to be right next to each other even while whitespace is skipped everywhere else,
+// eg to tell a call like foo( from foo (, or for suffix operators and string prefixes like
+// r"...". Whitespace inside each of the parsers is skipped as usual.
+func Glued(parsers ...Parserish) Parser {
+	parserfied := ParsifyAll(parsers...)
+	glued := make([]Parserish, len(parserfied))
+	for i, p := range parserfied {
+		glued[i] = p
+		if i > 0 {
+			glued[i] = gluedTo(p)
+		}
+	}
+	return seq("Glued()", false, glued)
+}
+
+// gluedTo runs p without skipping the whitespace where it starts.
+func gluedTo(p Parser) Parser {
+	return func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			p(ps, node)
+			return
+		}
+		ws, at := ps.WS, ps.Pos
+		ps.WS = func(ps *State) {
+			if ps.Pos != at {
+				ws(ps)
+			}
+		}
+		p(ps, node)
+		ps.WS = ws
+	}
+}
+
 // AnyWithName matches the first successful parser and returns its result.
 // The name parameter is used in error messages to tell what was expected.
 func AnyWithName(name string, parsers ...Parserish) Parser {
 	parserfied := ParsifyAll(parsers...)
+	rule := &grammarRule{kind: name}
 	// Records which parser was successful for each byte, and will use it first next time.
 
 	return NewParser("Any()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.any(ps, rule, parserfied)
+			return
+		}
 		ps.WS(ps)
-		if ps.Pos >= len(ps.Input) {
+		if ps.Pos >= ps.length() {
 			ps.ErrorHere("!EOF")
 			return
 		}
 		startpos := ps.Pos
 
-		if ps.Cut <= startpos {
+		if !ps.cutSince(startpos) {
 			ps.Recover()
 		} else {
 			return
 		}
 
-		for _, parser := range parserfied {
+		parsers, last := parserfied, len(parserfied)-1
+		if ps.cfg().ambiguity != nil {
+			parsers, last = ps.cfg().ambiguity.choose(parsers)
+		}
+		if ps.cfg().longest {
+			if ok, _ := longestMatch(ps, node, parsers, Error{}); !ok {
+				ps.Error = Error{pos: startpos, expected: name}
+			}
+			return
+		}
+		if ps.lexer != nil && ps.cfg().ambiguity == nil && ps.lexer.dispatch(ps, node, rule, parsers) {
+			return
+		}
+		for i, parser := range parsers {
+			var window *branchWindow
+			if i < last {
+				window = ps.limitBranch()
+			}
 			parser(ps, node)
+			ps.endBranch(window)
 			if ps.Errored() {
-				if ps.Cut > startpos {
+				if ps.cutSince(startpos) {
 					break
 				}
 				ps.Recover()
@@ -131,33 +486,60 @@ func AnyWithName(name string, parsers ...Parserish) Parser {
 	})
 }
 
-// Any matches the first successful parser and returns its result
+// Any matches the first successful parser and returns its result. It panics without any
+// parsers, which could never match.
 func Any(parsers ...Parserish) Parser {
+	if len(parsers) == 0 {
+		panic(fmt.Errorf("Any() needs at least one parser"))
+	}
 	parserfied := ParsifyAll(parsers...)
+	rule := &grammarRule{kind: "Any()"}
 	// Records which parser was successful for each byte, and will use it first next time.
 
 	return NewParser("Any()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.any(ps, rule, parserfied)
+			return
+		}
 		ps.WS(ps)
-		if ps.Pos >= len(ps.Input) {
+		if ps.Pos >= ps.length() {
 			ps.ErrorHere("!EOF")
 			return
 		}
 		startpos := ps.Pos
 
 		longestError := ps.Error
-		if ps.Cut <= startpos {
+		if !ps.cutSince(startpos) {
 			ps.Recover()
 		} else {
 			return
 		}
 
-		for _, parser := range parserfied {
+		parsers, last := parserfied, len(parserfied)-1
+		if ps.cfg().ambiguity != nil {
+			parsers, last = ps.cfg().ambiguity.choose(parsers)
+		}
+		if ps.cfg().longest {
+			if ok, err := longestMatch(ps, node, parsers, longestError); !ok {
+				ps.Error = err
+			}
+			return
+		}
+		if ps.lexer != nil && ps.cfg().ambiguity == nil && ps.lexer.dispatch(ps, node, rule, parsers) {
+			return
+		}
+		for i, parser := range parsers {
+			var window *branchWindow
+			if i < last {
+				window = ps.limitBranch()
+			}
 			parser(ps, node)
+			ps.endBranch(window)
 			if ps.Errored() {
 				if ps.Error.pos >= longestError.pos {
 					longestError = ps.Error
 				}
-				if ps.Cut > startpos {
+				if ps.cutSince(startpos) {
 					break
 				}
 				ps.Recover()
@@ -171,49 +553,196 @@ func Any(parsers ...Parserish) Parser {
 	})
 }
 
+// Longest tries every parser and returns the result of the one that consumed the most input,
+// or the first of them if several tie. Use it when the alternatives overlap and the longest
+// should win regardless of order, eg Longest("a", "ab"). WithLongestMatch makes every Any act
+// like this.
+func Longest(parsers ...Parserish) Parser {
+	if len(parsers) == 0 {
+		panic(fmt.Errorf("Longest() needs at least one parser"))
+	}
+	parserfied := ParsifyAll(parsers...)
+	rule := &grammarRule{kind: "Longest()", longest: true}
+
+	return NewParser("Longest()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.any(ps, rule, parserfied)
+			return
+		}
+		ps.WS(ps)
+		if ok, err := longestMatch(ps, node, parserfied, Error{}); !ok {
+			ps.Error = err
+		}
+	})
+}
+
+// longestMatch runs every parser from the current position and keeps the result of the one
+// that ends furthest, along with the user state and captures it left. It returns whether any
+// matched, and the furthest error otherwise.
+func longestMatch(ps *State, node *Result, parsers []Parser, longestError Error) (bool, Error) {
+	start, cut := ps.Mark(), ps.Cut
+	startpos := start.pos
+	var best Result
+	var bestState Mark
+	bestEnd, bestCut := -1, cut
+	for i, parser := range parsers {
+		var result Result
+		ps.Restore(start)
+		ps.Cut = cut
+		var window *branchWindow
+		if i < len(parsers)-1 {
+			window = ps.limitBranch()
+		}
+		parser(ps, &result)
+		ps.endBranch(window)
+		if ps.Errored() {
+			if ps.Error.pos >= longestError.pos {
+				longestError = ps.Error
+			}
+			if ps.cutSince(startpos) {
+				bestEnd = -1
+				break
+			}
+			ps.Recover()
+			continue
+		}
+		if ps.Pos > bestEnd {
+			best, bestState, bestEnd, bestCut = result, ps.Mark(), ps.Pos, ps.Cut
+		}
+	}
+
+	if bestEnd < 0 {
+		ps.Restore(start)
+		return false, longestError
+	}
+	*node = best
+	ps.Restore(bestState)
+	ps.Cut = bestCut
+	return true, longestError
+}
+
 // Some matches one or more parsers and returns the value as .Child[n]
 // an optional separator can be provided and that value will be consumed
 // but not returned. Only one separator can be provided.
 func Some(parser Parserish, separator ...Parserish) Parser {
-	return NewParser("Some()", manyImpl(1, parser, separator...))
+	return NewParser("Some()", manyImpl("Some()", 1, false, sameItem(parser), separator...))
 }
 
 // Many matches zero or more parsers and returns the value as .Child[n]
 // an optional separator can be provided and that value will be consumed
 // but not returned. Only one separator can be provided.
 func Many(parser Parserish, separator ...Parserish) Parser {
-	return NewParser("Many()", manyImpl(0, parser, separator...))
+	return NewParser("Many()", manyImpl("Many()", 0, false, sameItem(parser), separator...))
 }
 
-func manyImpl(min int, op Parserish, sep ...Parserish) Parser {
-	var opParser = Parsify(op)
+// SomeStrict is like Some, but the list has to end after an item rather than stopping quietly
+// wherever the input stops matching, see ManyStrict.
+func SomeStrict(parser Parserish, separator Parserish) Parser {
+	return NewParser("SomeStrict()", manyImpl("SomeStrict()", 1, true, sameItem(parser), separator))
+}
+
+// ManyStrict is like Many, but doesn't let a list end in the middle of a separator or right after
+// one, as if there were a Cut after each separator. Where Many(item, ",") stops before "d" in
+// "a,b,c,d" when item doesn't match it, and leaves the rest to fail as unparsed input somewhere
+// else, ManyStrict fails there, expecting an item. A separator that matches some input before it
+// fails is an error too, eg the first colon of "a:b" against "::".
+func ManyStrict(parser Parserish, separator Parserish) Parser {
+	return NewParser("ManyStrict()", manyImpl("ManyStrict()", 0, true, sameItem(parser), separator))
+}
+
+// ManyIndexed is like Many, but the parser for each item is the one parserAt returns for its
+// index, counting from 0, eg for a row whose first column is a name and the rest are numbers:
+//
+//	row := ManyIndexed(func(i int) Parser {
+//		if i == 0 {
+//			return name
+//		}
+//		return number
+//	}, ",")
+//
+// parserAt is called for every item of every match, so it should hand out parsers made once
+// rather than make new ones. Analyze and ExportEBNF only see the parser for the first item.
+func ManyIndexed(parserAt func(i int) Parser, separator ...Parserish) Parser {
+	return NewParser("ManyIndexed()", manyImpl("ManyIndexed()", 0, false, parserAt, separator...))
+}
+
+// SomeIndexed is like ManyIndexed but matches one or more items, like Some.
+func SomeIndexed(parserAt func(i int) Parser, separator ...Parserish) Parser {
+	return NewParser("SomeIndexed()", manyImpl("SomeIndexed()", 1, false, parserAt, separator...))
+}
+
+// sameItem returns the parserAt of a list whose items are all matched by p.
+func sameItem(p Parserish) func(i int) Parser {
+	parser := Parsify(p)
+	return func(i int) Parser { return parser }
+}
+
+func manyImpl(kind string, min int, strict bool, parserAt func(i int) Parser, sep ...Parserish) Parser {
 	var sepParser Parser
 	if len(sep) > 0 {
 		sepParser = Parsify(sep[0])
 	}
+	rule := &grammarRule{kind: kind}
 
 	return func(ps *State, node *Result) {
-		node.Child = make([]Result, 0, 5)
-		startpos := ps.Pos
+		if ps.analysis != nil {
+			ps.analysis.many(ps, rule, min, parserAt(0), sepParser)
+			return
+		}
+		node.Child = ps.children(0, 5)
+		// Failing part way through a list puts back the user state and captures of the items
+		// matched so far, not just the position.
+		start := ps.Mark()
+		separated := false
+		// The separators are matched into one Result, made once for the whole list.
+		var sep Result
 		for {
-			node.Child = append(node.Child, Result{})
-			opParser(ps, &node.Child[len(node.Child)-1])
+			itemstart := ps.Pos
+			node.Child = ps.appendChild(node.Child)
+			parserAt(len(node.Child)-1)(ps, &node.Child[len(node.Child)-1])
 			if ps.Errored() {
-				if len(node.Child)-1 < min || ps.Cut > ps.Pos {
-					ps.Pos = startpos
+				if len(node.Child)-1 < min || ps.cutSince(ps.Pos) || separated {
+					ps.Restore(start)
 					return
 				}
 				ps.Recover()
 				node.Child = node.Child[0 : len(node.Child)-1]
+				node.spanChildren(ps.offset(ps.Pos))
+				return
+			}
+			if ps.tooManyChildren(len(node.Child), ps.pos(node.Child[len(node.Child)-1].Start)) {
+				ps.Restore(start)
 				return
 			}
 
 			if sepParser != nil {
-				sepParser(ps, TrashResult)
+				sep = Result{}
+				if strict {
+					// Skipping the whitespace first tells a separator that isn't there from
+					// one that fails part way through.
+					ps.WS(ps)
+				}
+				sepstart := ps.Pos
+				sepParser(ps, &sep)
 				if ps.Errored() {
+					if strict && ps.Error.pos > sepstart {
+						ps.Commit()
+						ps.Restore(start)
+						return
+					}
 					ps.Recover()
+					node.spanChildren(ps.offset(ps.Pos))
 					return
 				}
+				if strict {
+					ps.Commit()
+					separated = true
+				}
+			}
+			if ps.Pos == itemstart {
+				// An item and separator that match nothing would match again forever.
+				node.spanChildren(ps.offset(ps.Pos))
+				return
 			}
 		}
 	}
@@ -222,11 +751,16 @@ func manyImpl(min int, op Parserish, sep ...Parserish) Parser {
 // Maybe will 0 or 1 of the parser
 func Maybe(parser Parserish) Parser {
 	parserfied := Parsify(parser)
+	rule := &grammarRule{kind: "Maybe()"}
 
 	return NewParser("Maybe()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parserfied, true)
+			return
+		}
 		startpos := ps.Pos
 		parserfied(ps, node)
-		if ps.Errored() && ps.Cut <= startpos {
+		if ps.Errored() && !ps.cutSince(startpos) {
 			ps.Recover()
 		}
 	})
@@ -247,6 +781,20 @@ func Bind(parser Parserish, val interface{}) Parser {
 	}
 }
 
+// WithMeta sets the annotation key to value on the result of parser when it matches, see
+// Result.Meta.
+func WithMeta(parser Parserish, key string, value interface{}) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		p(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+		node.SetMeta(key, value)
+	}
+}
+
 // Map applies the callback if the parser matches. This is used to set the Result
 // based on the matched result.
 func Map(parser Parserish, f func(n *Result)) Parser {
@@ -254,13 +802,55 @@ func Map(parser Parserish, f func(n *Result)) Parser {
 
 	return func(ps *State, node *Result) {
 		p(ps, node)
-		if ps.Errored() {
+		if ps.Errored() || ps.analysis != nil {
 			return
 		}
 		f(node)
 	}
 }
 
+// Named sets the node .Name when the given parser matches, so the rule shows up in Result.Dump.
+// Named rules are also listed by Rules and WriteCoverage. Use Rule to name the parser in logs
+// and profiles too.
+func Named(name string, parser Parserish) Parser {
+	return named(name, parser, 1)
+}
+
+// named implements Named, skip is the number of stack frames between the caller and the code
+// defining the rule.
+func named(name string, parser Parserish, skip int) Parser {
+	p := Parsify(parser)
+	registered := newNamedRule(name, skip+1)
+	rule := &grammarRule{kind: "Named()", name: name, named: registered}
+
+	return func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, p, false)
+			return
+		}
+		p(ps, node)
+		if ps.Errored() {
+			return
+		}
+		node.Name = name
+		registered.hit()
+	}
+}
+
+// Kinded sets the node .Kind when the given parser matches, eg to one of the grammar's own
+// constants for the kinds of token it has.
+func Kinded(kind int, parser Parserish) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		p(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+		node.Kind = kind
+	}
+}
+
 func flatten(n *Result) {
 	if len(n.Child) > 0 {
 		sbuf := &bytes.Buffer{}
@@ -4,7 +4,6 @@ import (
 	"testing"
 
 	"fmt"
-	"os"
 
 	"github.com/stretchr/testify/require"
 )
@@ -51,7 +50,7 @@ func TestSignalSeq(t *testing.T) {
 	t.Run("error if missing other signal", func(t *testing.T) {
 		_, _, err := Run(p, "12")
 		require.Error(t, err)
-		require.Equal(t, "offset 2: expected eggs|chickens or noise", err.Error())
+		require.Equal(t, "offset 2: unexpected end of input, expected eggs|chickens or noise", err.Error())
 	})
 
 	t.Run("all signal and no noise is fine", func(t *testing.T) {
@@ -82,6 +81,161 @@ func TestSignalSeq(t *testing.T) {
 	})
 }
 
+func TestBoundedSignalSeq(t *testing.T) {
+	qty := Regex(`\d+`)
+	thing := Regex(`eggs|chickens`)
+	noise := Regex(`\S+`)
+
+	t.Run("max noise", func(t *testing.T) {
+		p := BoundedSignalSeq(SignalOptions{MaxNoise: 2}, noise, qty, thing)
+
+		node, ps := runParser("so many words before 12 large brown eggs", p)
+		assertSequence(t, node, "12", "eggs")
+		require.Equal(t, "", ps.Get())
+
+		_, ps = runParser("12 large brown speckled eggs", p)
+		require.Equal(t, "offset 15: expected eggs|chickens within 2 chunks of noise", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("max noise bytes", func(t *testing.T) {
+		p := BoundedSignalSeq(SignalOptions{MaxNoiseBytes: 10}, noise, qty, thing)
+
+		_, ps := runParser("12 large eggs", p)
+		require.False(t, ps.Errored())
+
+		_, ps = runParser("12 large brown eggs", p)
+		require.Equal(t, "offset 2: expected eggs|chickens within 10 bytes of noise", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("keep noise", func(t *testing.T) {
+		p := BoundedSignalSeq(SignalOptions{KeepNoise: true}, noise, qty, thing)
+
+		node, ps := runParser("buy 12 large eggs today", p)
+		require.False(t, ps.Errored())
+		var tokens []string
+		var noiseTokens []string
+		for _, c := range node.Child {
+			tokens = append(tokens, c.Token)
+			if _, ok := c.GetMeta(NoiseKey); ok {
+				noiseTokens = append(noiseTokens, c.Token)
+			}
+		}
+		require.Equal(t, []string{"buy", "12", "large", "eggs", "today"}, tokens)
+		require.Equal(t, []string{"buy", "large", "today"}, noiseTokens)
+		require.Equal(t, 7, node.Child[2].Start)
+		require.Equal(t, 12, node.Child[2].End)
+	})
+}
+
+func TestOptionalSignal(t *testing.T) {
+	qty := Regex(`\d+`)
+	unit := Regex(`dozen|boxes`)
+	thing := Regex(`eggs|chickens`)
+	noise := Regex(`\S+`)
+
+	t.Run("found", func(t *testing.T) {
+		node, ps := runParser("buy 2 big boxes of eggs", SignalSeq(noise, qty, OptionalSignal(unit), thing))
+		assertSequence(t, node, "2", "boxes", "eggs")
+		require.Equal(t, "", ps.Get())
+		require.Equal(t, "2 boxes eggs", node.Token)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		node, ps := runParser("buy 2 big eggs, by the dozen", SignalSeq(noise, qty, OptionalSignal(unit), thing))
+		assertSequence(t, node, "2", "", "eggs")
+		require.Equal(t, "", ps.Get())
+		require.Equal(t, "2 eggs", node.Token)
+	})
+
+	t.Run("missing at the end", func(t *testing.T) {
+		node, ps := runParser("2 eggs please", SignalSeq(noise, qty, thing, OptionalSignal(unit)))
+		assertSequence(t, node, "2", "eggs", "")
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("beyond the noise limit", func(t *testing.T) {
+		p := BoundedSignalSeq(SignalOptions{MaxNoise: 1}, noise, qty, OptionalSignal(unit))
+		node, ps := runParser("2 eggs and a dozen chickens", p)
+		require.False(t, ps.Errored())
+		assertSequence(t, node, "2", "")
+	})
+
+	t.Run("required signals still are", func(t *testing.T) {
+		_, ps := runParser("2 dozen", SignalSeq(noise, qty, OptionalSignal(unit), thing))
+		require.Equal(t, "offset 7: expected eggs|chickens or noise", ps.Error.Error())
+	})
+
+	t.Run("signal set", func(t *testing.T) {
+		p := SignalSet(noise, qty, OptionalSignal(unit), thing)
+		node, ps := runParser("eggs, 2 dozen", p)
+		assertSequence(t, node, "2", "dozen", "eggs")
+		require.Equal(t, "", ps.Get())
+
+		node, ps = runParser("eggs, 2 of them", p)
+		assertSequence(t, node, "2", "", "eggs")
+		require.Equal(t, "", ps.Get())
+
+		_, ps = runParser("dozen eggs", p)
+		require.True(t, ps.Errored())
+	})
+
+	t.Run("maybe elsewhere", func(t *testing.T) {
+		node, ps := runParser("2 eggs", Seq(qty, OptionalSignal(unit), thing))
+		require.False(t, ps.Errored())
+		require.Equal(t, "eggs", node.Child[2].Token)
+	})
+}
+
+func TestSignalSet(t *testing.T) {
+	qty := Regex(`\d+`)
+	thing := Regex(`eggs|chickens`)
+	noise := Regex(`\S+`)
+	p := SignalSet(noise, qty, thing)
+
+	t.Run("signals in order", func(t *testing.T) {
+		node, ps := runParser("buy 12 large eggs", p)
+		assertSequence(t, node, "12", "eggs")
+		require.Equal(t, "", ps.Get())
+		require.Equal(t, "12 eggs", node.Token)
+	})
+
+	t.Run("signals in reverse", func(t *testing.T) {
+		node, ps := runParser("eggs, 12 of them", p)
+		assertSequence(t, node, "12", "eggs")
+		require.Equal(t, "", ps.Get())
+		require.Equal(t, "eggs 12", node.Token)
+	})
+
+	t.Run("each signal is matched once", func(t *testing.T) {
+		node, ps := runParser("12 34 eggs", p)
+		assertSequence(t, node, "12", "eggs")
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("error if missing signal", func(t *testing.T) {
+		_, _, err := Run(p, "eggs, lots of them")
+		require.Equal(t, "offset 18: unexpected end of input, expected \\d+ or noise", err.Error())
+	})
+
+	t.Run("error if all noise", func(t *testing.T) {
+		_, ps := runParser("a b", p)
+		require.Equal(t, "offset 3: expected \\d+ or eggs|chickens or noise", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("noise results come after the signals", func(t *testing.T) {
+		dogs := NamedRegex("wild dog", "coyote|fox").Map(func(n *Result) {
+			n.Result = n.Token
+		})
+		p := SignalSet(AnyWithName("random", dogs, noise), qty, thing)
+
+		node, _ := runParser("the fox ate eggs 12 times", p)
+		assertSequence(t, node, "12", "eggs", "fox")
+	})
+}
+
 func TestSeq(t *testing.T) {
 	parser := Seq("hello", "world")
 
@@ -106,6 +260,34 @@ func TestSeq(t *testing.T) {
 	})
 }
 
+func TestSeqPartial(t *testing.T) {
+	parser := SeqPartial("func", Chars("a-z"), "(", ")", "{")
+
+	t.Run("matches sequence", func(t *testing.T) {
+		node, p2 := runParser("func main() {", parser)
+		assertSequence(t, node, "func", "main", "(", ")", "{")
+		require.Equal(t, "", p2.Get())
+	})
+
+	t.Run("keeps the children before an error", func(t *testing.T) {
+		node, p2 := runParser("func main(x", parser)
+		require.Equal(t, "offset 10: expected )", p2.Error.Error())
+		require.Equal(t, 0, p2.Pos)
+		assertSequence(t, node, "func", "main", "(")
+		require.Equal(t, 5, node.Child[1].Start)
+		require.Equal(t, 9, node.Child[1].End)
+		require.Equal(t, 0, node.Start)
+		require.Equal(t, 10, node.End)
+		require.Equal(t, "func main(", node.Token)
+	})
+
+	t.Run("fails on the first parser", func(t *testing.T) {
+		node, p2 := runParser("  var x", parser)
+		require.True(t, p2.Errored())
+		require.Empty(t, node.Child)
+	})
+}
+
 func TestNestedSeq(t *testing.T) {
 	parser := Seq(Seq("a", "b"), "c", Seq("d", "e"))
 
@@ -208,7 +390,6 @@ func TestAny(t *testing.T) {
 	})
 
 	t.Run("overlapping longest match", func(t *testing.T) {
-		EnableLogging(os.Stdout)
 		p := Many(Any("ab", "a"))
 
 		t.Run("a ab", func(t *testing.T) {
@@ -232,6 +413,65 @@ func TestAny(t *testing.T) {
 	})
 }
 
+func TestLongest(t *testing.T) {
+	t.Run("picks the longest match", func(t *testing.T) {
+		node, p := runParser("abc", Longest("a", "abc", "ab"))
+		require.False(t, p.Errored())
+		require.Equal(t, "abc", node.Token)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("prefers earlier parsers on a tie", func(t *testing.T) {
+		node, p := runParser("ab", Longest(Seq("a", "b"), "ab"))
+		require.False(t, p.Errored())
+		require.Len(t, node.Child, 2)
+	})
+
+	t.Run("returns the furthest error", func(t *testing.T) {
+		_, p := runParser("abx", Longest(Seq("a", "b", "c"), "x"))
+		require.Equal(t, "offset 2: expected c", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("respects cuts", func(t *testing.T) {
+		_, p := runParser("ab", Longest(Seq("a", Cut(), "c"), "ab"))
+		require.Equal(t, "offset 1: expected c", p.Error.Error())
+	})
+
+	t.Run("keeps the user state and captures of the longest only", func(t *testing.T) {
+		p := Seq(Longest(Capture("t", "abc"), Capture("t", "ab")), "-", MatchCaptured("t"))
+		_, _, err := Run(p, "abc-abc")
+		require.NoError(t, err)
+
+		mark := func(name string) func(state interface{}, n *Result) interface{} {
+			return func(state interface{}, n *Result) interface{} { return name }
+		}
+		_, ps := runParser("abc", Longest(UpdateState("abc", mark("long")), UpdateState("ab", mark("short"))))
+		require.Equal(t, "long", ps.GetUserState())
+	})
+
+	t.Run("is not reported by Analyze", func(t *testing.T) {
+		require.Empty(t, Analyze(Longest("a", "ab", Maybe("c"), "d")))
+	})
+}
+
+func TestWithLongestMatch(t *testing.T) {
+	p := Many(Any("a", "ab"))
+
+	result, _, err := Run(p.Map(func(n *Result) { n.Result = len(n.Child) }), "ab a", WithLongestMatch())
+	require.NoError(t, err)
+	require.Equal(t, 2, result)
+
+	_, _, err = Run(p, "ab a")
+	require.Error(t, err)
+
+	_, _, err = Run(AnyWithName("letter", "a", "ab"), "ab", WithLongestMatch())
+	require.NoError(t, err)
+
+	_, _, err = Run(AnyWithName("letter", "a", "ab"), "c", WithLongestMatch())
+	require.EqualError(t, err, "offset 0: expected letter")
+}
+
 func TestSome(t *testing.T) {
 	t.Run("Does not match empty input", func(t *testing.T) {
 		_, _, err := Run(Some(Chars("a-g"), Exact(",")), "")
@@ -308,6 +548,124 @@ func TestMany(t *testing.T) {
 		require.False(t, p2.Errored())
 		require.Equal(t, "a,b,c,d,e,", p2.Get())
 	})
+	t.Run("Stops at an item that matches nothing", func(t *testing.T) {
+		node, p2 := runParser("aab", Many(Maybe("a")))
+		require.False(t, p2.Errored())
+		assertSequence(t, node, "a", "a", "")
+		require.Equal(t, "b", p2.Get())
+
+		node, p2 = runParser("a,,b", Many(Maybe("a"), Maybe(",")))
+		require.False(t, p2.Errored())
+		assertSequence(t, node, "a", "", "")
+		require.Equal(t, "b", p2.Get())
+	})
+}
+
+func TestManyStrict(t *testing.T) {
+	t.Run("Matches sequence with sep", func(t *testing.T) {
+		node, p2 := runParser("a, b ,c", ManyStrict(Chars("a-g"), ","))
+		require.False(t, p2.Errored())
+		assertSequence(t, node, "a", "b", "c")
+		require.Equal(t, "", p2.Get())
+	})
+
+	t.Run("Matches empty input", func(t *testing.T) {
+		_, _, err := Run(ManyStrict(Chars("a-g"), ","), "")
+		require.NoError(t, err)
+	})
+
+	t.Run("Stops where the sep doesn't match", func(t *testing.T) {
+		node, p2 := runParser("a,b;c", ManyStrict(Chars("a-g"), ","))
+		require.False(t, p2.Errored())
+		assertSequence(t, node, "a", "b")
+		require.Equal(t, ";c", p2.Get())
+	})
+
+	t.Run("Fails on an item missing after a sep", func(t *testing.T) {
+		_, p2 := runParser("a,b,c,d,e", ManyStrict(Chars("abc"), ","))
+		require.Equal(t, "offset 6: expected abc", p2.Error.Error())
+		require.Equal(t, 0, p2.Pos)
+
+		_, _, err := Run(ManyStrict(Chars("a-g"), ","), "a,b,")
+		require.EqualError(t, err, "offset 4: unexpected end of input, expected a-g")
+	})
+
+	t.Run("Fails on a partly matched sep", func(t *testing.T) {
+		_, _, err := Run(ManyStrict(Chars("a-g"), Seq(":", ":")), "a::b :c")
+		require.EqualError(t, err, "offset 6: expected :")
+	})
+
+	t.Run("Isn't backtracked out of", func(t *testing.T) {
+		_, _, err := Run(Any(Seq(ManyStrict(Chars("a-g"), ","), ";"), Chars("a-z,")), "a,b,1")
+		require.EqualError(t, err, "offset 4: expected a-g")
+
+		_, _, err = Run(Any(Seq(Many(Chars("a-g"), ","), ";"), Chars("a-z,")), "a,b,1")
+		require.EqualError(t, err, "left unparsed: 1")
+	})
+	t.Run("Puts back user state and captures when it fails", func(t *testing.T) {
+		letter := Chars("a-g")
+		item := func(ps *State, node *Result) {
+			letter(ps, node)
+			if !ps.Errored() {
+				ps.SetUserState(ps.GetUserState().(int) + 1)
+			}
+		}
+		ps := NewState("a,b,1")
+		ps.SetUserState(0)
+		ManyStrict(item, ",")(ps, &Result{})
+		require.Equal(t, "offset 4: expected a-g", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+		require.Equal(t, 0, ps.GetUserState())
+
+		_, ps = runParser("a,b,1", ManyStrict(Capture("x", Chars("a-g")), ","))
+		require.True(t, ps.Errored())
+		require.Nil(t, ps.captures)
+	})
+}
+
+func TestSomeStrict(t *testing.T) {
+	_, _, err := Run(SomeStrict(Chars("a-g"), ","), "")
+	require.EqualError(t, err, "offset 0: unexpected end of input, expected a-g")
+
+	node, p2 := runParser("a,b", SomeStrict(Chars("a-g"), ","))
+	require.False(t, p2.Errored())
+	assertSequence(t, node, "a", "b")
+
+	_, _, err = Run(SomeStrict(Chars("a-g"), ","), "a,")
+	require.EqualError(t, err, "offset 2: unexpected end of input, expected a-g")
+}
+
+func TestManyIndexed(t *testing.T) {
+	name, number := Chars("a-z"), Chars("0-9")
+	row := ManyIndexed(func(i int) Parser {
+		if i == 0 {
+			return name
+		}
+		return number
+	}, ",")
+
+	t.Run("Matches each item with its parser", func(t *testing.T) {
+		node, p2 := runParser("abc,1,22,333", row)
+		require.False(t, p2.Errored())
+		assertSequence(t, node, "abc", "1", "22", "333")
+	})
+
+	t.Run("Stops where an item doesn't match its parser", func(t *testing.T) {
+		node, p2 := runParser("abc,1,x", row)
+		require.False(t, p2.Errored())
+		assertSequence(t, node, "abc", "1")
+		require.Equal(t, "x", p2.Get())
+
+		node, p2 = runParser("1,2", row)
+		require.False(t, p2.Errored())
+		require.Len(t, node.Child, 0)
+		require.Equal(t, "1,2", p2.Get())
+	})
+
+	t.Run("Some needs the first item", func(t *testing.T) {
+		_, p2 := runParser("1,2", SomeIndexed(func(i int) Parser { return name }))
+		require.Equal(t, "offset 0: expected a-z", p2.Error.Error())
+	})
 }
 
 type htmlTag struct {
@@ -347,6 +705,117 @@ func TestBind(t *testing.T) {
 	})
 }
 
+func TestWithMeta(t *testing.T) {
+	parser := Seq(WithMeta("let", "kind", "keyword"), Chars("a-z"))
+
+	t.Run("success", func(t *testing.T) {
+		result, _ := runParser("let x", parser)
+		kind, ok := result.Child[0].GetMeta("kind")
+		require.True(t, ok)
+		require.Equal(t, "keyword", kind)
+
+		_, ok = result.Child[1].GetMeta("kind")
+		require.False(t, ok)
+
+		result.Child[1].SetMeta("type", "int")
+		require.Equal(t, map[string]interface{}{"type": "int"}, result.Child[1].Meta)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		result, ps := runParser("var x", parser)
+		require.Nil(t, result.Meta)
+		require.Equal(t, "offset 0: expected let", ps.Error.Error())
+	})
+}
+
+func TestKinded(t *testing.T) {
+	const (
+		keyword = iota + 1
+		ident
+	)
+	parser := Seq(Kinded(keyword, "let"), Kinded(ident, Chars("a-z")), "=")
+
+	t.Run("success", func(t *testing.T) {
+		result, _ := runParser("let x =", parser)
+		require.Equal(t, keyword, result.Child[0].Kind)
+		require.Equal(t, ident, result.Child[1].Kind)
+		require.Equal(t, 0, result.Child[2].Kind)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		result, ps := runParser("var x", parser)
+		require.Equal(t, 0, result.Kind)
+		require.Equal(t, "offset 0: expected let", ps.Error.Error())
+	})
+}
+
+func TestPruneEmpty(t *testing.T) {
+	parser := Seq("a", Maybe("b"), NoAutoWS(Chars(" ", 0)), Named("c", Maybe("c")), "d")
+
+	t.Run("PruneEmpty", func(t *testing.T) {
+		result, ps := runParser("a  d", PruneEmpty(parser))
+		require.False(t, ps.Errored())
+		require.Len(t, result.Child, 3)
+		require.Equal(t, "a", result.Child[0].Token)
+		require.Equal(t, "c", result.Child[1].Name)
+		require.Equal(t, "d", result.Child[2].Token)
+	})
+
+	t.Run("WithPruneEmpty", func(t *testing.T) {
+		result, _, err := Run(parser.Map(func(n *Result) {
+			n.Result = len(n.Child)
+		}), "a b d", WithPruneEmpty())
+		require.NoError(t, err)
+		require.Equal(t, 4, result)
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		result, ps := runParser("a  d", parser)
+		require.False(t, ps.Errored())
+		require.Len(t, result.Child, 5)
+	})
+}
+
+func TestGlued(t *testing.T) {
+	ident := Chars("a-z")
+	call := Seq(Glued(ident, "("), Maybe(ident), ")")
+
+	t.Run("matches adjacent parts", func(t *testing.T) {
+		node, ps := runParser("  foo( x )", call)
+		require.False(t, ps.Errored())
+		assertSequence(t, node.Child[0], "foo", "(")
+		require.Equal(t, 2, node.Start)
+
+		_, ps = runParser("f(x )", Glued(ident, "(", ident, ")"))
+		require.Equal(t, "offset 3: expected )", ps.Error.Error())
+	})
+
+	t.Run("fails on whitespace between parts", func(t *testing.T) {
+		_, ps := runParser("foo (x)", call)
+		require.Equal(t, "offset 3: expected (", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("skips whitespace inside parts", func(t *testing.T) {
+		parser := Glued("r", Seq(`"`, ident, `"`))
+		_, ps := runParser(`r" x "`, parser)
+		require.False(t, ps.Errored())
+
+		_, ps = runParser(`r "x"`, parser)
+		require.True(t, ps.Errored())
+	})
+
+	t.Run("through alternatives", func(t *testing.T) {
+		parser := Glued(ident, Any("++", "--"))
+		_, ps := runParser("i ++", parser)
+		require.Equal(t, "offset 1: expected --", ps.Error.Error())
+
+		node, ps := runParser("i++", parser)
+		require.False(t, ps.Errored())
+		require.Equal(t, "i++", node.Token)
+	})
+}
+
 func TestCut(t *testing.T) {
 	t.Run("test any", func(t *testing.T) {
 		_, ps := runParser("var world", Any(Seq("var", Cut(), "hello"), "var world"))
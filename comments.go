@@ -0,0 +1,158 @@
+package goparsify
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommentsKey is the annotation in Result.Meta holding the *Comments attached to a result by
+// AttachComments.
+const CommentsKey = "comments"
+
+// Comment is a comment found in the input skipped between tokens.
+type Comment struct {
+	// Text is the whole comment, including the likes of // or /* */.
+	Text string
+	// Start and End are the byte offsets of the comment.
+	Start, End int
+}
+
+// Comments are the comments attached to a result, see AttachComments.
+type Comments struct {
+	// Leading are the comments just before the result, eg its doc comment.
+	Leading []Comment
+	// Trailing are the comments after the result on the line it ends on.
+	Trailing []Comment
+	// Dangling are the comments inside the result that belong to none of its named results.
+	Dangling []Comment
+}
+
+// AttachComments finds the comments matched by comment in the input the tree under root skipped
+// between its tokens, usually as part of the whitespace, and attaches them to its named results
+// under CommentsKey in Meta, where tools like documentation generators and linters expect them:
+//
+//   - a comment on the line a named result ends on, after it, trails the outermost one ending
+//     there, eg `x := 1 // the answer`
+//   - a comment on a line of its own, or a block of them, with no blank line before the next
+//     token leads the outermost named result starting there, like a doc comment
+//   - any other comment dangles in the innermost named result around it, or root if there is
+//     none, eg a comment at the end of a block or separated from what follows by a blank line
+//
+// input is the input root was parsed from. comment is matched without skipping whitespace.
+func AttachComments(root *Result, input string, comment Parserish) {
+	parser := Parsify(comment)
+	var named []*Result
+	var tokens [][2]int
+	Walk(root, func(n *Result, depth int) bool {
+		if n.Name != "" && n.Start < n.End {
+			named = append(named, n)
+		}
+		if len(n.Child) == 0 && n.Start < n.End {
+			tokens = append(tokens, [2]int{n.Start, n.End})
+		}
+		return true
+	})
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i][0] < tokens[j][0] })
+
+	a := &commentAttacher{root: root, input: input, named: named, ps: NewState(input)}
+	a.ps.WS = NoWhitespace
+	pos := 0
+	for i := 0; i <= len(tokens); i++ {
+		end := len(input)
+		if i < len(tokens) {
+			end = tokens[i][0]
+		}
+		if end > pos {
+			a.gap(parser, pos, end)
+		}
+		if i < len(tokens) && tokens[i][1] > pos {
+			pos = tokens[i][1]
+		}
+	}
+}
+
+// CommentsOf returns the comments attached to r by AttachComments, or nil if there are none.
+func CommentsOf(r *Result) *Comments {
+	comments, _ := r.Meta[CommentsKey].(*Comments)
+	return comments
+}
+
+// commentAttacher attaches the comments between the tokens of a tree.
+type commentAttacher struct {
+	root  *Result
+	input string
+	// named holds the named results of the tree, parents before their children.
+	named []*Result
+	ps    *State
+}
+
+// gap attaches the comments in the input from start to end, which has no tokens in it.
+func (a *commentAttacher) gap(parser Parser, start, end int) {
+	var comments []Comment
+	for pos := start; pos < end; {
+		a.ps.Pos = pos
+		a.ps.Recover()
+		parser(a.ps, &Result{})
+		if a.ps.Errored() || a.ps.Pos <= pos || a.ps.Pos > end {
+			_, w := utf8.DecodeRuneInString(a.input[pos:end])
+			pos += w
+			continue
+		}
+		comments = append(comments, Comment{Text: a.input[pos:a.ps.Pos], Start: pos, End: a.ps.Pos})
+		pos = a.ps.Pos
+	}
+
+	// The comments on the line of the token before trail what ends there, and the block of
+	// them right before the next token leads what starts there.
+	trailing := 0
+	if before := a.outermost(func(n *Result) bool { return n.End == start }); before != nil {
+		for trailing < len(comments) && !strings.Contains(a.input[start:comments[trailing].Start], "\n") {
+			trailing++
+		}
+		if trailing > 0 {
+			commentsOn(before).Trailing = append(commentsOn(before).Trailing, comments[:trailing]...)
+		}
+	}
+	leading := len(comments)
+	if after := a.outermost(func(n *Result) bool { return n.Start == end }); after != nil {
+		next := end
+		for leading > trailing && strings.Count(a.input[comments[leading-1].End:next], "\n") < 2 {
+			leading--
+			next = comments[leading].Start
+		}
+		if leading < len(comments) {
+			commentsOn(after).Leading = append(commentsOn(after).Leading, comments[leading:]...)
+		}
+	}
+
+	for _, c := range comments[trailing:leading] {
+		around := a.root
+		for _, n := range a.named {
+			if n.Start <= c.Start && c.End <= n.End {
+				around = n
+			}
+		}
+		commentsOn(around).Dangling = append(commentsOn(around).Dangling, c)
+	}
+}
+
+// outermost returns the first named result, and so the outermost, that match is true for.
+func (a *commentAttacher) outermost(match func(n *Result) bool) *Result {
+	for _, n := range a.named {
+		if match(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+// commentsOn returns the Comments of r, adding them if it has none yet.
+func commentsOn(r *Result) *Comments {
+	if comments := CommentsOf(r); comments != nil {
+		return comments
+	}
+	comments := &Comments{}
+	r.SetMeta(CommentsKey, comments)
+	return comments
+}
@@ -0,0 +1,64 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachComments(t *testing.T) {
+	comment := Regex(`//[^\n]*`)
+	whitespace := func(s *State) {
+		for {
+			UnicodeWhitespace(s)
+			if !strings.HasPrefix(s.Get(), "//") {
+				return
+			}
+			if i := strings.IndexByte(s.Get(), '\n'); i >= 0 {
+				s.Advance(i)
+			} else {
+				s.Pos = len(s.Input)
+			}
+		}
+	}
+	let := Named("let", Seq("let", Named("name", Chars("a-z")), "=", Chars("0-9"), ";"))
+	block := Named("block", Seq("{", Many(let), "}"))
+
+	input := `// Package stuff.
+
+// a is the first.
+// It is documented.
+let a = 1; // one
+{
+	let b = 2;
+
+	// Nothing follows.
+}
+// The end.`
+	tree, err := RunTree(Seq(Many(let), block), input, WithWhitespace(whitespace))
+	require.NoError(t, err)
+	AttachComments(&tree, input, comment)
+
+	texts := func(comments []Comment) []string {
+		var texts []string
+		for _, c := range comments {
+			require.Equal(t, input[c.Start:c.End], c.Text)
+			texts = append(texts, c.Text)
+		}
+		return texts
+	}
+
+	a := &tree.Child[0].Child[0]
+	require.Equal(t, "let", a.Name)
+	require.Equal(t, []string{"// a is the first.", "// It is documented."}, texts(CommentsOf(a).Leading))
+	require.Equal(t, []string{"// one"}, texts(CommentsOf(a).Trailing))
+	require.Nil(t, CommentsOf(a.Get("name")))
+
+	b := &tree.Child[1]
+	require.Equal(t, "block", b.Name)
+	require.Equal(t, []string{"// Nothing follows."}, texts(CommentsOf(b).Dangling))
+	require.Nil(t, CommentsOf(&b.Child[1].Child[0]))
+
+	require.Equal(t, []string{"// Package stuff.", "// The end."}, texts(CommentsOf(&tree).Dangling))
+}
@@ -0,0 +1,80 @@
+package goparsify
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrentRuns runs one grammar from many goroutines at once with different options.
+// Run it with -race to check that parses share no state.
+func TestConcurrentRuns(t *testing.T) {
+	var value Parser
+	name := Regex(`[a-z]+`)
+	element := Seq("<", Capture("tag", name), ">", Many(&value), "</", MatchCaptured("tag"), ">")
+	list := Seq("[", Many(&value, ","), "]")
+	declared := UpdateState(Seq("let", name), func(state interface{}, n *Result) interface{} {
+		count, _ := state.(int)
+		return count + 1
+	})
+	value = Any(NumberLit(), StringLit(`"`), list, element, declared, FuzzyAny(1, "true", "false"))
+
+	inputs := []string{
+		`[1, "two", [3.5, tru], <a>[<b>false</b>]</a>]`,
+		`<x>let y [let z] 4</x>`,
+		`[[[[]]], "\"", fals]`,
+	}
+	opts := [][]Option{
+		nil,
+		{WithLongestMatch()},
+		{WithPruneEmpty(), WithTrivia()},
+		{WithUserState(10), WithMaxDepth(20)},
+	}
+
+	want := map[string]string{}
+	for _, input := range inputs {
+		result, err := RunTree(&value, input)
+		require.NoError(t, err)
+		want[input] = dumpString(result)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				input := inputs[(g+i)%len(inputs)]
+				options := append([]Option{WithTrace(&bytes.Buffer{}), WithAttempts(&bytes.Buffer{})}, opts[g%len(opts)]...)
+				result, err := RunTree(&value, input, options...)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if got := dumpString(result); got != want[input] && g%len(opts) == 0 {
+					errs <- fmt.Errorf("parsing %q got\n%s\nwant\n%s", input, got, want[input])
+					return
+				}
+				if _, err := RunAllParses(&value, input, WithMaxParses(2)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func dumpString(r Result) string {
+	var b bytes.Buffer
+	r.Dump(&b)
+	return b.String()
+}
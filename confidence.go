@@ -0,0 +1,128 @@
+package goparsify
+
+// ConfidenceKey is the annotation in Result.Meta holding how sure the parser that made a
+// result is that it matched what was meant, as a float64 from 0 to 1. Results without it are
+// taken as certain, as an Exact match is. Fuzzy and FuzzyAny set it lower the more edits a
+// match needed, and SignalSeq and SignalSet the more noise there was between their signals.
+const ConfidenceKey = "confidence"
+
+// Confidence returns the confidence of the interpretation of the input under r, the product of
+// every confidence set in the tree. It ranks competing parses, eg from RunAllParses, against
+// each other. Since the confidences are kept on the results, the ones of branches that were
+// backtracked out of don't count.
+func (r *Result) Confidence() float64 {
+	confidence := 1.0
+	Walk(r, func(n *Result, depth int) bool {
+		if c, ok := n.Meta[ConfidenceKey].(float64); ok {
+			confidence *= c
+		}
+		return true
+	})
+	return confidence
+}
+
+// Scored sets the confidence of the result of parser to what score returns for it when it
+// matches, to weigh in things only the grammar knows, eg that a quantity over 1000 is
+// probably a misparse. The confidence of its children still counts.
+func Scored(parser Parserish, score func(n *Result) float64) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		p(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+		node.SetMeta(ConfidenceKey, clampConfidence(score(node)))
+	}
+}
+
+// editConfidence is the confidence in a match of a word of length runes that needed dist edits.
+func editConfidence(dist, length int) float64 {
+	if length == 0 {
+		return 1
+	}
+	return clampConfidence(1 - float64(dist)/float64(length))
+}
+
+// noiseConfidence is the confidence in signals taking up signalBytes of the input between start
+// and end, the rest of it being noise.
+func noiseConfidence(signalBytes, start, end int) float64 {
+	if end <= start {
+		return 1
+	}
+	return clampConfidence(float64(signalBytes) / float64(end-start))
+}
+
+func clampConfidence(c float64) float64 {
+	switch {
+	case c < 0:
+		return 0
+	case c > 1:
+		return 1
+	}
+	return c
+}
+
+// BestExtraction runs every pattern from the same position and returns the result of the one
+// that scores best, rather than the first that matches, so several extraction grammars can be
+// tried on free text, eg SignalSeq(noise, qty, thing) and SignalSeq(noise, qty, unit, thing). The
+// score is the Confidence of a result times the number of signals it found, ie its children
+// that aren't noise or a missing OptionalSignal, so a pattern that explains more of the input
+// wins unless it is much less sure of it. The first of the patterns that tie wins.
+func BestExtraction(patterns ...Parserish) Parser {
+	parserfied := ParsifyAll(patterns...)
+	rule := &grammarRule{kind: "BestExtraction()", longest: true}
+
+	return NewParser("BestExtraction()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.any(ps, rule, parserfied)
+			return
+		}
+		start, cut := ps.Mark(), ps.Cut
+		startpos := start.pos
+		var best Result
+		var bestState Mark
+		bestScore, bestCut := -1.0, cut
+		var furthestError Error
+		for _, parser := range parserfied {
+			var result Result
+			ps.Restore(start)
+			ps.Cut = cut
+			parser(ps, &result)
+			if ps.Errored() {
+				if ps.Error.pos >= furthestError.pos {
+					furthestError = ps.Error
+				}
+				if ps.cutSince(startpos) {
+					bestScore = -1
+					break
+				}
+				ps.Recover()
+				continue
+			}
+			if score := extractionScore(&result); score > bestScore {
+				best, bestScore, bestState, bestCut = result, score, ps.Mark(), ps.Cut
+			}
+		}
+
+		if bestScore < 0 {
+			ps.Restore(start)
+			ps.Error = furthestError
+			return
+		}
+		*node = best
+		// Only the pattern picked keeps the user state and captures it left.
+		ps.Restore(bestState)
+		ps.Cut = bestCut
+	})
+}
+
+func extractionScore(r *Result) float64 {
+	signals := 0
+	for _, c := range r.Child {
+		if _, noise := c.GetMeta(NoiseKey); !noise && (c.Start < c.End || c.Token != "") {
+			signals++
+		}
+	}
+	return r.Confidence() * float64(signals)
+}
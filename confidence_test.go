@@ -0,0 +1,115 @@
+package goparsify
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfidence(t *testing.T) {
+	qty := Regex(`\d+`)
+	thing := FuzzyAny(2, "eggs", "chickens")
+	noise := Regex(`\S+`)
+
+	t.Run("exact matches are certain", func(t *testing.T) {
+		node, ps := runParser("12 eggs", Seq(qty, "eggs"))
+		require.False(t, ps.Errored())
+		require.Equal(t, 1.0, node.Confidence())
+	})
+
+	t.Run("fuzzy matches", func(t *testing.T) {
+		node, _ := runParser("chickns", thing)
+		require.Equal(t, 0.875, node.Confidence())
+
+		node, _ = runParser("egs", thing)
+		require.Equal(t, 0.75, node.Confidence())
+	})
+
+	t.Run("noise between signals", func(t *testing.T) {
+		node, ps := runParser("buy 12 eggs", SignalSeq(noise, qty, thing))
+		require.False(t, ps.Errored())
+		require.Equal(t, 1.0, node.Confidence())
+
+		node, ps = runParser("buy 12 large brown eggs", SignalSeq(noise, qty, thing))
+		require.False(t, ps.Errored())
+		require.Equal(t, 8.0/20, node.Confidence())
+
+		node, ps = runParser("eggs, twelve, 12", SignalSet(noise, qty, thing))
+		require.False(t, ps.Errored())
+		require.Equal(t, 7.0/16, node.Confidence())
+	})
+
+	t.Run("ranks interpretations", func(t *testing.T) {
+		p := SignalSeq(noise, qty, thing)
+		near, _ := runParser("12 egs", p)
+		far, _ := runParser("12 of the best eggs", p)
+		exact, _ := runParser("12 eggs", p)
+		require.Greater(t, exact.Confidence(), near.Confidence())
+		require.Greater(t, near.Confidence(), far.Confidence())
+	})
+
+	t.Run("scored", func(t *testing.T) {
+		plausible := Scored(qty, func(n *Result) float64 {
+			if v, _ := strconv.Atoi(n.Token); v > 1000 {
+				return 0.1
+			}
+			return 1
+		})
+		node, _ := runParser("5000 egs", Seq(plausible, thing))
+		require.InDelta(t, 0.075, node.Confidence(), 1e-9)
+
+		_, ps := runParser("x", plausible)
+		require.True(t, ps.Errored())
+	})
+}
+
+func TestBestExtraction(t *testing.T) {
+	qty := Regex(`\d+`)
+	unit := Regex(`dozen|boxes`)
+	thing := FuzzyAny(2, "eggs", "chickens")
+	noise := Regex(`\S+`)
+	p := BestExtraction(
+		SignalSeq(noise, qty),
+		SignalSeq(noise, qty, thing),
+		SignalSeq(noise, qty, unit, thing),
+	)
+
+	t.Run("most signals", func(t *testing.T) {
+		node, ps := runParser("buy 12 large eggs", p)
+		require.False(t, ps.Errored())
+		assertSequence(t, node, "12", "eggs")
+
+		node, _ = runParser("buy 2 dozen eggs", p)
+		assertSequence(t, node, "2", "dozen", "eggs")
+	})
+
+	t.Run("too much noise", func(t *testing.T) {
+		node, _ := runParser("12, or so I heard, of them lay eggs", p)
+		assertSequence(t, node, "12")
+	})
+
+	t.Run("only some match", func(t *testing.T) {
+		node, ps := runParser("buy 12", p)
+		require.False(t, ps.Errored())
+		assertSequence(t, node, "12")
+	})
+
+	t.Run("none match", func(t *testing.T) {
+		_, ps := runParser("buy eggs", p)
+		require.Equal(t, "offset 8: expected \\d+ or noise", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("keeps the captures of the best pattern only", func(t *testing.T) {
+		best := BestExtraction(
+			SignalSeq(noise, Capture("n", qty)),
+			SignalSeq(noise, qty, Capture("n", unit), thing),
+			SignalSeq(noise, Capture("n", qty)),
+		)
+		_, ps := runParser("buy 2 dozen eggs", best)
+		require.False(t, ps.Errored())
+		require.Equal(t, "dozen", ps.captures.text)
+		require.Nil(t, ps.captures.next)
+	})
+}
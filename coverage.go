@@ -0,0 +1,86 @@
+package goparsify
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// coverageEnabled is set between StartCoverage and StopCoverage, and coverageGen counts the
+// calls to StartCoverage, so rules can tell what they recorded before it from what they did
+// after.
+var coverageEnabled int32
+var coverageGen int64
+
+func (r *namedRule) hit() {
+	if atomic.LoadInt32(&coverageEnabled) == 0 {
+		return
+	}
+	if gen := atomic.LoadInt64(&coverageGen); atomic.LoadInt64(&r.gen) != gen {
+		atomic.StoreInt64(&r.hits, 0)
+		atomic.StoreInt64(&r.gen, gen)
+	}
+	atomic.AddInt64(&r.hits, 1)
+}
+
+// hitsSince returns how many times r matched since the last StartCoverage.
+func (r *namedRule) hitsSince() int64 {
+	if atomic.LoadInt64(&r.gen) != atomic.LoadInt64(&coverageGen) {
+		return 0
+	}
+	return atomic.LoadInt64(&r.hits)
+}
+
+// StartCoverage starts recording which rules created with Named match, clearing anything
+// recorded before. It is meant to be called from TestMain, with WriteCoverage called for the
+// grammar once the tests have run:
+//
+//	func TestMain(m *testing.M) {
+//		goparsify.StartCoverage()
+//		code := m.Run()
+//		goparsify.WriteCoverage(os.Stdout, grammar)
+//		os.Exit(code)
+//	}
+//
+// The locations of the rules that never matched are only known for those created after it or
+// when built with -tags debug, to keep Named fast.
+func StartCoverage() {
+	atomic.AddInt64(&coverageGen, 1)
+	atomic.StoreInt32(&coverageEnabled, 1)
+}
+
+// StopCoverage stops recording rule coverage. What has been recorded so far is kept for WriteCoverage.
+func StopCoverage() {
+	atomic.StoreInt32(&coverageEnabled, 0)
+}
+
+// WriteCoverage writes how many of the rules of the grammar of p created with Named have matched
+// since StartCoverage, followed by the name and location of each rule that never did.
+func WriteCoverage(w io.Writer, p Parserish) error {
+	rules := grammarRules(p)
+	var missed []*namedRule
+	for _, rule := range rules {
+		if rule.hitsSince() == 0 {
+			missed = append(missed, rule)
+		}
+	}
+
+	total := len(rules)
+	percent := 100.0
+	if total > 0 {
+		percent = float64(total-len(missed)) * 100 / float64(total)
+	}
+	if _, err := fmt.Fprintf(w, "grammar coverage: %d of %d rules matched (%.1f%%)\n", total-len(missed), total, percent); err != nil {
+		return err
+	}
+	for _, rule := range missed {
+		location := ""
+		if rule.location != "" {
+			location = " at " + rule.location
+		}
+		if _, err := fmt.Fprintf(w, "  never matched: %s%s\n", rule.name, location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package goparsify
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage(t *testing.T) {
+	StartCoverage()
+	number := Named("coverage number", Chars("0-9"))
+	word := Named("coverage word", Chars("a-z"))
+	unused := Named("coverage unused", Exact("never"))
+	p := Many(Any(number, word, unused))
+
+	_, _, err := Run(p, "12 ab")
+	StopCoverage()
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteCoverage(buf, p))
+	require.Regexp(t, `^grammar coverage: 2 of 3 rules matched \(66.7%\)\n  never matched: coverage unused at coverage_test.go:\d+\n$`, buf.String())
+
+	t.Run("stopped coverage records nothing", func(t *testing.T) {
+		StartCoverage()
+		StopCoverage()
+		_, _, err := Run(p, "12")
+		require.NoError(t, err)
+
+		buf.Reset()
+		require.NoError(t, WriteCoverage(buf, p))
+		require.Contains(t, buf.String(), "grammar coverage: 0 of 3 rules matched")
+		require.Contains(t, buf.String(), "never matched: coverage number")
+	})
+}
@@ -0,0 +1,108 @@
+package goparsify
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVField matches a single RFC 4180 field and returns its unescaped value in .Token. A field
+// is either quoted, in which case it may contain delim, newlines and doubled quotes standing
+// for a literal quote, or unquoted, in which case it runs until delim, a quote or a line break.
+// Empty fields match without consuming input.
+//
+// Whitespace is significant in CSV so CSVField never skips it.
+func CSVField(delim, quote rune) Parser {
+	return NewParser("csv field", csvFieldImpl(delim, quote))
+}
+
+func csvFieldImpl(delim, quote rune) Parser {
+	quoteStr := string(quote)
+	return func(ps *State, node *Result) {
+		input := ps.Get()
+
+		if !strings.HasPrefix(input, quoteStr) {
+			end := 0
+			for end < len(input) {
+				r, w := utf8.DecodeRuneInString(input[end:])
+				if r == delim || r == quote || r == '\n' || r == '\r' {
+					break
+				}
+				end += w
+			}
+			if ps.tokenTooLong(ps.Pos, end) {
+				return
+			}
+			node.Token = input[:end]
+			node.Start, node.End = ps.Pos, ps.Pos+end
+			ps.Advance(end)
+			return
+		}
+
+		var buf strings.Builder
+		pos := len(quoteStr)
+		for {
+			next := strings.Index(input[pos:], quoteStr)
+			if next < 0 {
+				ps.errorInToken(ps.Pos, ps.Pos+len(input), quoteStr)
+				return
+			}
+			if ps.tokenTooLong(ps.Pos, pos+next+len(quoteStr)) {
+				return
+			}
+			buf.WriteString(input[pos : pos+next])
+			pos += next + len(quoteStr)
+			if !strings.HasPrefix(input[pos:], quoteStr) {
+				break
+			}
+			// A doubled quote is an escaped quote.
+			buf.WriteString(quoteStr)
+			pos += len(quoteStr)
+		}
+
+		if pos < len(input) {
+			if r, _ := utf8.DecodeRuneInString(input[pos:]); r != delim && r != '\n' && r != '\r' {
+				ps.errorInToken(ps.Pos, ps.Pos+pos, string(delim))
+				return
+			}
+		}
+
+		node.Token = buf.String()
+		node.Start, node.End = ps.Pos, ps.Pos+pos
+		ps.Advance(pos)
+	}
+}
+
+// CSVRecord matches one or more CSVFields separated by delim. Each field is returned as
+// .Child[n] and their values are bound into .Result as a []string. The line break ending the
+// record is not consumed, so records can be combined with whatever line handling the
+// surrounding grammar uses.
+func CSVRecord(delim, quote rune) Parser {
+	field := csvFieldImpl(delim, quote)
+	delimStr := string(delim)
+
+	return NewParser("csv record", func(ps *State, node *Result) {
+		ps.WS(ps)
+		startpos := ps.Pos
+		node.Child = nil
+		var values []string
+		for {
+			var child Result
+			field(ps, &child)
+			if ps.Errored() {
+				ps.Pos = startpos
+				node.Child = nil
+				return
+			}
+			node.Child = append(node.Child, child)
+			values = append(values, child.Token)
+
+			if !strings.HasPrefix(ps.Get(), delimStr) {
+				break
+			}
+			ps.Advance(len(delimStr))
+		}
+		node.Token = ps.Input[startpos:ps.Pos]
+		node.Start, node.End = startpos, ps.Pos
+		node.Result = values
+	})
+}
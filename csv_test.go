@@ -0,0 +1,72 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVField(t *testing.T) {
+	parser := CSVField(',', '"')
+
+	t.Run("unquoted", func(t *testing.T) {
+		result, p := runParser(" a b ,c", parser)
+		require.Equal(t, " a b ", result.Token)
+		require.Equal(t, ",c", p.Get())
+	})
+
+	t.Run("quoted", func(t *testing.T) {
+		result, p := runParser(`"a, ""b""`+"\nc\",d", parser)
+		require.Equal(t, "a, \"b\"\nc", result.Token)
+		require.Equal(t, ",d", p.Get())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		result, p := runParser(",b", parser)
+		require.False(t, p.Errored())
+		require.Equal(t, "", result.Token)
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		_, p := runParser(`"abc`, parser)
+		require.Equal(t, `offset 4: expected "`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("text after closing quote", func(t *testing.T) {
+		_, p := runParser(`"ab"c,d`, parser)
+		require.Equal(t, `offset 4: expected ,`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestCSVRecord(t *testing.T) {
+	t.Run("rfc 4180", func(t *testing.T) {
+		result, p := runParser("a,\"b,c\",,\"d\"\"\"\r\nnext", CSVRecord(',', '"'))
+		require.Equal(t, []string{"a", "b,c", "", `d"`}, result.Result)
+		assertSequence(t, result, "a", "b,c", "", `d"`)
+		require.Equal(t, "\r\nnext", p.Get())
+	})
+
+	t.Run("custom delimiter and quote", func(t *testing.T) {
+		result, p := runParser("x;'y;z';w", CSVRecord(';', '\''))
+		require.Equal(t, []string{"x", "y;z", "w"}, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("embedded in a grammar", func(t *testing.T) {
+		tags := Seq("tags:", CSVRecord('|', '"')).Map(func(n *Result) {
+			n.Result = n.Child[1].Result
+		})
+		result, _, err := Run(tags, `tags: red|"green|blue"|`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"red", "green|blue", ""}, result)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, p := runParser(`a,"b`, CSVRecord(',', '"'))
+		require.Equal(t, `offset 4: expected "`, p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
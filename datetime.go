@@ -0,0 +1,153 @@
+package goparsify
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DateTime matches a timestamp in any of the given time.Parse layouts and binds it into
+// .Result as a time.Time. Layouts are tried in order and the first that parses wins.
+// If no layouts are given, time.RFC3339 is used (which also accepts fractional seconds).
+func DateTime(layouts ...string) Parser {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	fields, widest := make([]int, len(layouts)), make([]int, len(layouts))
+	for i, layout := range layouts {
+		fields[i] = len(strings.Fields(layout))
+		// Fractional seconds are taken after the seconds even if the layout has none.
+		widest[i] = len(widestTime.Format(layout)) + len(".000000000")
+	}
+
+	return NewParser("date time", func(ps *State, node *Result) {
+		ps.WS(ps)
+		for i, layout := range layouts {
+			candidate := leadingFields(ps.Get(), fields[i])
+			if t, end, ok := parseLeadingTime(layout, candidate, widest[i]); ok {
+				if ps.tokenTooLong(ps.Pos, end) {
+					return
+				}
+				node.Token = candidate[:end]
+				node.Result = t
+				node.Start, node.End = ps.Pos, ps.Pos+end
+				ps.Advance(end)
+				return
+			}
+		}
+		ps.ErrorHere("date time")
+	})
+}
+
+// widestTime is formatted as widely as a time can be by any layout, with the longest month
+// and weekday names, two digit days and hours and the longest zone name and offset.
+var widestTime = time.Date(2021, time.September, 29, 22, 59, 59, 999999999, time.FixedZone("GMT+10", -(11*3600+59*60+59)))
+
+// parseLeadingTime parses the longest prefix of candidate that is a time in layout, returning
+// its length. The candidate may have text glued on after the time, eg a comma, which
+// time.Parse says is extra text after it. Otherwise it is shrunk until it parses, starting at
+// widest bytes, so that a long candidate doesn't take time.Parse a time for each of its bytes.
+func parseLeadingTime(layout, candidate string, widest int) (time.Time, int, bool) {
+	t, err := time.Parse(layout, candidate)
+	if err == nil {
+		return t, len(candidate), true
+	}
+	var perr *time.ParseError
+	if errors.As(err, &perr) && strings.HasPrefix(perr.Message, ": extra text") {
+		end := len(candidate) - len(perr.ValueElem)
+		if t, err := time.Parse(layout, candidate[:end]); err == nil {
+			return t, end, true
+		}
+	}
+	end := len(candidate)
+	if end > widest {
+		end = widest
+	}
+	for ; end > 0; end-- {
+		if end < len(candidate) && !utf8.RuneStart(candidate[end]) {
+			continue
+		}
+		if t, err := time.Parse(layout, candidate[:end]); err == nil {
+			return t, end, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// leadingFields returns the prefix of s containing its first n whitespace separated fields.
+func leadingFields(s string, n int) string {
+	end := 0
+	for ; n > 0; n-- {
+		end = skipRunes(s, end, unicode.IsSpace, true)
+		end = skipRunes(s, end, unicode.IsSpace, false)
+	}
+	return s[:end]
+}
+
+// skipRunes advances pos past every rune for which f returns want.
+func skipRunes(s string, pos int, f func(rune) bool, want bool) int {
+	for pos < len(s) {
+		r, w := utf8.DecodeRuneInString(s[pos:])
+		if f(r) != want {
+			break
+		}
+		pos += w
+	}
+	return pos
+}
+
+var durationUnits = []string{"ns", "us", "µs", "μs", "ms", "s", "m", "h"}
+
+// Duration matches a duration in the format accepted by time.ParseDuration, eg 1h30m or -1.5s,
+// and binds it into .Result as a time.Duration.
+func Duration() Parser {
+	return NewParser("duration", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end := ps.Pos
+		inputLen := len(ps.Input)
+
+		if end < inputLen && (ps.Input[end] == '-' || ps.Input[end] == '+') {
+			end++
+		}
+
+		for end < inputLen {
+			numEnd := end
+			for numEnd < inputLen && (ps.Input[numEnd] >= '0' && ps.Input[numEnd] <= '9' || ps.Input[numEnd] == '.') {
+				numEnd++
+			}
+			if numEnd == end {
+				break
+			}
+			unit := ""
+			for _, u := range durationUnits {
+				if strings.HasPrefix(ps.Input[numEnd:], u) && len(u) > len(unit) {
+					unit = u
+				}
+			}
+			if unit == "" {
+				// A bare 0 is a valid duration on its own.
+				if ps.Input[end:numEnd] == "0" && end == ps.Pos {
+					end = numEnd
+				}
+				break
+			}
+			end = numEnd + len(unit)
+		}
+
+		if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+			return
+		}
+		d, err := time.ParseDuration(ps.Input[ps.Pos:end])
+		if err != nil {
+			ps.ErrorHere("duration")
+			return
+		}
+
+		node.Token = ps.Input[ps.Pos:end]
+		node.Result = d
+		node.Start, node.End = ps.Pos, end
+		ps.Pos = end
+	})
+}
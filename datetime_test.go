@@ -0,0 +1,91 @@
+package goparsify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateTime(t *testing.T) {
+	t.Run("rfc3339 by default", func(t *testing.T) {
+		result, p := runParser("2024-03-01T12:30:00Z rest", DateTime())
+		require.Equal(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), result.Result)
+		require.Equal(t, "2024-03-01T12:30:00Z", result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("fractional seconds and offsets", func(t *testing.T) {
+		result, p := runParser("2024-03-01T12:30:00.5+02:00", DateTime())
+		require.False(t, p.Errored())
+		require.True(t, time.Date(2024, 3, 1, 10, 30, 0, 5e8, time.UTC).Equal(result.Result.(time.Time)))
+	})
+
+	t.Run("trailing punctuation", func(t *testing.T) {
+		result, p := runParser("2024-03-01T12:30:00Z, ok", DateTime())
+		require.Equal(t, "2024-03-01T12:30:00Z", result.Token)
+		require.Equal(t, ", ok", p.Get())
+	})
+
+	t.Run("layouts with spaces are tried in order", func(t *testing.T) {
+		parser := DateTime("2006-01-02", "Jan _2 15:04:05")
+		result, p := runParser("Mar  1 12:30:00 sshd[42]", parser)
+		require.Equal(t, time.Date(0, 3, 1, 12, 30, 0, 0, time.UTC), result.Result)
+		require.Equal(t, " sshd[42]", p.Get())
+
+		result, _ = runParser("2024-03-01", parser)
+		require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), result.Result)
+	})
+
+	t.Run("text glued on that time.Parse doesn't call extra", func(t *testing.T) {
+		result, p := runParser("12:30 PSTX", DateTime("15:04 MST"))
+		require.Equal(t, "12:30 PST", result.Token)
+		require.Equal(t, "X", p.Get())
+
+		result, _ = runParser("Wednesday 29 September 2021, late", DateTime("Monday _2 January 2006"))
+		require.Equal(t, time.Date(2021, 9, 29, 0, 0, 0, 0, time.UTC), result.Result)
+	})
+
+	t.Run("long fractional seconds", func(t *testing.T) {
+		result, p := runParser("2024-03-01T12:30:00.123456789123Z,", DateTime())
+		require.Equal(t, "2024-03-01T12:30:00.123456789123Z", result.Token)
+		require.Equal(t, ",", p.Get())
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("yesterday", DateTime())
+		require.Equal(t, "offset 0: expected date time", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"0":       0,
+		"1h30m":   90 * time.Minute,
+		"-1.5s":   -1500 * time.Millisecond,
+		"250ms":   250 * time.Millisecond,
+		"10µs":    10 * time.Microsecond,
+		"3m20s5h": 5*time.Hour + 3*time.Minute + 20*time.Second,
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, Duration())
+			require.False(t, p.Errored())
+			require.Equal(t, expected, result.Result)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	t.Run("stops after the last unit", func(t *testing.T) {
+		result, p := runParser("5m later", Duration())
+		require.Equal(t, 5*time.Minute, result.Result)
+		require.Equal(t, " later", p.Get())
+	})
+
+	t.Run("missing unit", func(t *testing.T) {
+		_, p := runParser("15", Duration())
+		require.Equal(t, "offset 0: expected duration", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
@@ -13,10 +13,12 @@ import (
 var varRegex = regexp.MustCompile(`(?:var)?\s*(\w*)\s*:?=`)
 
 func getPackageName(f runtime.Frame) string {
-	parts := strings.Split(f.Func.Name(), ".")
+	// Generic functions are named like pkg.Func[...], and the dots in there aren't separators.
+	// f.Func is nil for calls that were inlined, but f.Function is always set.
+	parts := strings.Split(strings.ReplaceAll(f.Function, "[...]", ""), ".")
 	pl := len(parts)
 
-	if pl >= 2 && parts[pl-2][0] == '(' {
+	if pl >= 2 && strings.HasPrefix(parts[pl-2], "(") {
 		return strings.Join(parts[0:pl-2], ".")
 	}
 
@@ -1,6 +1,7 @@
 package debug
 
 import (
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -22,3 +23,14 @@ func TestRegex(t *testing.T) {
 		})
 	}
 }
+
+func callerFrame[T any]() runtime.Frame {
+	pc := make([]uintptr, 1)
+	runtime.Callers(1, pc)
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return frame
+}
+
+func TestGetPackageName(t *testing.T) {
+	require.Equal(t, "github.com/ijt/goparsify/debug", getPackageName(callerFrame[int]()))
+}
@@ -11,11 +11,36 @@ func NewParser(description string, p Parser) Parser {
 	return p
 }
 
+// debugBuild tells whether the package is built with -tags debug.
+const debugBuild = false
+
+func newRuleParser(name string, p Parser) Parser {
+	return p
+}
+
 // DumpDebugStats will print out the curring timings for each parser if built with -tags debug
 func DumpDebugStats() {}
 
-// EnableLogging will write logs to the given writer as the next parse happens
+// DumpProfile writes a table with the number of calls, successes and failures of each parser
+// and the time spent in it if built with -tags debug
+func DumpProfile(w io.Writer) {}
+
+// ResetProfile zeroes the counters reported by DumpProfile if built with -tags debug
+func ResetProfile() {}
+
+// EnableLogging will write logs to the given writer as the next parse happens.
+//
+// Deprecated: it logs every parse in the program, whichever goroutine or test runs it. Pass
+// WithTrace to the calls to Run to log instead.
 func EnableLogging(w io.Writer) {}
 
 // DisableLogging will stop writing logs
+//
+// Deprecated: use WithTrace rather than EnableLogging.
 func DisableLogging() {}
+
+// SetHooks sets the hooks called around every parser if built with -tags debug. Pass nil to remove them.
+//
+// Deprecated: the hooks are called for every parse in the program. Pass WithHooks to the
+// calls to Run to use hooks instead.
+func SetHooks(h Hooks) {}
@@ -4,21 +4,26 @@
 package goparsify
 
 import (
-	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/ijt/goparsify/debug"
 )
 
+// debugBuild tells whether the package is built with -tags debug.
+const debugBuild = true
+
+// debugMu guards the variables below and the counters of every debugParser, as parsers can
+// run on several goroutines at once.
+var debugMu sync.Mutex
 var log io.Writer = nil
+var hooks Hooks = nil
 var parsers []*debugParser
-var pendingOpenLog = ""
-var activeParsers []*debugParser
 var longestLocation = 0
 
 type debugParser struct {
@@ -28,76 +33,63 @@ type debugParser struct {
 	Next       Parser
 	Cumulative time.Duration
 	Self       time.Duration
-	SelfStart  time.Time
 	Calls      int
 	Errors     int
 }
 
-func (dp *debugParser) Name() string {
-	if len(activeParsers) > 1 && activeParsers[len(activeParsers)-2].Var == dp.Var {
-		return dp.Match
+func (dp *debugParser) Parse(ps *State, node *Result) {
+	debugMu.Lock()
+	logTo, h, width := log, hooks, longestLocation
+	debugMu.Unlock()
+	if ps.cfg().hooks != nil {
+		h = ps.cfg().hooks
 	}
-	return dp.Var
-}
-
-func (dp *debugParser) logf(ps *State, result *Result, format string, args ...interface{}) string {
-	buf := &bytes.Buffer{}
-	buf.WriteString(fmt.Sprintf("%"+strconv.Itoa(longestLocation)+"s | ", dp.Location))
-	buf.WriteString(fmt.Sprintf("%-15s", ps.Preview(15)))
-	buf.WriteString(" | ")
-	buf.WriteString(strings.Repeat("  ", len(activeParsers)-1))
-	buf.WriteString(fmt.Sprintf(format, args...))
-	if ps.Errored() {
-		buf.WriteString(fmt.Sprintf(" did not find %s", ps.Error.expected))
-	} else if result != nil {
-		resultStr := strconv.Quote(result.String())
-		if len(resultStr) > 20 {
-			resultStr = resultStr[0:20]
-		}
-		buf.WriteString(fmt.Sprintf(" found %s", resultStr))
+	if ps.trace == nil && logTo != nil {
+		ps.trace = newTracer(logTo)
 	}
-	buf.WriteRune('\n')
-	return buf.String()
-}
+	start := time.Now()
+	startPos := ps.Pos
 
-func (dp *debugParser) logStart(ps *State) {
-	if log != nil {
-		if pendingOpenLog != "" {
-			fmt.Fprint(log, pendingOpenLog)
-			pendingOpenLog = ""
-		}
-		pendingOpenLog = dp.logf(ps, nil, dp.Name()+" {")
+	var name, location string
+	if ps.trace != nil {
+		name = ps.trace.name(dp.Var, dp.Match)
+		location = fmt.Sprintf("%"+strconv.Itoa(width)+"s", dp.Location)
+		ps.trace.enter(ps, location, name, dp.Var)
 	}
-}
-
-func (dp *debugParser) logEnd(ps *State, result *Result) {
-	if log != nil {
-		if pendingOpenLog != "" {
-			fmt.Fprintf(log, dp.logf(ps, result, dp.Name()))
-			pendingOpenLog = ""
-		} else {
-			fmt.Fprintf(log, dp.logf(ps, result, "}"))
-		}
+	if h != nil {
+		h.OnEnter(dp.info(), ps.offset(startPos))
 	}
-}
-
-func (dp *debugParser) Parse(ps *State, node *Result) {
-	activeParsers = append(activeParsers, dp)
-	start := time.Now()
-	dp.SelfStart = start
-
-	dp.logStart(ps)
+	nextStart := time.Now()
 	dp.Next(ps, node)
-	dp.logEnd(ps, node)
+	nextEnd := time.Now()
+	took := time.Since(start)
+	if ps.trace != nil {
+		ps.trace.exit(ps, location, name, startPos, node, took)
+	}
+	if ps.attempts != nil {
+		ps.attempts.record(Attempt{Parser: dp.Match, Var: dp.Var, Start: ps.offset(startPos), End: ps.offset(ps.Pos), OK: !ps.Errored()})
+	}
+	if h != nil {
+		outcome := Outcome{Start: ps.offset(startPos), End: ps.offset(ps.Pos), Result: node, Took: took}
+		if ps.Errored() {
+			err := ps.Error
+			outcome.Error = &err
+		}
+		h.OnExit(dp.info(), outcome)
+	}
 
-	dp.Cumulative += time.Since(start)
-	dp.Self += time.Since(dp.SelfStart)
+	debugMu.Lock()
+	dp.Cumulative += took
+	dp.Self += nextStart.Sub(start) + time.Since(nextEnd)
 	dp.Calls++
 	if ps.Errored() {
 		dp.Errors++
 	}
+	debugMu.Unlock()
+}
 
-	activeParsers = activeParsers[0 : len(activeParsers)-1]
+func (dp *debugParser) info() ParserInfo {
+	return ParserInfo{Name: dp.Match, Var: dp.Var, Location: dp.Location}
 }
 
 // NewParser should be called around the creation of every Parser.
@@ -105,49 +97,92 @@ func (dp *debugParser) Parse(ps *State, node *Result) {
 // it will instrument every parser to collect valuable timing and debug information.
 func NewParser(name string, p Parser) Parser {
 	description, location := debug.GetDefinition()
+	return newDebugParser(name, description, location, p)
+}
+
+// newRuleParser instruments a parser created by Rule, using the rule name as its variable name.
+func newRuleParser(name string, p Parser) Parser {
+	_, location := debug.GetDefinition()
+	return newDebugParser(name, name, location, p)
+}
 
+func newDebugParser(match, varName, location string, p Parser) Parser {
 	dp := &debugParser{
-		Match:    name,
-		Var:      description,
+		Match:    match,
+		Var:      varName,
 		Location: location,
+		Next:     p,
 	}
 
-	dp.Next = func(ps *State, ret *Result) {
-		dp.Self += time.Since(dp.SelfStart)
-
-		p(ps, ret)
-
-		dp.SelfStart = time.Now()
-	}
-
+	debugMu.Lock()
+	defer debugMu.Unlock()
 	if len(dp.Location) > longestLocation {
 		longestLocation = len(dp.Location)
 	}
-
 	parsers = append(parsers, dp)
 	return dp.Parse
 }
 
-// EnableLogging will write logs to the given writer as the next parse happens
+// EnableLogging will write logs to the given writer as the next parse happens.
+//
+// Deprecated: it logs every parse in the program, whichever goroutine or test runs it. Pass
+// WithTrace to the calls to Run to log instead.
 func EnableLogging(w io.Writer) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
 	log = w
 }
 
 // DisableLogging will stop writing logs
+//
+// Deprecated: use WithTrace rather than EnableLogging.
 func DisableLogging() {
+	debugMu.Lock()
+	defer debugMu.Unlock()
 	log = nil
 }
 
+// SetHooks sets the hooks called around every parser. Pass nil to remove them.
+//
+// Deprecated: the hooks are called for every parse in the program. Pass WithHooks to the
+// calls to Run to use hooks instead.
+func SetHooks(h Hooks) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	hooks = h
+}
+
 // DumpDebugStats will print out the curring timings for each parser if built with -tags debug
 func DumpDebugStats() {
-	sort.Slice(parsers, func(i, j int) bool {
-		return parsers[i].Cumulative >= parsers[j].Cumulative
+	fmt.Println()
+	DumpProfile(os.Stdout)
+}
+
+// DumpProfile writes a table with the number of calls, successes and failures of each parser
+// and the time spent in it, slowest first. Self time excludes time spent in child parsers.
+func DumpProfile(w io.Writer) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	sorted := append([]*debugParser(nil), parsers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Cumulative > sorted[j].Cumulative
 	})
 
-	fmt.Println()
-	fmt.Println("|             var name |              matches |      total time |       self time |      calls |     errors | location  ")
-	fmt.Println("| -------------------- | -------------------- | --------------- | --------------- | ---------- | ---------- | ----------")
+	fmt.Fprintln(w, "|             var name |              matches |      total time |       self time |      calls |  successes |   failures | location  ")
+	fmt.Fprintln(w, "| -------------------- | -------------------- | --------------- | --------------- | ---------- | ---------- | ---------- | ----------")
+	for _, parser := range sorted {
+		fmt.Fprintf(w, "| %20s | %20s | %15s | %15s | %10d | %10d | %10d | %s\n", parser.Var, parser.Match, parser.Cumulative.String(), parser.Self.String(), parser.Calls, parser.Calls-parser.Errors, parser.Errors, parser.Location)
+	}
+}
+
+// ResetProfile zeroes the counters reported by DumpProfile, eg to measure a single parse.
+func ResetProfile() {
+	debugMu.Lock()
+	defer debugMu.Unlock()
 	for _, parser := range parsers {
-		fmt.Printf("| %20s | %20s | %15s | %15s | %10d | %10d | %s\n", parser.Var, parser.Match, parser.Cumulative.String(), parser.Self.String(), parser.Calls, parser.Errors, parser.Location)
+		parser.Cumulative = 0
+		parser.Self = 0
+		parser.Calls = 0
+		parser.Errors = 0
 	}
 }
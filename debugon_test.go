@@ -0,0 +1,156 @@
+//go:build debug
+// +build debug
+
+package goparsify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTrace(t *testing.T) {
+	greeting := Seq("hello", "world")
+
+	buf := &bytes.Buffer{}
+	_, _, err := Run(greeting, "hello world", WithTrace(buf))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	// Parsers defined in this package have no variable name, so they are shown by what they match.
+	require.Contains(t, lines[0], "| Seq() {")
+	require.Contains(t, lines[1], `|   hello found "hello" consuming "hello" in `)
+	require.Contains(t, lines[2], `|   world found "world" consuming " world" in `)
+	require.Contains(t, lines[3], `| } Seq() found "[hello,world]" consuming "hello world" in `)
+
+	// A second parse without the option isn't logged.
+	buf.Reset()
+	_, _, err = Run(greeting, "hello world")
+	require.NoError(t, err)
+	require.Equal(t, "", buf.String())
+}
+
+func TestDumpProfile(t *testing.T) {
+	ResetProfile()
+	hello := Exact("hello")
+	greeting := Many(Any(hello, "world"))
+	_, _, err := Run(greeting, "hello world hello")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	DumpProfile(buf)
+	lines := strings.Split(buf.String(), "\n")
+	require.Contains(t, lines[0], "calls |  successes |   failures")
+
+	// hello is tried three times and fails once, on world. Any stops at EOF before trying it again.
+	// Other tests also match hello, but their rows have been reset so they sort after ours.
+	var helloRow string
+	for _, line := range lines {
+		if strings.Contains(line, "|                hello |") {
+			helloRow = line
+			break
+		}
+	}
+	require.Regexp(t, `\|          3 \|          2 \|          1 \|`, helloRow)
+
+	ResetProfile()
+	buf.Reset()
+	DumpProfile(buf)
+	require.NotContains(t, buf.String(), "|          3 |")
+}
+
+type recordingHooks struct {
+	events []string
+}
+
+func (h *recordingHooks) OnEnter(p ParserInfo, pos int) {
+	h.events = append(h.events, fmt.Sprintf("enter %s at %d", p.Name, pos))
+}
+
+func (h *recordingHooks) OnExit(p ParserInfo, o Outcome) {
+	if o.Error != nil {
+		h.events = append(h.events, fmt.Sprintf("exit %s failed: %s", p.Name, o.Error))
+		return
+	}
+	h.events = append(h.events, fmt.Sprintf("exit %s %d..%d %q", p.Name, o.Start, o.End, o.Result.Token))
+}
+
+func TestSetHooks(t *testing.T) {
+	h := &recordingHooks{}
+	SetHooks(h)
+	defer SetHooks(nil)
+
+	_, _, err := Run(Seq("hello", Maybe("there"), "world"), "hello world")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"enter Seq() at 0",
+		"enter hello at 0",
+		`exit hello 0..5 "hello"`,
+		"enter Maybe() at 5",
+		"enter there at 5",
+		"exit there failed: offset 6: expected there",
+		// Failed parsers still skip the whitespace in front of them.
+		`exit Maybe() 5..6 ""`,
+		"enter world at 6",
+		`exit world 6..11 "world"`,
+		`exit Seq() 0..11 "hello world"`,
+	}, h.events)
+
+	SetHooks(nil)
+	h.events = nil
+	_, _, err = Run(Exact("hello"), "hello")
+	require.NoError(t, err)
+	require.Empty(t, h.events)
+}
+
+func TestWithHooks(t *testing.T) {
+	h := &recordingHooks{}
+	_, _, err := Run(Seq("hello", "world"), "hello world", WithHooks(h))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"enter Seq() at 0",
+		"enter hello at 0",
+		`exit hello 0..5 "hello"`,
+		"enter world at 5",
+		`exit world 5..11 "world"`,
+		`exit Seq() 0..11 "hello world"`,
+	}, h.events)
+
+	// Other parses don't call them.
+	h.events = nil
+	_, _, err = Run(Exact("hello"), "hello")
+	require.NoError(t, err)
+	require.Empty(t, h.events)
+}
+
+func TestWithAttempts(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, _, err := Run(Any("hi", "hello"), "hello", WithAttempts(buf))
+	require.NoError(t, err)
+
+	attempts, err := ReadAttempts(buf)
+	require.NoError(t, err)
+	require.Equal(t, []Attempt{
+		{Parser: "hi", Start: 0, End: 0, OK: false},
+		{Parser: "hello", Start: 0, End: 5, OK: true},
+		{Parser: "Any()", Start: 0, End: 5, OK: true},
+	}, attempts)
+}
+
+func TestRuleNamesParsers(t *testing.T) {
+	greeting := Rule("greeting", Seq("hello", "world"))
+
+	buf := &bytes.Buffer{}
+	_, _, err := Run(greeting, "hello world", WithTrace(buf))
+	require.NoError(t, err)
+	require.Contains(t, strings.Split(buf.String(), "\n")[0], "| greeting {")
+	require.Contains(t, buf.String(), `} greeting found "[hello,world]"`)
+
+	profile := &bytes.Buffer{}
+	DumpProfile(profile)
+	require.Contains(t, profile.String(), "|             greeting |             greeting |")
+}
@@ -0,0 +1,141 @@
+package goparsify
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeKind is the kind of difference between two trees reported by DiffResults.
+type ChangeKind int
+
+const (
+	// Added is a result only in the new tree.
+	Added ChangeKind = iota
+	// Removed is a result only in the old tree.
+	Removed
+	// Changed is a result in both trees with a different name, or a leaf with a different
+	// token or result.
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a difference between two trees reported by DiffResults.
+type Change struct {
+	Kind ChangeKind
+	// Old is the result in the old tree, nil when it was Added. New is the result in the new
+	// tree, nil when it was Removed.
+	Old, New *Result
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return "added " + c.New.summary()
+	case Removed:
+		return "removed " + c.Old.summary()
+	}
+	return "changed " + c.Old.summary() + " to " + c.New.summary()
+}
+
+// DiffResults compares the trees under a and b, eg parses of a corpus before and after a
+// grammar change, and returns what it would take to turn a into b. Children are matched up by
+// name and token so an insertion doesn't show up as a change to everything after it, and a
+// result that was added or removed is reported once for its whole subtree. Spans aren't
+// compared, as editing the input moves everything after the edit, but are shown by
+// Change.String to find the results in the input.
+func DiffResults(a, b *Result) []Change {
+	var changes []Change
+	diffResults(a, b, &changes)
+	return changes
+}
+
+func diffResults(a, b *Result, changes *[]Change) {
+	if !sameResult(a, b) {
+		*changes = append(*changes, Change{Kind: Changed, Old: a, New: b})
+	}
+	diffChildren(a.Child, b.Child, changes)
+}
+
+// sameResult compares the results without their children. The tokens and results of parents
+// are made from their children so only leaves are compared on them.
+func sameResult(a, b *Result) bool {
+	if a.Name != b.Name || a.Kind != b.Kind {
+		return false
+	}
+	if len(a.Child) > 0 && len(b.Child) > 0 {
+		return true
+	}
+	return a.Token == b.Token && reflect.DeepEqual(a.Result, b.Result)
+}
+
+func diffKey(r *Result) string {
+	if len(r.Child) > 0 {
+		return r.Name + "\x00"
+	}
+	return r.Name + "\x00" + r.Token
+}
+
+// diffChildren matches up the longest common subsequence of children with the same key and
+// diffs the children in between position by position.
+func diffChildren(as, bs []Result, changes *[]Change) {
+	// lcs[i][j] is the length of the longest common subsequence of as[i:] and bs[j:].
+	lcs := make([][]int, len(as)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bs)+1)
+	}
+	for i := len(as) - 1; i >= 0; i-- {
+		for j := len(bs) - 1; j >= 0; j-- {
+			switch {
+			case diffKey(&as[i]) == diffKey(&bs[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	gapA, gapB := 0, 0
+	flush := func() {
+		for gapA < i && gapB < j {
+			diffResults(&as[gapA], &bs[gapB], changes)
+			gapA++
+			gapB++
+		}
+		for ; gapA < i; gapA++ {
+			*changes = append(*changes, Change{Kind: Removed, Old: &as[gapA]})
+		}
+		for ; gapB < j; gapB++ {
+			*changes = append(*changes, Change{Kind: Added, New: &bs[gapB]})
+		}
+	}
+	for i < len(as) && j < len(bs) {
+		switch {
+		case diffKey(&as[i]) == diffKey(&bs[j]):
+			flush()
+			diffResults(&as[i], &bs[j], changes)
+			i++
+			j++
+			gapA, gapB = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	i, j = len(as), len(bs)
+	flush()
+}
@@ -0,0 +1,53 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffResults(t *testing.T) {
+	list := Named("list", Some(Named("number", Chars("0-9")), ","))
+	parse := func(input string) *Result {
+		result, ps := runParser(input, list)
+		require.False(t, ps.Errored())
+		return &result
+	}
+	diff := func(a, b string) []string {
+		var changes []string
+		for _, change := range DiffResults(parse(a), parse(b)) {
+			changes = append(changes, change.String())
+		}
+		return changes
+	}
+
+	t.Run("same trees", func(t *testing.T) {
+		require.Empty(t, diff("1,2,3", "1,2,3"))
+	})
+
+	t.Run("spans are ignored", func(t *testing.T) {
+		require.Empty(t, diff("1,2,3", "1, 2,   3"))
+	})
+
+	t.Run("added", func(t *testing.T) {
+		require.Equal(t, []string{`added number 2..3 "9"`}, diff("1,2,3", "1,9,2,3"))
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		require.Equal(t, []string{`removed number 4..5 "3"`}, diff("1,2,3", "1,2"))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		require.Equal(t, []string{`changed number 2..3 "2" to number 2..4 "22"`}, diff("1,2,3", "1,22,3"))
+	})
+
+	t.Run("names", func(t *testing.T) {
+		a := &Result{Name: "a", Child: []Result{{Token: "x"}}}
+		b := &Result{Name: "b", Child: []Result{{Token: "x"}}}
+		changes := DiffResults(a, b)
+		require.Len(t, changes, 1)
+		require.Equal(t, Changed, changes[0].Kind)
+		require.Equal(t, a, changes[0].Old)
+		require.Equal(t, b, changes[0].New)
+	})
+}
@@ -0,0 +1,68 @@
+package goparsify
+
+// RunDynamic parses input like RunTree and returns the tree as Dynamic makes it, eg to try out
+// a grammar before writing the types and Maps to build an AST with.
+func RunDynamic(parser Parserish, input string, opts ...Option) (interface{}, error) {
+	tree, err := RunTree(parser, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Dynamic(&tree, input), nil
+}
+
+// Dynamic turns the tree under root into maps, slices and values like the ones encoding/json
+// decodes into, from the names of its rules alone. A result with named results under it becomes
+// a map[string]interface{} holding each of them under its name, or a []interface{} of them if
+// the name is there more than once, eg for a rule matched by Many:
+//
+//	pair := Named("pair", Seq(Named("key", Chars("a-z")), "=", Named("value", NumberLit())))
+//	RunDynamic(Many(pair, ","), "a=1,b=2")
+//	// {"pair": [{"key": "a", "value": 1}, {"key": "b", "value": 2}]}
+//
+// Results without a name are looked through, so only the named ones make the structure, see
+// Select. A result with no named results under it becomes its .Result if it has one, its
+// .Token if it has no children, or else the text of input it matched. input is the input the
+// tree was parsed from.
+func Dynamic(root *Result, input string) interface{} {
+	if root.Result != nil {
+		return root.Result
+	}
+	named := namedUnder(root, nil)
+	if len(named) == 0 {
+		if len(root.Child) == 0 || root.Start < 0 || root.Start > root.End || root.End > len(input) {
+			return root.Token
+		}
+		return input[root.Start:root.End]
+	}
+
+	m := map[string]interface{}{}
+	repeated := map[string]bool{}
+	for _, n := range named {
+		value := Dynamic(n, input)
+		existing, ok := m[n.Name]
+		switch {
+		case !ok:
+			m[n.Name] = value
+		case repeated[n.Name]:
+			m[n.Name] = append(existing.([]interface{}), value)
+		default:
+			m[n.Name] = []interface{}{existing, value}
+			repeated[n.Name] = true
+		}
+	}
+	return m
+}
+
+// namedUnder appends the named results right under r to named, looking through those without
+// a name.
+func namedUnder(r *Result, named []*Result) []*Result {
+	for i := range r.Child {
+		child := &r.Child[i]
+		if child.Name != "" {
+			named = append(named, child)
+			continue
+		}
+		named = namedUnder(child, named)
+	}
+	return named
+}
@@ -0,0 +1,54 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamic(t *testing.T) {
+	pair := Named("pair", Seq(Named("key", Chars("a-z")), "=", Named("value", NumberLit())))
+
+	t.Run("makes maps and slices", func(t *testing.T) {
+		value, err := RunDynamic(Many(pair, ","), "a=1, b=2.5")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"pair": []interface{}{
+				map[string]interface{}{"key": "a", "value": int64(1)},
+				map[string]interface{}{"key": "b", "value": 2.5},
+			},
+		}, value)
+
+		value, err = RunDynamic(Many(pair, ","), "a=1")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"pair": map[string]interface{}{"key": "a", "value": int64(1)},
+		}, value)
+	})
+
+	t.Run("takes the text of rules without names under them", func(t *testing.T) {
+		call := Seq(Named("name", Chars("a-z")), "(", Named("args", Seq(Chars("0-9"), Many(Seq(",", Chars("0-9"))))), ")")
+		value, err := RunDynamic(call, "f(1, 2,3)")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"name": "f", "args": "1, 2,3"}, value)
+	})
+
+	t.Run("keeps results", func(t *testing.T) {
+		sum := Named("sum", Seq(Chars("0-9"), "+", Chars("0-9")).Map(func(n *Result) {
+			n.Result = "a sum"
+		}))
+		value, err := RunDynamic(Seq(sum, Named("keyword", Insensitive("end"))), "1+2 END")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"sum": "a sum", "keyword": "end"}, value)
+
+		value, err = RunDynamic(Chars("a-z"), "abc")
+		require.NoError(t, err)
+		require.Equal(t, "abc", value)
+	})
+
+	t.Run("fails like RunTree", func(t *testing.T) {
+		value, err := RunDynamic(pair, "a=")
+		require.Error(t, err)
+		require.Nil(t, value)
+	})
+}
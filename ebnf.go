@@ -0,0 +1,251 @@
+package goparsify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExportEBNF writes the grammar of p in ISO 14977 EBNF, so it can be documented, reviewed in
+// diffs and compared against a reference specification.
+//
+// Every parser given a name with Named or Rule becomes a rule of its own, and recursive
+// combinators without a name are given one. Exact becomes a terminal string and the other
+// terminals in this package are written as special sequences like ? [0-9]+ ?. Parsers written
+// by hand can't be looked into and are written as ? custom parser ?.
+func ExportEBNF(p Parserish) string {
+	parser := Parsify(p)
+	w := &ebnfWriter{
+		names:     map[*grammarRule]string{},
+		used:      map[string]bool{},
+		recursive: map[*grammarRule]bool{},
+	}
+	ps := NewState("")
+	ps.analysis = w
+
+	// The first pass finds the combinators without a name that refer back to themselves,
+	// which need a rule of their own to be written down.
+	w.walk(ps, parser)
+	for _, rule := range w.recursiveOrder {
+		w.names[rule] = w.unique("rule")
+	}
+	root := w.walk(ps, parser)
+	if len(w.rules) == 0 || w.rules[0].name != root.text {
+		w.rules = append([]ebnfRule{{name: w.unique("grammar"), body: root.text}}, w.rules...)
+	}
+
+	var b strings.Builder
+	for _, rule := range w.rules {
+		fmt.Fprintf(&b, "%s = %s ;\n", rule.name, rule.body)
+	}
+	return b.String()
+}
+
+// Precedences of EBNF expressions, used to decide where parentheses are needed.
+const (
+	ebnfAlternation = iota
+	ebnfConcatenation
+	ebnfAtom
+)
+
+type ebnfExpr struct {
+	text string
+	prec int
+}
+
+type ebnfRule struct {
+	name string
+	body string
+}
+
+// ebnfWriter renders a grammar as it is walked. Each combinator pushes its expression onto out
+// for its parent to pick up.
+type ebnfWriter struct {
+	names          map[*grammarRule]string
+	used           map[string]bool
+	recursive      map[*grammarRule]bool
+	recursiveOrder []*grammarRule
+
+	stack   []*grammarRule
+	defined map[*grammarRule]bool
+	rules   []ebnfRule
+	out     []ebnfExpr
+}
+
+func (w *ebnfWriter) walk(ps *State, p Parser) ebnfExpr {
+	w.stack = nil
+	w.defined = map[*grammarRule]bool{}
+	w.rules = nil
+	w.out = nil
+	return w.render(ps, p)
+}
+
+// render runs p and returns the expression it pushed.
+func (w *ebnfWriter) render(ps *State, p Parser) ebnfExpr {
+	n := len(w.out)
+	p(ps, &Result{})
+	ps.Recover()
+	if len(w.out) == n {
+		return ebnfExpr{text: "? custom parser ?", prec: ebnfAtom}
+	}
+	e := w.out[n]
+	w.out = w.out[:n]
+	return e
+}
+
+// node pushes the expression for rule, or a reference to it if it has a name.
+func (w *ebnfWriter) node(rule *grammarRule, render func() ebnfExpr) {
+	name, named := w.names[rule]
+	for _, r := range w.stack {
+		if r != rule {
+			continue
+		}
+		if !named && !w.recursive[rule] {
+			w.recursive[rule] = true
+			w.recursiveOrder = append(w.recursiveOrder, rule)
+		}
+		w.out = append(w.out, ebnfExpr{text: name, prec: ebnfAtom})
+		return
+	}
+	if named && w.defined[rule] {
+		w.out = append(w.out, ebnfExpr{text: name, prec: ebnfAtom})
+		return
+	}
+
+	i := len(w.rules)
+	if named {
+		w.defined[rule] = true
+		w.rules = append(w.rules, ebnfRule{name: name})
+	}
+	w.stack = append(w.stack, rule)
+	e := render()
+	w.stack = w.stack[:len(w.stack)-1]
+	if named {
+		w.rules[i].body = e.text
+		e = ebnfExpr{text: name, prec: ebnfAtom}
+	}
+	w.out = append(w.out, e)
+}
+
+// unique returns name, or name with a number after it if another rule already has it.
+func (w *ebnfWriter) unique(name string) string {
+	candidate := name
+	for i := 2; w.used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	w.used[candidate] = true
+	return candidate
+}
+
+func (w *ebnfWriter) seq(ps *State, rule *grammarRule, parsers []Parser) {
+	w.node(rule, func() ebnfExpr {
+		var parts []ebnfExpr
+		for _, p := range parsers {
+			if e := w.render(ps, p); e.text != "" {
+				parts = append(parts, e)
+			}
+		}
+		return ebnfJoin(parts, " , ", ebnfConcatenation)
+	})
+}
+
+func (w *ebnfWriter) any(ps *State, rule *grammarRule, parsers []Parser) {
+	w.node(rule, func() ebnfExpr {
+		var parts []ebnfExpr
+		for _, p := range parsers {
+			parts = append(parts, w.render(ps, p))
+		}
+		return ebnfJoin(parts, " | ", ebnfAlternation)
+	})
+}
+
+func (w *ebnfWriter) many(ps *State, rule *grammarRule, min int, op, sep Parser) {
+	w.node(rule, func() ebnfExpr {
+		item := w.render(ps, op)
+		if sep == nil {
+			if min == 0 {
+				return ebnfExpr{text: "{ " + item.text + " }", prec: ebnfAtom}
+			}
+			return ebnfJoin([]ebnfExpr{item, {text: "{ " + item.text + " }", prec: ebnfAtom}}, " , ", ebnfConcatenation)
+		}
+
+		rest := ebnfJoin([]ebnfExpr{w.render(ps, sep), item}, " , ", ebnfConcatenation)
+		e := ebnfJoin([]ebnfExpr{item, {text: "{ " + rest.text + " }", prec: ebnfAtom}}, " , ", ebnfConcatenation)
+		if min == 0 {
+			return ebnfExpr{text: "[ " + e.text + " ]", prec: ebnfAtom}
+		}
+		return e
+	})
+}
+
+func (w *ebnfWriter) signalSeq(ps *State, rule *grammarRule, noise Parser, signals []Parser) {
+	w.node(rule, func() ebnfExpr {
+		skip := ebnfExpr{text: "{ " + w.render(ps, noise).text + " }", prec: ebnfAtom}
+		var parts []ebnfExpr
+		for _, p := range signals {
+			parts = append(parts, skip, w.render(ps, p))
+		}
+		e := ebnfJoin(parts, " , ", ebnfConcatenation)
+		if rule.kind == "SignalSet()" {
+			// EBNF has no way to say the signals can come in any order.
+			e.text = "(* in any order *) " + e.text
+		}
+		return e
+	})
+}
+
+func (w *ebnfWriter) wrap(ps *State, rule *grammarRule, p Parser, nullable bool) {
+	if _, ok := w.names[rule]; !ok && rule.name != "" {
+		w.names[rule] = w.unique(rule.name)
+	}
+	w.node(rule, func() ebnfExpr {
+		e := w.render(ps, p)
+		if nullable {
+			return ebnfExpr{text: "[ " + e.text + " ]", prec: ebnfAtom}
+		}
+		return e
+	})
+}
+
+func (w *ebnfWriter) exact(match string) {
+	text := `"` + match + `"`
+	switch {
+	case strings.Contains(match, `"`) && !strings.Contains(match, "'"):
+		text = "'" + match + "'"
+	case strings.Contains(match, `"`):
+		text = "? " + strconv.Quote(match) + " ?"
+	}
+	w.out = append(w.out, ebnfExpr{text: text, prec: ebnfAtom})
+}
+
+func (w *ebnfWriter) chars(description string, first func(b byte) bool, nullable bool) {
+	w.terminal(description)
+}
+
+func (w *ebnfWriter) terminal(description string) {
+	if description == "" {
+		w.out = append(w.out, ebnfExpr{prec: ebnfAtom})
+		return
+	}
+	w.out = append(w.out, ebnfExpr{text: "? " + description + " ?", prec: ebnfAtom})
+}
+
+// ebnfJoin joins parts with the operator sep of precedence prec, parenthesizing the parts
+// that bind more loosely.
+func ebnfJoin(parts []ebnfExpr, sep string, prec int) ebnfExpr {
+	switch len(parts) {
+	case 0:
+		return ebnfExpr{prec: ebnfAtom}
+	case 1:
+		return parts[0]
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.prec < prec {
+			texts = append(texts, "( "+part.text+" )")
+		} else {
+			texts = append(texts, part.text)
+		}
+	}
+	return ebnfExpr{text: strings.Join(texts, sep), prec: prec}
+}
@@ -0,0 +1,54 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportEBNF(t *testing.T) {
+	t.Run("named rules", func(t *testing.T) {
+		var value Parser
+		array := Named("array", Seq("[", Cut(), Many(&value, ","), "]"))
+		value = Named("value", Any(NumberLit(), StringLit(`"`), array))
+
+		require.Equal(t, `value = ? number literal ? | ? string literal ? | array ;
+array = "[" , [ value , { "," , value } ] , "]" ;
+`, ExportEBNF(value))
+	})
+
+	t.Run("unnamed root", func(t *testing.T) {
+		require.Equal(t, `grammar = ( "a" | "b" ) , { "," , ( "a" | "b" ) } , [ ? [0-9]+ ? ] ;
+`, ExportEBNF(Seq(Some(Any("a", "b"), ","), Maybe(Chars("0-9")))))
+	})
+
+	t.Run("unnamed recursion", func(t *testing.T) {
+		var group Parser
+		group = Seq("(", Maybe(&group), ")")
+
+		require.Equal(t, `rule = "(" , [ rule ] , ")" ;
+`, ExportEBNF(group))
+	})
+
+	t.Run("terminals", func(t *testing.T) {
+		require.Equal(t, `grammar = '"' , ? /[a-z]+/ ? , ? "select" in any case ? , ? [^x]{2,3} ? , ? anything until "*/" ? , { "x" } , x , ? custom parser ? ;
+x = "x" , { "x" } ;
+`, ExportEBNF(Seq(
+			`"`,
+			Regex("[a-z]+"),
+			Insensitive("select"),
+			NotChars("x", 2, 3),
+			Until("*/"),
+			Many("x"),
+			Named("x", Some("x")),
+			func(ps *State, node *Result) {},
+		)))
+	})
+
+	t.Run("rule names are unique", func(t *testing.T) {
+		require.Equal(t, `grammar = item , item2 ;
+item = "a" ;
+item2 = "b" ;
+`, ExportEBNF(Seq(Named("item", "a"), Named("item", "b"))))
+	})
+}
@@ -0,0 +1,43 @@
+package goparsify
+
+// Embed runs p with its own settings, given as options, and then goes back to those of the
+// parsers around it. That lets an island grammar, like the SQL in a string or a regex literal,
+// skip its own whitespace and comments inside a host grammar that skips others:
+//
+//	query := Seq("sql`", Embed(sql, WithWhitespace(sqlWhitespace)), "`")
+//
+// The options that can be given are WithWhitespace, WithLineContinuation, WithLongestMatch,
+// WithPruneEmpty, WithResolver, WithMaxDepth, WithMaxTokenLength and WithMaxChildren. Those not
+// given are taken from around Embed, so the line continuations skipped around it are only
+// dropped by a WithWhitespace. Options about the whole run, like those about the input, are
+// ignored.
+func Embed(p Parserish, opts ...Option) Parser {
+	parser := Parsify(p)
+	// Embed can skip whitespace of its own that the parser around it doesn't.
+	rule := &grammarRule{kind: "Embed()", withoutChild: true}
+
+	return NewParser("Embed()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parser, false)
+			return
+		}
+		outer, outerWS := ps.config, ps.WS
+		given := *ps.cfg()
+		given.ws, given.continuations = ps.WS, nil
+		for _, opt := range opts {
+			opt(&given)
+		}
+		// Only the options Embed takes are used, the others stay those of the run.
+		cfg := *ps.cfg()
+		cfg.longest, cfg.prune, cfg.resolver = given.longest, given.prune, given.resolver
+		cfg.maxDepth, cfg.maxToken, cfg.maxChildren = given.maxDepth, given.maxToken, given.maxChildren
+		ps.WS = given.ws
+		if len(given.continuations) > 0 {
+			ps.WS = skipContinuations(ps.WS, given.continuations)
+		}
+		ps.config = &cfg
+
+		parser(ps, node)
+		ps.config, ps.WS = outer, outerWS
+	})
+}
@@ -0,0 +1,65 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbed(t *testing.T) {
+	// sqlWhitespace skips -- comments along with the whitespace.
+	sqlWhitespace := func(s *State) {
+		for {
+			ASCIIWhitespace(s)
+			if !strings.HasPrefix(s.Get(), "--") {
+				return
+			}
+			if i := strings.IndexByte(s.Get(), '\n'); i >= 0 {
+				s.Advance(i)
+			} else {
+				s.Pos = len(s.Input)
+			}
+		}
+	}
+	sql := Some(Chars("a-z"))
+	query := Seq("sql", "{", Embed(sql, WithWhitespace(sqlWhitespace)), "}")
+
+	t.Run("uses its own whitespace", func(t *testing.T) {
+		node, ps := runParser("sql { select -- all of it\n x }", query)
+		require.False(t, ps.Errored())
+		assertSequence(t, node.Child[2], "select", "x")
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("leaves the whitespace around it alone", func(t *testing.T) {
+		_, ps := runParser("sql -- no comments here\n{ x }", query)
+		require.Equal(t, "offset 4: expected {", ps.Error.Error())
+	})
+
+	t.Run("goes back to the outer settings", func(t *testing.T) {
+		glued := Seq(Embed("a", WithWhitespace(NoWhitespace)), "b")
+		_, ps := runParser("a b", glued)
+		require.False(t, ps.Errored())
+
+		_, ps = runParser(" a", glued)
+		require.Equal(t, "offset 0: expected a", ps.Error.Error())
+
+		_, ps = runParser("x a", Seq("x", Embed("a", WithWhitespace(NoWhitespace))))
+		require.Equal(t, "offset 1: expected a", ps.Error.Error())
+
+		_, ps = runParser(" b", Seq(Maybe(Embed("a", WithWhitespace(NoWhitespace))), "b"))
+		require.False(t, ps.Errored())
+	})
+
+	t.Run("takes other settings", func(t *testing.T) {
+		words := Seq(Embed(Chars("a-z"), WithMaxTokenLength(3)), Chars("a-z"))
+		_, _, err := Run(words, "abc defgh")
+		require.NoError(t, err)
+		_, _, err = Run(words, "abcd efg")
+		require.EqualError(t, err, "offset 0: expected token of at most 3 bytes")
+
+		_, _, err = Run(Embed(Any("a", "ab"), WithLongestMatch()), "ab")
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package goparsify
+
+// maxEncodedRune is the most bytes decodeInput gives the decoder to decode something from.
+const maxEncodedRune = 16
+
+// Decoder decodes input from some character encoding into UTF-8, see WithEncoding. It has the
+// methods of a golang.org/x/text/transform.Transformer, so the decoders of the encodings in
+// golang.org/x/text/encoding, eg charmap.ISO8859_1.NewDecoder() or japanese.ShiftJIS.NewDecoder(),
+// can be passed as they are.
+type Decoder interface {
+	Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error)
+	Reset()
+}
+
+// WithEncoding parses input written in another character encoding than UTF-8, eg Latin-1,
+// UTF-16 or Shift-JIS, by decoding it with decoder first. The literals of the grammar are in
+// UTF-8 as usual. Tokens are decoded but errors and the spans of the Results are at byte
+// offsets into the input as it was given, so they point at the bytes of the original file.
+// Input the decoder fails on fails the parse where it is, before any parser is run.
+//
+// A Decoder keeps state as it goes, so parses running at the same time each need their own.
+func WithEncoding(decoder Decoder) Option {
+	return func(cfg *runConfig) {
+		cfg.decoder = decoder
+	}
+}
+
+// decodeInput decodes input with d as little at a time as d lets it, so each piece of the
+// output is mapped back to the bytes it was decoded from.
+func decodeInput(input string, d Decoder) (string, *offsetMap, error) {
+	d.Reset()
+	m := &offsetMap{}
+	src := []byte(input)
+	var out []byte
+	var dst [4 * maxEncodedRune]byte
+	for pos := 0; pos < len(src); {
+		consumed := 0
+		for n := 1; n <= maxEncodedRune && pos+n <= len(src); n++ {
+			nDst, nSrc, _ := d.Transform(dst[:], src[pos:pos+n], pos+n == len(src))
+			if nSrc == 0 {
+				continue
+			}
+			m.normalized = append(m.normalized, len(out))
+			m.original = append(m.original, pos)
+			out = append(out, dst[:nDst]...)
+			consumed = nSrc
+			break
+		}
+		if consumed == 0 {
+			return "", nil, &Error{pos: pos, expected: "valid encoding"}
+		}
+		pos += consumed
+	}
+	m.normalized = append(m.normalized, len(out))
+	m.original = append(m.original, len(input))
+	return string(out), m, nil
+}
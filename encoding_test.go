@@ -0,0 +1,101 @@
+package goparsify
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+// latin1 decodes ISO 8859-1 the way charmap.ISO8859_1.NewDecoder() does.
+type latin1 struct{}
+
+func (latin1) Reset() {}
+
+func (latin1) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for _, b := range src {
+		if nDst+utf8.RuneLen(rune(b)) > len(dst) {
+			return nDst, nSrc, errors.New("short dst")
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], rune(b))
+		nSrc++
+	}
+	return nDst, nSrc, nil
+}
+
+// utf16LE decodes little endian UTF-16, failing on unpaired surrogates.
+type utf16LE struct{}
+
+func (utf16LE) Reset() {}
+
+func (utf16LE) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc+2 <= len(src) {
+		r, n := rune(src[nSrc])|rune(src[nSrc+1])<<8, 2
+		if utf16.IsSurrogate(r) {
+			if nSrc+4 > len(src) {
+				break
+			}
+			r = utf16.DecodeRune(r, rune(src[nSrc+2])|rune(src[nSrc+3])<<8)
+			if r == utf8.RuneError {
+				return nDst, nSrc, errors.New("unpaired surrogate")
+			}
+			n = 4
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += n
+	}
+	if nSrc < len(src) {
+		return nDst, nSrc, errors.New("short src")
+	}
+	return nDst, nSrc, nil
+}
+
+func TestWithEncoding(t *testing.T) {
+	word := Named("word", Chars(`\pL`))
+	words := Many(word)
+
+	t.Run("latin-1", func(t *testing.T) {
+		tree, err := RunTree(words, "caf\xe9 cr\xe8me", WithEncoding(latin1{}))
+		require.NoError(t, err)
+		require.Equal(t, "café", tree.Child[0].Token)
+		require.Equal(t, "crème", tree.Child[1].Token)
+		require.Equal(t, 5, tree.Child[1].Start)
+		require.Equal(t, 10, tree.Child[1].End)
+
+		_, err = RunTree(words, "caf\xe9 1", WithEncoding(latin1{}))
+		require.EqualError(t, err, "left unparsed: 1")
+
+		_, err = RunTree(Seq(word, word), "caf\xe9 1", WithEncoding(latin1{}))
+		require.EqualError(t, err, "offset 5: expected \\pL")
+	})
+
+	t.Run("utf-16", func(t *testing.T) {
+		input := "h\x00i\x00 \x00=\xd8\x00\xde"
+		tree, err := RunTree(Seq(word, "😀"), input, WithEncoding(utf16LE{}))
+		require.NoError(t, err)
+		require.Equal(t, "hi", tree.Child[0].Token)
+		require.Equal(t, 6, tree.Child[1].Start)
+		require.Equal(t, 10, tree.Child[1].End)
+	})
+
+	t.Run("undecodable input", func(t *testing.T) {
+		_, err := RunTree(words, "h\x00i\x00\x00\xd8x\x00", WithEncoding(utf16LE{}))
+		require.EqualError(t, err, "offset 4: expected valid encoding")
+	})
+
+	t.Run("with normalization", func(t *testing.T) {
+		compose := func(s string) string {
+			if s == "e\u0301" {
+				return "\u00e9"
+			}
+			return s
+		}
+		tree, err := RunTree(Seq("\u00e9", word), "e\x00\x01\x03x\x00", WithEncoding(utf16LE{}), WithNormalization(compose))
+		require.NoError(t, err)
+		require.Equal(t, 0, tree.Child[0].Start)
+		require.Equal(t, 4, tree.Child[1].Start)
+		require.Equal(t, 6, tree.Child[1].End)
+	})
+}
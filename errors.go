@@ -1,23 +1,123 @@
 package goparsify
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnexpectedEOF is matched by errors.Is for the errors returned when the input ended where
+// more was expected, eg in the middle of a string, so a REPL can tell when to ask for another
+// line instead of reporting the error.
+var ErrUnexpectedEOF = errors.New("unexpected end of input")
 
 // Error represents a parse error. These will often be set, the parser will back up a little and
 // find another viable path. In general when combining errors the longest error should be returned.
 type Error struct {
 	pos      int
 	expected string
+	// message is set by ErrorHereWithMessage to say what is wrong in place of what's expected.
+	message string
+	// rule is the name of the innermost Rule the error was found in, if any.
+	rule string
+	// tokenStart is where the token starts when pos is in the middle of one, which inToken is
+	// set for.
+	tokenStart int
+	inToken    bool
+	// eof is set by Run when the error is at the end of the input, and incomplete when it or
+	// one recovered from on the way is, see IsIncomplete.
+	eof, incomplete bool
+	// indexed is set when text holds the text the error was found in, at the offset at into
+	// it, for Position, and lines indexes it once Position is first called.
+	indexed bool
+	// sources is the chain of sources the error is in when it is in an included one, the
+	// outermost first and the one holding pos last.
+	sources []Source
+	text    string
+	at      int
+	lines   *LineIndex
 }
 
-// Pos is the offset into the document the error was found
+// Pos is the offset into the document the error was found. Whitespace skipped before what was
+// expected is never part of it, so it is that of the first character that couldn't be matched.
 func (e *Error) Pos() int { return e.pos }
 
+// TokenStart is the offset of the token the error is in. It is Pos too unless the error is in
+// the middle of a token, eg at a bad escape in a string literal, where it is the offset of the
+// opening quote.
+func (e *Error) TokenStart() int {
+	if e.inToken {
+		return e.tokenStart
+	}
+	return e.pos
+}
+
+// mapOffsets maps the offsets of the error with f, eg back to the input before normalization.
+func (e *Error) mapOffsets(f func(int) int) {
+	e.pos = f(e.pos)
+	if e.inToken {
+		e.tokenStart = f(e.tokenStart)
+	}
+}
+
+// Expected is what the parser was looking for at Pos.
+func (e *Error) Expected() string { return e.expected }
+
+// Message is the explanation given with ErrorHereWithMessage, or "" if there is none.
+func (e *Error) Message() string { return e.message }
+
+// Rule is the name of the innermost Rule the error was found in, or "" if it isn't in one.
+func (e *Error) Rule() string { return e.rule }
+
+// Sources returns the chain of includes leading to the source the error was found in,
+// starting from the input given to Run, with the Pos of each where the next was included.
+// The last is the source the error is in. It is empty for errors in the input itself.
+func (e *Error) Sources() []Source { return e.sources }
+
 // Error satisfies the golang error interface
-func (e *Error) Error() string { return fmt.Sprintf("offset %d: expected %s", e.pos, e.expected) }
+func (e *Error) Error() string {
+	what := "expected " + e.expected
+	switch {
+	case e.message != "":
+		what = e.message
+	case e.eof && e.expected == "!EOF":
+		what = ErrUnexpectedEOF.Error()
+	case e.eof:
+		what = ErrUnexpectedEOF.Error() + ", " + what
+	}
+	if e.rule != "" {
+		what += " in rule " + e.rule
+	}
+	if len(e.sources) == 0 {
+		return fmt.Sprintf("offset %d: %s", e.pos, what)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s offset %d: %s", e.sources[len(e.sources)-1].Name, e.pos, what)
+	for i := len(e.sources) - 2; i >= 0; i-- {
+		if i == len(e.sources)-2 {
+			b.WriteString(" (included from ")
+		} else {
+			b.WriteString(", included from ")
+		}
+		if e.sources[i].Name != "" {
+			b.WriteString(e.sources[i].Name + " ")
+		}
+		fmt.Fprintf(&b, "offset %d", e.sources[i].Pos)
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Is tells whether target is ErrUnexpectedEOF and the error is at the end of the input.
+func (e *Error) Is(target error) bool {
+	return target == ErrUnexpectedEOF && e.eof
+}
 
 // UnparsedInputError is returned by Run when not all of the input was consumed. There may still be a valid result
 type UnparsedInputError struct {
 	remaining string
+	// incomplete is set when the parse was stopped by the end of the input, see IsIncomplete.
+	incomplete bool
 }
 
 // Is tells whether the target is of type UnparsedInputError
@@ -26,6 +126,25 @@ func (e UnparsedInputError) Is(target error) bool {
 	return ok
 }
 
+// IsIncomplete returns whether err is from a parse that got as far as the end of the input,
+// so that more input might have completed it, eg to tell a REPL to show a continuation prompt
+// rather than the error for "(1 +". That includes errors like "left unparsed: +" from a
+// Many that gave up at the end of the input on a "+" missing its right hand side.
+func IsIncomplete(err error) bool {
+	var perr *Error
+	var unparsed UnparsedInputError
+	switch {
+	case errors.As(err, &perr):
+		return perr.incomplete
+	case errors.As(err, &unparsed):
+		return unparsed.incomplete
+	}
+	return false
+}
+
+// Remaining is the input that was left unparsed.
+func (e UnparsedInputError) Remaining() string { return e.remaining }
+
 // Error satisfies the golang error interface
 func (e UnparsedInputError) Error() string {
 	return "left unparsed: " + e.remaining
@@ -13,9 +13,131 @@ func TestUnparsedInputError_Is(t *testing.T) {
 		}
 	})
 	t.Run("UnparsedInputError is an UnparsedInputError", func(t *testing.T) {
-		err := UnparsedInputError{"more stuff"}
+		err := UnparsedInputError{remaining: "more stuff"}
 		if !errors.Is(err, UnparsedInputError{}) {
 			t.Fatal("error did not get classified as UnparsedInputError")
 		}
 	})
 }
+
+func TestUnexpectedEOF(t *testing.T) {
+	parser := Seq("(", Chars("a-z"), ")")
+
+	t.Run("at the end of the input", func(t *testing.T) {
+		_, _, err := Run(parser, "(abc")
+		if !errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatalf("%v is not ErrUnexpectedEOF", err)
+		}
+		if got, want := err.Error(), "offset 4: unexpected end of input, expected )"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+	t.Run("before the end of the input", func(t *testing.T) {
+		_, _, err := Run(parser, "(abc]")
+		if errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatalf("%v is ErrUnexpectedEOF", err)
+		}
+	})
+	t.Run("when there is nothing left to choose from", func(t *testing.T) {
+		_, _, err := Run(Seq("(", Any("a", "b")), "( ")
+		if !errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatalf("%v is not ErrUnexpectedEOF", err)
+		}
+		if got, want := err.Error(), "offset 2: unexpected end of input"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestIsIncomplete(t *testing.T) {
+	var expr Parser
+	value := Any(NumberLit(), Seq("(", &expr, ")"))
+	expr = Seq(value, Many(Seq(Chars("+-", 1, 1), value)))
+
+	for input, want := range map[string]bool{
+		"1 + 2":   false,
+		"(1 + 2":  true,
+		"1 +":     true,
+		"1 + ":    true,
+		"(1 + (":  true,
+		"":        true,
+		"1 + )":   false,
+		"(1 + 2]": false,
+		"1 2":     false,
+	} {
+		_, _, err := Run(expr, input)
+		if got := IsIncomplete(err); got != want {
+			t.Errorf("IsIncomplete(%v) for %q = %v, want %v", err, input, got, want)
+		}
+	}
+
+	if IsIncomplete(errors.New("unexpected end of input")) {
+		t.Error("other errors are not incomplete")
+	}
+}
+
+func TestErrorPositionsSkipWhitespace(t *testing.T) {
+	never := func(ps *State) bool { return false }
+	always := func(ps *State) bool { return true }
+	var ref Parser = Exact("x")
+
+	tests := map[string]Parserish{
+		"Exact":         "x",
+		"Chars":         Chars("a-z"),
+		"NotChars":      NotChars("!"),
+		"Regex":         Regex("[a-z]+"),
+		"StringLit":     StringLit(`"`),
+		"NumberLit":     NumberLit(),
+		"Int":           Int(IntOptions{}),
+		"Ident":         Ident(IdentOptions{}),
+		"Seq":           Seq("x", "y"),
+		"Any":           Any("x", "y"),
+		"AnyWithName":   AnyWithName("letter", "x", "y"),
+		"AdaptiveAny":   AdaptiveAny("x", "y"),
+		"Longest":       Longest("x", "xy"),
+		"Some":          Some("x"),
+		"Named":         Named("x", "x"),
+		"Map":           Map("x", func(n *Result) {}),
+		"Reference":     &ref,
+		"When":          When(never, "x"),
+		"Unless":        Unless(always, "x"),
+		"Scope":         Scope("s", Seq("x", CutIn("s"))),
+		"LeftRecursive": LeftRecursive(Any(Seq(&ref, "y"), "x")),
+	}
+	for name, parser := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := Run(parser, "  \t!")
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+			if perr.Pos() != 3 || perr.TokenStart() != 3 {
+				t.Fatalf("got the error %v starting at %d, want it at offset 3", err, perr.TokenStart())
+			}
+		})
+	}
+}
+
+func TestErrorTokenStart(t *testing.T) {
+	tests := []struct {
+		parser     Parserish
+		input      string
+		pos, start int
+	}{
+		{StringLit(`"`), ` "ab\ux"`, 6, 1},
+		{StringLit(`"`), ` "ab`, 1, 1},
+		{Seq("a", "b"), " a c", 3, 3},
+		{CSVField(',', '"'), `"ab"c`, 4, 0},
+		{Heredoc(Seq("<<", Chars("A-Z")).Map(func(n *Result) { n.Result = n.Child[1].Token })), " <<END\nbody", 7, 1},
+	}
+	for _, test := range tests {
+		_, _, err := Run(test.parser, test.input)
+		var perr *Error
+		if !errors.As(err, &perr) {
+			t.Fatalf("%q: got %v, want an *Error", test.input, err)
+		}
+		if perr.Pos() != test.pos || perr.TokenStart() != test.start {
+			t.Fatalf("%q: got the error %v in a token starting at %d, want it at %d in one starting at %d", test.input, err, perr.TokenStart(), test.pos, test.start)
+		}
+	}
+}
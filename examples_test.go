@@ -18,5 +18,5 @@ func ExampleCut() {
 
 	// Output:
 	// left unparsed: <foo
-	// offset 9: expected >
+	// offset 9: unexpected end of input, expected >
 }
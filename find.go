@@ -0,0 +1,104 @@
+package goparsify
+
+// Find skips ahead through the input until p matches, so a pattern can be picked out of a
+// document without describing everything around it. The input skipped is returned as
+// .Child[0], as a Token holding it, and the match as .Child[1]. It fails if p matches nowhere
+// in the rest of the input.
+func Find(p Parserish) Parser {
+	parser := Parsify(p)
+	// Find skips any amount of input before p.
+	rule := &grammarRule{kind: "Find()", withoutChild: true}
+
+	return NewParser("Find()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parser, false)
+			return
+		}
+		startpos := ps.Pos
+		var match Result
+		at, found, _ := findNext(ps, parser, &match)
+		if !found {
+			ps.Pos = startpos
+			return
+		}
+		node.Child = []Result{
+			{Token: ps.text(startpos, at), Start: ps.offset(startpos), End: ps.offset(at)},
+			match,
+		}
+		node.Start, node.End = ps.offset(startpos), ps.offset(ps.Pos)
+	})
+}
+
+// FindAll returns every match of p in the rest of the input as .Child[n], skipping the input
+// between them. Matches don't overlap, the search for the next one starts where the last one
+// ended. It consumes all of the input and never fails, unless p fails after a Cut.
+func FindAll(p Parserish) Parser {
+	parser := Parsify(p)
+	rule := &grammarRule{kind: "FindAll()"}
+
+	return NewParser("FindAll()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.many(ps, rule, 0, parser, nil)
+			return
+		}
+		startpos := ps.Pos
+		node.Child = nil
+		for ps.Pos < ps.length() {
+			var match Result
+			at, found, cut := findNext(ps, parser, &match)
+			if cut {
+				ps.Pos = startpos
+				return
+			}
+			if !found {
+				ps.Recover()
+				break
+			}
+			node.Child = append(node.Child, match)
+			if ps.Pos == at {
+				// Don't find the same empty match again.
+				ps.Advance(ps.nextWidth())
+			}
+		}
+		ps.Pos = ps.length()
+		node.Start, node.End = ps.offset(startpos), ps.offset(ps.Pos)
+	})
+}
+
+// findNext runs parser at each rune, or token with RunTokens, from the current position on
+// until it matches, leaving the position at the end of the match and returning where it starts
+// after whitespace, setting the span of match if parser didn't. Otherwise it leaves the error
+// from the start position, or from where a Cut stopped it and cut is set.
+func findNext(ps *State, parser Parser, match *Result) (at int, found bool, cut bool) {
+	startpos := ps.Pos
+	var firstError Error
+	for pos := startpos; pos <= ps.length(); {
+		ps.Pos = pos
+		parser(ps, match)
+		if !ps.Errored() {
+			end := ps.Pos
+			ps.Pos = pos
+			ps.WS(ps)
+			at, ps.Pos = ps.Pos, end
+			if match.Start == 0 && match.End == 0 {
+				match.Start, match.End = ps.offset(at), ps.offset(end)
+			}
+			return at, true, false
+		}
+		if ps.cutSince(pos) {
+			return 0, false, true
+		}
+		if pos == startpos {
+			firstError = ps.Error
+		}
+		ps.Recover()
+		*match = Result{}
+		if pos == ps.length() {
+			break
+		}
+		ps.Pos = pos
+		pos += ps.nextWidth()
+	}
+	ps.Error = firstError
+	return 0, false, false
+}
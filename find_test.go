@@ -0,0 +1,67 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	date := Seq(Chars("0-9", 4, 4), "-", Chars("0-9", 2, 2))
+
+	t.Run("skips to the match", func(t *testing.T) {
+		node, ps := runParser("released in 2017-06 or so", Find(date))
+		require.False(t, ps.Errored())
+		require.Equal(t, "released in ", node.Child[0].Token)
+		require.Equal(t, 0, node.Child[0].Start)
+		require.Equal(t, 12, node.Child[0].End)
+		require.Equal(t, "2017", node.Child[1].Child[0].Token)
+		require.Equal(t, " or so", ps.Get())
+	})
+
+	t.Run("match at the start", func(t *testing.T) {
+		node, ps := runParser("2017-06", Find(date))
+		require.False(t, ps.Errored())
+		require.Equal(t, "", node.Child[0].Token)
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, ps := runParser("released in june", Find(date))
+		require.Equal(t, "offset 0: expected 0-9", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("stops at a cut", func(t *testing.T) {
+		_, ps := runParser("see 2017 and 2017-06", Find(Seq(Chars("0-9", 4, 4), Cut(), "-")))
+		require.Equal(t, "offset 9: expected -", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+}
+
+func TestFindAll(t *testing.T) {
+	word := Regex(`[a-zé]+`)
+
+	t.Run("every match", func(t *testing.T) {
+		node, ps := runParser("12 café, 3 tea & 40 cakes!", FindAll(word))
+		require.False(t, ps.Errored())
+		assertSequence(t, node, "café", "tea", "cakes")
+		require.Equal(t, 21, node.Child[2].Start)
+		require.Equal(t, 26, node.Child[2].End)
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		node, ps := runParser("12 34", FindAll(word))
+		require.False(t, ps.Errored())
+		require.Empty(t, node.Child)
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("empty matches", func(t *testing.T) {
+		node, ps := runParser("ab", FindAll(Maybe("x")))
+		require.False(t, ps.Errored())
+		require.Len(t, node.Child, 2)
+		require.Equal(t, "", ps.Get())
+	})
+}
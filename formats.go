@@ -0,0 +1,177 @@
+package goparsify
+
+import (
+	"net/url"
+	"strings"
+)
+
+// UUID matches a UUID in its canonical 8-4-4-4-12 hex form, optionally wrapped in braces,
+// and binds the 16 bytes into .Result as a [16]byte.
+func UUID() Parser {
+	return NewParser("uuid", func(ps *State, node *Result) {
+		ps.WS(ps)
+		input := ps.Get()
+		braced := strings.HasPrefix(input, "{")
+		if braced {
+			input = input[1:]
+		}
+
+		var uuid [16]byte
+		pos := 0
+		for i := range uuid {
+			if pos == 8 || pos == 13 || pos == 18 || pos == 23 {
+				if pos >= len(input) || input[pos] != '-' {
+					ps.ErrorHere("uuid")
+					return
+				}
+				pos++
+			}
+			if pos+2 > len(input) {
+				ps.ErrorHere("uuid")
+				return
+			}
+			b, ok := unhex(input[pos : pos+2])
+			if !ok {
+				ps.ErrorHere("uuid")
+				return
+			}
+			uuid[i] = byte(b)
+			pos += 2
+		}
+
+		if braced {
+			if pos >= len(input) || input[pos] != '}' {
+				ps.ErrorHere("uuid")
+				return
+			}
+			pos += 2 // the closing brace and the opening one we skipped
+		}
+
+		if ps.tokenTooLong(ps.Pos, pos) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+pos]
+		node.Result = uuid
+		node.Start, node.End = ps.Pos, ps.Pos+pos
+		ps.Advance(pos)
+	})
+}
+
+// Email matches an address of the form local@domain and binds it into .Result as a string.
+// The local part is an RFC 5322 dot-atom and the domain is one or more hostname labels, which
+// covers real world addresses without quoted local parts or IP literals. A trailing period
+// is never part of the match, so addresses can end sentences.
+func Email() Parser {
+	return NewParser("email", func(ps *State, node *Result) {
+		ps.WS(ps)
+		input := ps.Get()
+
+		local := scanDotSeparated(input, isAtomByte)
+		if local == 0 || local >= len(input) || input[local] != '@' {
+			ps.ErrorHere("email")
+			return
+		}
+
+		domain := scanDotSeparated(input[local+1:], isLabelByte)
+		if domain == 0 {
+			ps.ErrorHere("email")
+			return
+		}
+		for _, label := range strings.Split(input[local+1:local+1+domain], ".") {
+			if label[0] == '-' || label[len(label)-1] == '-' || len(label) > 63 {
+				ps.ErrorHere("email")
+				return
+			}
+		}
+
+		end := local + 1 + domain
+		if ps.tokenTooLong(ps.Pos, end) {
+			return
+		}
+		node.Token = input[:end]
+		node.Result = node.Token
+		node.Start, node.End = ps.Pos, ps.Pos+end
+		ps.Advance(end)
+	})
+}
+
+// scanDotSeparated returns the length of the longest prefix of s made of non empty runs
+// of allowed bytes joined by single dots.
+func scanDotSeparated(s string, allowed func(byte) bool) int {
+	end := 0
+	for {
+		runEnd := end
+		for runEnd < len(s) && allowed(s[runEnd]) {
+			runEnd++
+		}
+		if runEnd == end {
+			if end > 0 {
+				return end - 1 // drop the dot we just stepped over
+			}
+			return 0
+		}
+		if runEnd >= len(s) || s[runEnd] != '.' {
+			return runEnd
+		}
+		end = runEnd + 1
+	}
+}
+
+func isAtomByte(c byte) bool {
+	return isLabelByte(c) || strings.IndexByte("!#$%&'*+/=?^_`{|}~", c) >= 0
+}
+
+func isLabelByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-'
+}
+
+// URL matches an absolute URL such as https://example.com/a?b=c and binds it into .Result as
+// a *url.URL. The URL runs until whitespace, and trailing punctuation that is more likely
+// to belong to the surrounding text (eg a full stop or an unbalanced closing paren) is left behind.
+func URL() Parser {
+	return NewParser("url", func(ps *State, node *Result) {
+		ps.WS(ps)
+		input := ps.Get()
+
+		scheme := 0
+		for scheme < len(input) && (isLabelByte(input[scheme]) || input[scheme] == '+' || input[scheme] == '.') {
+			scheme++
+		}
+		if scheme == 0 || !isLetter(input[0]) || scheme >= len(input) || input[scheme] != ':' {
+			ps.ErrorHere("url")
+			return
+		}
+
+		end := scheme + 1
+		for end < len(input) && input[end] > ' ' && input[end] != 0x7f && strings.IndexByte(`<>"`, input[end]) < 0 {
+			end++
+		}
+		for end > scheme+1 {
+			c := input[end-1]
+			if strings.IndexByte(".,;:!?'", c) >= 0 ||
+				c == ')' && strings.Count(input[:end], "(") < strings.Count(input[:end], ")") {
+				end--
+				continue
+			}
+			break
+		}
+
+		if ps.tokenTooLong(ps.Pos, end) {
+			return
+		}
+		u, err := url.Parse(input[:end])
+		if err != nil || u.Host == "" && u.Opaque == "" {
+			ps.ErrorHere("url")
+			return
+		}
+
+		node.Token = input[:end]
+		node.Result = u
+		node.Start, node.End = ps.Pos, ps.Pos+end
+		ps.Advance(end)
+	})
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,96 @@
+package goparsify
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUID(t *testing.T) {
+	want := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+
+	t.Run("canonical", func(t *testing.T) {
+		result, p := runParser("123e4567-e89b-12d3-a456-426614174000 rest", UUID())
+		require.Equal(t, want, result.Result)
+		require.Equal(t, "123e4567-e89b-12d3-a456-426614174000", result.Token)
+		require.Equal(t, " rest", p.Get())
+	})
+
+	t.Run("braces and upper case", func(t *testing.T) {
+		result, p := runParser("{123E4567-E89B-12D3-A456-426614174000}", UUID())
+		require.Equal(t, want, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	for _, input := range []string{
+		"123e4567e89b12d3a456426614174000",
+		"123e4567-e89b-12d3-a456-42661417400",
+		"{123e4567-e89b-12d3-a456-426614174000",
+		"123e4567-e89b-12d3-a456-42661417400g",
+	} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, p := runParser(input, UUID())
+			require.Equal(t, "offset 0: expected uuid", p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		result, p := runParser("bob.smith+tag@mail.example.com wrote", Email())
+		require.Equal(t, "bob.smith+tag@mail.example.com", result.Result)
+		require.Equal(t, " wrote", p.Get())
+	})
+
+	t.Run("ends a sentence", func(t *testing.T) {
+		result, p := runParser("bob@example.com.", Email())
+		require.Equal(t, "bob@example.com", result.Token)
+		require.Equal(t, ".", p.Get())
+	})
+
+	for _, input := range []string{"bob", "@example.com", "bob@", "bob..smith@example.com", ".bob@example.com", "bob@-example.com"} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, p := runParser(input, Email())
+			require.Equal(t, "offset 0: expected email", p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	t.Run("http", func(t *testing.T) {
+		result, p := runParser("https://example.com/a/b?c=d#e next", URL())
+		u := result.Result.(*url.URL)
+		require.Equal(t, "https", u.Scheme)
+		require.Equal(t, "example.com", u.Host)
+		require.Equal(t, "/a/b", u.Path)
+		require.Equal(t, "c=d", u.RawQuery)
+		require.Equal(t, " next", p.Get())
+	})
+
+	t.Run("opaque", func(t *testing.T) {
+		result, _ := runParser("mailto:bob@example.com", URL())
+		require.Equal(t, "bob@example.com", result.Result.(*url.URL).Opaque)
+	})
+
+	t.Run("trailing punctuation", func(t *testing.T) {
+		result, p := runParser("(see http://example.com/x).", Seq("(", Exact("see"), URL(), ")", "."))
+		require.False(t, p.Errored())
+		require.Equal(t, "http://example.com/x", result.Child[2].Token)
+	})
+
+	t.Run("balanced parens are kept", func(t *testing.T) {
+		result, _ := runParser("https://en.wikipedia.org/wiki/Go_(game)", URL())
+		require.Equal(t, "https://en.wikipedia.org/wiki/Go_(game)", result.Token)
+	})
+
+	for _, input := range []string{"example.com", "http://", "1http://example.com", "://example.com"} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, p := runParser(input, URL())
+			require.Equal(t, "offset 0: expected url", p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package goparsify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keys of the annotations Fuzzy and FuzzyAny set in Result.Meta.
+const (
+	// FuzzyDistanceKey holds the edit distance between the matched input and the word as an int.
+	FuzzyDistanceKey = "fuzzy.distance"
+	// FuzzyWordKey holds the word that matched, as the Token holds the input.
+	FuzzyWordKey = "fuzzy.word"
+)
+
+// Fuzzy matches word allowing up to maxDist edits, ie runes inserted, deleted or replaced, so
+// mistyped or OCR'd text like "chickns" still matches "chickens". The input matched is in
+// .Token and the number of edits under FuzzyDistanceKey in .Meta, which also lowers its
+// confidence, see ConfidenceKey. The closest match wins, and of those the shortest so trailing whitespace isn't taken.
+func Fuzzy(word string, maxDist int) Parser {
+	return FuzzyAny(maxDist, word)
+}
+
+// FuzzyAny is like Fuzzy for a set of words, eg keywords, and matches the closest of them. Ties
+// go to the word listed first. The word matched is under FuzzyWordKey in .Meta.
+func FuzzyAny(maxDist int, words ...string) Parser {
+	var runes [][]rune
+	var quoted []string
+	for _, word := range words {
+		runes = append(runes, []rune(word))
+		quoted = append(quoted, strconv.Quote(word))
+	}
+	expected := words[0]
+	if len(words) > 1 {
+		expected = "one of " + strings.Join(words, ", ")
+	}
+	description := fmt.Sprintf("%s within %d edits", strings.Join(quoted, " or "), maxDist)
+
+	return NewParser(expected, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
+		ps.WS(ps)
+		bestWord, bestLength, bestDist := -1, 0, 0
+		for i, word := range runes {
+			length, dist, ok := fuzzyPrefix(ps.Get(), word, maxDist)
+			if ok && (bestWord < 0 || dist < bestDist) {
+				bestWord, bestLength, bestDist = i, length, dist
+			}
+		}
+		if bestWord < 0 {
+			ps.ErrorHere(expected)
+			return
+		}
+
+		if ps.tokenTooLong(ps.Pos, bestLength) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+bestLength]
+		node.Start, node.End = ps.Pos, ps.Pos+bestLength
+		node.SetMeta(FuzzyDistanceKey, bestDist)
+		node.SetMeta(FuzzyWordKey, words[bestWord])
+		node.SetMeta(ConfidenceKey, editConfidence(bestDist, len(runes[bestWord])))
+		ps.Advance(bestLength)
+	})
+}
+
+// fuzzyPrefix finds the shortest prefix of input that is closest to word by edit distance,
+// returning its length in bytes and its distance if that is within maxDist.
+func fuzzyPrefix(input string, word []rune, maxDist int) (length int, dist int, ok bool) {
+	// Only prefixes of up to len(word)+maxDist runes can be close enough.
+	var in []rune
+	var offsets []int
+	for i, r := range input {
+		if len(in) == len(word)+maxDist {
+			break
+		}
+		in = append(in, r)
+		offsets = append(offsets, i+utf8.RuneLen(r))
+	}
+
+	// prev[j] is the distance between the word so far and in[:j].
+	prev := make([]int, len(in)+1)
+	cur := make([]int, len(in)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(word); i++ {
+		cur[0] = i
+		for j := 1; j <= len(in); j++ {
+			cur[j] = prev[j-1]
+			if word[i-1] != in[j-1] {
+				cur[j]++
+			}
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	best := -1
+	for j := 1; j <= len(in); j++ {
+		if prev[j] <= maxDist && (best < 0 || prev[j] < prev[best]) {
+			best = j
+		}
+	}
+	if best < 0 {
+		return 0, 0, false
+	}
+	return offsets[best-1], prev[best], true
+}
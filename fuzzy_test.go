@@ -0,0 +1,65 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzy(t *testing.T) {
+	meta := func(n Result, key string) interface{} {
+		value, _ := n.GetMeta(key)
+		return value
+	}
+
+	t.Run("exact", func(t *testing.T) {
+		node, ps := runParser("chickens and eggs", Fuzzy("chickens", 2))
+		require.Equal(t, "chickens", node.Token)
+		require.Equal(t, 0, meta(node, FuzzyDistanceKey))
+		require.Equal(t, " and eggs", ps.Get())
+	})
+
+	t.Run("missing letter", func(t *testing.T) {
+		node, ps := runParser("chickns and eggs", Fuzzy("chickens", 2))
+		require.Equal(t, "chickns", node.Token)
+		require.Equal(t, 1, meta(node, FuzzyDistanceKey))
+		require.Equal(t, 0, node.Start)
+		require.Equal(t, 7, node.End)
+		require.Equal(t, " and eggs", ps.Get())
+	})
+
+	t.Run("extra and replaced letters", func(t *testing.T) {
+		node, ps := runParser("  chiclkens", Fuzzy("chickens", 2))
+		require.Equal(t, "chiclkens", node.Token)
+		require.Equal(t, 1, meta(node, FuzzyDistanceKey))
+		require.Equal(t, "", ps.Get())
+
+		node, _ = runParser("chikcens", Fuzzy("chickens", 2))
+		require.Equal(t, "chikcens", node.Token)
+		require.Equal(t, 2, meta(node, FuzzyDistanceKey))
+	})
+
+	t.Run("runes", func(t *testing.T) {
+		node, ps := runParser("crème brûlée", Fuzzy("creme", 1))
+		require.Equal(t, "crème", node.Token)
+		require.Equal(t, " brûlée", ps.Get())
+	})
+
+	t.Run("too far", func(t *testing.T) {
+		_, ps := runParser("chkns", Fuzzy("chickens", 2))
+		require.Equal(t, "offset 0: expected chickens", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("keywords", func(t *testing.T) {
+		keyword := FuzzyAny(1, "select", "from", "where")
+		node, ps := runParser("selct * frm t", Seq(keyword, "*", keyword, "t"))
+		require.False(t, ps.Errored())
+		require.Equal(t, "select", meta(node.Child[0], FuzzyWordKey))
+		require.Equal(t, "from", meta(node.Child[2], FuzzyWordKey))
+		require.Equal(t, "frm", node.Child[2].Token)
+
+		_, ps = runParser("update", keyword)
+		require.Equal(t, "offset 0: expected one of select, from, where", ps.Error.Error())
+	})
+}
@@ -0,0 +1,200 @@
+package goparsify
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// AnyGrapheme matches a single user-perceived character, ie an extended grapheme cluster like
+// an emoji with its skin tone, a flag or a letter with combining accents, so text written by
+// people isn't split in the middle of one. The match will be stored in .Token.
+func AnyGrapheme() Parser {
+	return NewParser("AnyGrapheme", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("any grapheme")
+		}
+		ps.WS(ps)
+		n := nextGrapheme(ps.Get())
+		if n == 0 {
+			ps.ErrorHere("any grapheme")
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, n) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+n]
+		node.Start, node.End = ps.Pos, ps.Pos+n
+		ps.Advance(n)
+	})
+}
+
+// GraphemeChars is like Chars but matches whole grapheme clusters, taking the ones that start
+// with a rune matcher matches along with everything combined with it: GraphemeChars(`\p{L}`)
+// takes the accents on the letters it matches and Chars(`\p{L}`) stops before them. min and max
+// count graphemes.
+func GraphemeChars(matcher string, repetition ...int) Parser {
+	min, max := parseRepetition(1, -1, repetition...)
+	class := parseCharClass(matcher)
+	description := "graphemes of " + charsDescription(matcher, false, min, max)
+
+	return NewParser("["+matcher+"]", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
+		ps.WS(ps)
+		matched, count := 0, 0
+		for rest := ps.Get(); len(rest) > matched && (max == -1 || count < max); count++ {
+			r, _ := utf8.DecodeRuneInString(rest[matched:])
+			if !class.contains(r) {
+				break
+			}
+			matched += nextGrapheme(rest[matched:])
+		}
+		if count < min {
+			ps.ErrorHere(matcher)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, matched) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+matched]
+		node.Start, node.End = ps.Pos, ps.Pos+matched
+		ps.Advance(matched)
+	})
+}
+
+// CountGraphemes returns the number of grapheme clusters in s, eg to limit the length of a
+// token in user-perceived characters rather than bytes or runes.
+func CountGraphemes(s string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[nextGrapheme(s):]
+		count++
+	}
+	return count
+}
+
+// graphemeBreak is a class of rune from UAX #29, as far as it matters to nextGrapheme.
+type graphemeBreak int
+
+const (
+	gbOther graphemeBreak = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+	gbPictographic
+)
+
+func graphemeBreakOf(r rune) graphemeBreak {
+	switch {
+	case r == '\r':
+		return gbCR
+	case r == '\n':
+		return gbLF
+	case r == 0x200d:
+		return gbZWJ
+	case r == 0x200c, unicode.In(r, unicode.Mn, unicode.Me, unicode.Other_Grapheme_Extend), r >= 0x1f3fb && r <= 0x1f3ff:
+		return gbExtend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gbControl
+	case r >= 0x1f1e6 && r <= 0x1f1ff:
+		return gbRegionalIndicator
+	case unicode.Is(unicode.Mc, r):
+		return gbSpacingMark
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return gbL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return gbV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return gbT
+	case r >= 0xac00 && r <= 0xd7a3 && (r-0xac00)%28 == 0:
+		return gbLV
+	case r >= 0xac00 && r <= 0xd7a3:
+		return gbLVT
+	case r >= 0x1f000 && r <= 0x1faff, r >= 0x2600 && r <= 0x27bf, r == 0xa9, r == 0xae, r == 0x203c, r == 0x2049, r == 0x2122, r == 0x2139:
+		return gbPictographic
+	}
+	return gbOther
+}
+
+// nextGrapheme returns the length in bytes of the extended grapheme cluster at the start of s,
+// following the rules of UAX #29 except for prepended concatenation marks, with a simplified
+// set of pictographs for emoji zero width joiner sequences.
+func nextGrapheme(s string) int {
+	if s == "" {
+		return 0
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	prev := graphemeBreakOf(r)
+	// pictographic is set while the cluster is a pictograph followed by extenders, which a zero
+	// width joiner can join to another pictograph. regional counts the regional indicators in a
+	// row, which pair up into flags.
+	pictographic := prev == gbPictographic
+	regional := 0
+	if prev == gbRegionalIndicator {
+		regional = 1
+	}
+
+	for n < len(s) {
+		r, w := utf8.DecodeRuneInString(s[n:])
+		next := graphemeBreakOf(r)
+		if !joinsGrapheme(prev, next, pictographic, regional) {
+			break
+		}
+		switch {
+		case next == gbPictographic:
+			pictographic = true
+		case next != gbExtend && next != gbZWJ:
+			pictographic = false
+		}
+		if next == gbRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = next
+		n += w
+	}
+	return n
+}
+
+// joinsGrapheme tells whether there is no grapheme cluster boundary between runes of the
+// classes prev and next.
+func joinsGrapheme(prev, next graphemeBreak, pictographic bool, regional int) bool {
+	switch {
+	case prev == gbCR:
+		return next == gbLF
+	case prev == gbLF || prev == gbControl || next == gbCR || next == gbLF || next == gbControl:
+		return false
+	case prev == gbL:
+		if next == gbL || next == gbV || next == gbLV || next == gbLVT {
+			return true
+		}
+	case prev == gbLV || prev == gbV:
+		if next == gbV || next == gbT {
+			return true
+		}
+	case prev == gbLVT || prev == gbT:
+		if next == gbT {
+			return true
+		}
+	}
+	switch {
+	case next == gbExtend || next == gbZWJ || next == gbSpacingMark:
+		return true
+	case prev == gbZWJ && next == gbPictographic:
+		return pictographic
+	case prev == gbRegionalIndicator && next == gbRegionalIndicator:
+		return regional%2 == 1
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnyGrapheme(t *testing.T) {
+	for _, grapheme := range []string{
+		"a",
+		"e\u0301",                    // e with a combining acute accent
+		"\U0001F44D\U0001F3FD",       // thumbs up with a skin tone
+		"\U0001F469\u200d\U0001F467", // woman and girl joined by a zero width joiner
+		"\U0001F1F3\U0001F1FF",       // a flag made of two regional indicators
+		"\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", // a flag made of tags
+		"\u1100\u1161\u11a8", // hangul jamo making up a syllable
+		"\uac01",             // the same syllable precomposed
+		"\u0928\u093f",       // devanagari with a spacing vowel sign
+	} {
+		node, ps := runParser(grapheme+"x", Many(AnyGrapheme()))
+		require.False(t, ps.Errored(), grapheme)
+		require.Equal(t, []string{grapheme, "x"}, []string{node.Child[0].Token, node.Child[1].Token}, grapheme)
+	}
+
+	_, ps := runParser("", AnyGrapheme())
+	require.Equal(t, "offset 0: expected any grapheme", ps.Error.Error())
+
+	node, _ := runParser("\U0001F1F3\U0001F1FF\U0001F1E6\U0001F1FA\U0001F1EB", Many(AnyGrapheme()))
+	assertSequence(t, node, "\U0001F1F3\U0001F1FF", "\U0001F1E6\U0001F1FA", "\U0001F1EB")
+}
+
+func TestGraphemeChars(t *testing.T) {
+	name := "Jose\u0301 \U0001F44D\U0001F3FD"
+
+	node, ps := runParser(name, Chars(`\p{L}`))
+	require.Equal(t, "Jose", node.Token)
+	require.Equal(t, "\u0301 \U0001F44D\U0001F3FD", ps.Get())
+
+	node, ps = runParser(name, GraphemeChars(`\p{L}`))
+	require.Equal(t, "Jose\u0301", node.Token)
+	require.Equal(t, " \U0001F44D\U0001F3FD", ps.Get())
+
+	node, ps = runParser("e\u0301e\u0301e\u0301", GraphemeChars("e", 1, 2))
+	require.Equal(t, "e\u0301e\u0301", node.Token)
+	require.Equal(t, "e\u0301", ps.Get())
+
+	_, ps = runParser("e\u0301", GraphemeChars("e", 2))
+	require.Equal(t, "offset 0: expected e", ps.Error.Error())
+}
+
+func TestCountGraphemes(t *testing.T) {
+	require.Equal(t, 0, CountGraphemes(""))
+	require.Equal(t, 4, CountGraphemes("Jose\u0301"))
+	require.Equal(t, 3, CountGraphemes("a\r\nb"))
+	require.Equal(t, 3, CountGraphemes("\U0001F469\u200d\U0001F467\U0001F1F3\U0001F1FF!"))
+}
@@ -0,0 +1,90 @@
+package goparsify
+
+import "strings"
+
+// HeredocTag can be set as the .Result of the opening tag of a Heredoc to give the delimiter
+// and whether to strip indentation, eg for <<-EOF in a shell grammar.
+type HeredocTag struct {
+	Delimiter string
+	// StripIndent strips the tabs at the start of the lines of the body and of the closing
+	// delimiter, as <<- does in shells.
+	StripIndent bool
+}
+
+// Heredoc matches a here document: openTag, the end of its line, and then the lines up to one
+// holding nothing but the delimiter, which is matched too but not the newline after it. The
+// delimiter is found when the tag is parsed, from the Result of openTag if it's a HeredocTag or
+// a string, and otherwise its .Token. The lines in between, each ending with a newline, will be
+// stored in .Token and the tag in .Child[0].
+//
+//	tag := Seq("<<", Maybe("-"), Chars("A-Z")).Map(func(n *Result) {
+//		n.Result = HeredocTag{Delimiter: n.Child[2].Token, StripIndent: n.Child[1].Token == "-"}
+//	})
+//	doc := Heredoc(tag)
+func Heredoc(openTag Parserish) Parser {
+	tag := Parsify(openTag)
+	rule := &grammarRule{kind: "Heredoc()"}
+	body := func(ps *State, node *Result) {
+		ps.analysis.terminal("lines up to the delimiter")
+	}
+
+	return NewParser("Heredoc()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.seq(ps, rule, []Parser{tag, body})
+			return
+		}
+		m := ps.Mark()
+		var opening Result
+		tag(ps, &opening)
+		if ps.Errored() {
+			return
+		}
+		heredoc, ok := opening.Result.(HeredocTag)
+		if !ok {
+			heredoc.Delimiter = opening.Token
+			if delimiter, isString := opening.Result.(string); isString {
+				heredoc.Delimiter = delimiter
+			}
+		}
+
+		rest := ps.Get()
+		eol := strings.IndexByte(rest, '\n')
+		if eol < 0 || strings.TrimRight(rest[:eol], " \t\r") != "" {
+			pos := ps.Pos + len(rest) - len(strings.TrimLeft(rest, " \t"))
+			ps.Restore(m)
+			ps.errorInToken(opening.Start, pos, "end of line")
+			return
+		}
+
+		var body strings.Builder
+		for pos := eol + 1; pos < len(rest); {
+			end := strings.IndexByte(rest[pos:], '\n')
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += pos
+			}
+			line := strings.TrimSuffix(rest[pos:end], "\r")
+			if heredoc.StripIndent {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == heredoc.Delimiter {
+				node.Token = body.String()
+				node.Child = []Result{opening}
+				node.Start, node.End = opening.Start, ps.Pos+end
+				ps.Advance(end)
+				return
+			}
+			body.WriteString(line + "\n")
+			if ps.tokenTooLong(ps.Pos+eol+1, body.Len()) {
+				ps.Restore(m)
+				return
+			}
+			pos = end + 1
+		}
+
+		start := ps.Pos + eol + 1
+		ps.Restore(m)
+		ps.errorInToken(opening.Start, start, "line with just "+heredoc.Delimiter)
+	})
+}
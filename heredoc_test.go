@@ -0,0 +1,52 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeredoc(t *testing.T) {
+	tag := Seq("<<", Maybe("-"), Chars("A-Z")).Map(func(n *Result) {
+		n.Result = HeredocTag{Delimiter: n.Child[2].Token, StripIndent: n.Child[1].Token == "-"}
+	})
+	command := Seq("cat", Heredoc(tag), "done")
+
+	t.Run("takes the lines up to the delimiter", func(t *testing.T) {
+		node, ps := runParser("cat <<EOF\nhello\n  EOF\nEOFS\nEOF\ndone", command)
+		require.False(t, ps.Errored())
+		require.Equal(t, "hello\n  EOF\nEOFS\n", node.Child[1].Token)
+		require.Equal(t, 4, node.Child[1].Start)
+		require.Equal(t, 30, node.Child[1].End)
+		require.Equal(t, "", ps.Get())
+	})
+
+	t.Run("strips tabs for <<-", func(t *testing.T) {
+		node, ps := runParser("cat <<-END  \r\n\thello\r\n\t\tworld\n\tEND\ndone", command)
+		require.False(t, ps.Errored())
+		require.Equal(t, "hello\nworld\n", node.Child[1].Token)
+
+		_, ps = runParser("cat <<END\n\tEND\n", command)
+		require.Equal(t, "offset 10: expected line with just END", ps.Error.Error())
+	})
+
+	t.Run("needs the tag to end its line", func(t *testing.T) {
+		_, ps := runParser("cat <<EOF x\nEOF\ndone", command)
+		require.Equal(t, "offset 10: expected end of line", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("takes the delimiter from a string or the token", func(t *testing.T) {
+		node, ps := runParser("<<X\nbody\nX", Heredoc(Seq("<<", Chars("A-Z")).Map(func(n *Result) { n.Result = n.Child[1].Token })))
+		require.False(t, ps.Errored())
+		require.Equal(t, "body\n", node.Token)
+
+		node, ps = runParser("#\n#", Heredoc("#"))
+		require.False(t, ps.Errored())
+		require.Equal(t, "", node.Token)
+	})
+
+	t.Run("exports", func(t *testing.T) {
+		require.Equal(t, "grammar = \"cat\" , \"<<\" , ? [A-Z]+ ? , ? lines up to the delimiter ? ;\n", ExportEBNF(Seq("cat", Heredoc(Seq("<<", Chars("A-Z"))))))
+	})
+}
@@ -0,0 +1,75 @@
+package goparsify
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// Highlight classifies a span of the input by the rules it was matched by, see Highlights.
+type Highlight struct {
+	// Start and End are the byte offsets of the span.
+	Start, End int
+	// Rule is the name of the innermost named rule the span is in, eg string or keyword.
+	Rule string
+	// Scopes are the names of all the named rules the span is in, the outermost first and
+	// Rule last, for highlighters that style nested scopes.
+	Scopes []string
+	// Kind is the kind of the innermost result given one with Kinded the span is in, or 0.
+	Kind int
+}
+
+// Highlights flattens the tree rooted at r into the spans of input to highlight, in order and
+// without overlaps. Each token is classified by the rules given names with Named or Rule that
+// it's under, so naming the rules of a grammar is all it takes to drive a syntax highlighter or
+// the semantic tokens of a language server. Tokens can be classified by Kinded too, which is
+// cheaper to switch on. Tokens under no named or kinded rule are left out.
+func Highlights(r *Result) []Highlight {
+	var highlights []Highlight
+	highlight(r, nil, 0, &highlights)
+	return highlights
+}
+
+func highlight(r *Result, scopes []string, kind int, highlights *[]Highlight) {
+	if r.Name != "" {
+		scopes = append(scopes[:len(scopes):len(scopes)], r.Name)
+	}
+	if r.Kind != 0 {
+		kind = r.Kind
+	}
+	if len(r.Child) > 0 {
+		for i := range r.Child {
+			highlight(&r.Child[i], scopes, kind, highlights)
+		}
+		return
+	}
+	if r.Start == r.End || len(scopes) == 0 && kind == 0 {
+		return
+	}
+	h := Highlight{Start: r.Start, End: r.End, Scopes: scopes, Kind: kind}
+	if len(scopes) > 0 {
+		h.Rule = scopes[len(scopes)-1]
+	}
+	*highlights = append(*highlights, h)
+}
+
+// HighlightHTML writes input to w as HTML, with each of the highlights wrapped in a span with
+// the rule as its class, eg <span class="keyword">func</span>. Highlights under no named rule
+// are written as they are.
+func HighlightHTML(w io.Writer, input string, highlights []Highlight) error {
+	var b strings.Builder
+	pos := 0
+	for _, h := range highlights {
+		if h.Rule == "" {
+			continue
+		}
+		b.WriteString(html.EscapeString(input[pos:h.Start]))
+		b.WriteString(`<span class="` + html.EscapeString(h.Rule) + `">`)
+		b.WriteString(html.EscapeString(input[h.Start:h.End]))
+		b.WriteString("</span>")
+		pos = h.End
+	}
+	b.WriteString(html.EscapeString(input[pos:]))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,58 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlights(t *testing.T) {
+	keyword := Named("keyword", Any("let", "in"))
+	ident := Named("ident", Chars("a-z"))
+	number := Named("number", Chars("0-9"))
+	binding := Named("binding", Seq(keyword, ident, "=", number))
+	expr := Seq(binding, keyword, ident)
+
+	tree, err := RunTree(expr, "let x = 1 in x")
+	require.NoError(t, err)
+	require.Equal(t, []Highlight{
+		{Start: 0, End: 3, Rule: "keyword", Scopes: []string{"binding", "keyword"}},
+		{Start: 4, End: 5, Rule: "ident", Scopes: []string{"binding", "ident"}},
+		{Start: 6, End: 7, Rule: "binding", Scopes: []string{"binding"}},
+		{Start: 8, End: 9, Rule: "number", Scopes: []string{"binding", "number"}},
+		{Start: 10, End: 12, Rule: "keyword", Scopes: []string{"keyword"}},
+		{Start: 13, End: 14, Rule: "ident", Scopes: []string{"ident"}},
+	}, Highlights(&tree))
+
+	var b strings.Builder
+	require.NoError(t, HighlightHTML(&b, "let x = 1 in x", Highlights(&tree)))
+	require.Equal(t, `<span class="keyword">let</span> <span class="ident">x</span> <span class="binding">=</span> <span class="number">1</span> <span class="keyword">in</span> <span class="ident">x</span>`, b.String())
+
+	tree, err = RunTree(Seq("a", Named("op", "<"), "b"), "a<b & c", AllowTrailingInput())
+	require.NoError(t, err)
+	b.Reset()
+	require.NoError(t, HighlightHTML(&b, "a<b & c", Highlights(&tree)))
+	require.Equal(t, `a<span class="op">&lt;</span>b &amp; c`, b.String())
+}
+
+func TestHighlightsKinds(t *testing.T) {
+	const (
+		keyword = iota + 1
+		operator
+	)
+	expr := Seq(Kinded(keyword, "not"), Named("ident", Chars("a-z")), Kinded(operator, "&&"), Named("ident", Chars("a-z")))
+
+	tree, err := RunTree(expr, "not a && b")
+	require.NoError(t, err)
+	require.Equal(t, []Highlight{
+		{Start: 0, End: 3, Kind: keyword},
+		{Start: 4, End: 5, Rule: "ident", Scopes: []string{"ident"}},
+		{Start: 6, End: 8, Kind: operator},
+		{Start: 9, End: 10, Rule: "ident", Scopes: []string{"ident"}},
+	}, Highlights(&tree))
+
+	var b strings.Builder
+	require.NoError(t, HighlightHTML(&b, "not a && b", Highlights(&tree)))
+	require.Equal(t, `not <span class="ident">a</span> &amp;&amp; <span class="ident">b</span>`, b.String())
+}
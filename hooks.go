@@ -0,0 +1,34 @@
+package goparsify
+
+import "time"
+
+// Hooks is called as each parser starts and finishes, so tools like custom tracers, metrics or
+// debuggers can be built outside of this package. Like logging, hooks are only called when
+// built with -tags debug, and WithHooks is a no-op without it.
+type Hooks interface {
+	// OnEnter is called before p runs, with the offset into the input it starts at.
+	OnEnter(p ParserInfo, pos int)
+	// OnExit is called after p has run.
+	OnExit(p ParserInfo, o Outcome)
+}
+
+// ParserInfo describes the parser a hook is called for.
+type ParserInfo struct {
+	// Name is what the parser matches, as passed to NewParser, eg "Seq()" or a literal.
+	Name string
+	// Var is the name of the variable the parser was assigned to, if it could be found.
+	Var string
+	// Location is the file and line the parser was created on.
+	Location string
+}
+
+// Outcome is the result of running a parser, passed to Hooks.OnExit.
+type Outcome struct {
+	// Start and End are the offsets into the input before and after the parser ran.
+	Start, End int
+	// Error is set if the parser failed.
+	Error *Error
+	// Result is the node the parser filled in. It is only valid for the duration of the call.
+	Result *Result
+	Took   time.Duration
+}
@@ -0,0 +1,65 @@
+package goparsify
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IdentOptions configures Ident.
+type IdentOptions struct {
+	// Reserved words never match as identifiers, eg keywords like "if" and "return".
+	Reserved []string
+	// Extra lists additional runes allowed after the first character, eg "$" or "-".
+	Extra string
+}
+
+// Ident matches an identifier: a unicode letter or underscore followed by any number of
+// unicode letters, digits, combining marks, underscores or opts.Extra runes. An identifier
+// that is exactly one of opts.Reserved fails to match, so `if` won't be treated as a variable
+// name but `iffy` will.
+func Ident(opts IdentOptions) Parser {
+	reserved := make(map[string]bool, len(opts.Reserved))
+	for _, word := range opts.Reserved {
+		reserved[word] = true
+	}
+
+	return NewParser("identifier", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end := ps.Pos
+
+		r, w := utf8.DecodeRuneInString(ps.Get())
+		if !unicode.IsLetter(r) && r != '_' {
+			ps.ErrorHere("identifier")
+			return
+		}
+		end += w
+
+		for end < len(ps.Input) {
+			r, w := utf8.DecodeRuneInString(ps.Input[end:])
+			if !isIdentRune(r) && !strings.ContainsRune(opts.Extra, r) {
+				break
+			}
+			end += w
+		}
+
+		if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+			return
+		}
+		ident := ps.Input[ps.Pos:end]
+		if reserved[ident] {
+			ps.ErrorHere("identifier")
+			return
+		}
+
+		node.Token = ident
+		node.Start, node.End = ps.Pos, end
+		ps.Pos = end
+	})
+}
+
+// isIdentRune reports whether r may continue an identifier. Combining marks are included
+// so scripts like Devanagari, which attach vowel signs to letters, work as expected.
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
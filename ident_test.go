@@ -0,0 +1,46 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdent(t *testing.T) {
+	parser := Ident(IdentOptions{Reserved: []string{"if", "return"}})
+
+	for _, input := range []string{"foo", "_bar9", "naïve", "переменная", "変数", "नमस्ते", "iffy"} {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored())
+			require.Equal(t, input, result.Token)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	t.Run("stops at punctuation", func(t *testing.T) {
+		result, p := runParser("foo.bar", parser)
+		require.Equal(t, "foo", result.Token)
+		require.Equal(t, ".bar", p.Get())
+	})
+
+	t.Run("cannot start with a digit", func(t *testing.T) {
+		_, p := runParser("9lives", parser)
+		require.Equal(t, "offset 0: expected identifier", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("reserved words", func(t *testing.T) {
+		_, p := runParser("if x", parser)
+		require.Equal(t, "offset 0: expected identifier", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		_, _, err := Run(Seq("return", parser), "return return")
+		require.Equal(t, "offset 7: expected identifier", err.Error())
+	})
+
+	t.Run("extra runes", func(t *testing.T) {
+		result, _ := runParser("$el-id", Seq(Exact("$"), Ident(IdentOptions{Extra: "-"})))
+		require.Equal(t, "el-id", result.Child[1].Token)
+	})
+}
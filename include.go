@@ -0,0 +1,114 @@
+package goparsify
+
+import "fmt"
+
+// Source is an input being parsed, either the one given to Run or one pulled in by Include.
+type Source struct {
+	// Name is what the source was included as, or given by WithSourceName for the input.
+	Name  string
+	Input string
+	// Pos is the offset the source was at when the next source in a chain was included.
+	Pos int
+}
+
+// SourceKey is the annotation in Result.Meta naming the source the result of Include parsed,
+// whose Input its spans and those of its children are offsets into.
+const SourceKey = "source"
+
+// Include parses another source in place of a directive, eg #include "file" or a template
+// partial. When directive matches, load is called with its result and returns the name and
+// input of the source to parse with content, which must match all of it. The result of the
+// directive is .Child[0] and that of the content .Child[1].
+//
+// Included sources are decoded and normalized like the input, as the options given to Run say,
+// and errors in them are reported there, at offsets into the source as load returned it, with
+// the chain of sources that included it, see Error.Sources. Included sources can include others, but not one that is already
+// being included. As the included results are spans of other inputs, Reconstruct can't rebuild
+// the input from them.
+func Include(directive Parserish, load func(n *Result) (name, input string, err error), content Parserish) Parser {
+	directiveParser := Parsify(directive)
+	contentParser := Parsify(content)
+	rule := &grammarRule{kind: "Include()"}
+
+	return NewParser("Include()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.seq(ps, rule, []Parser{directiveParser, contentParser})
+			return
+		}
+		startpos := ps.Pos
+		node.Child = make([]Result, 2)
+		directiveParser(ps, &node.Child[0])
+		if ps.Errored() {
+			ps.Pos = startpos
+			return
+		}
+		name, input, err := load(&node.Child[0])
+		if err != nil {
+			ps.Error = Error{pos: node.Child[0].Start, expected: fmt.Sprintf("source to include (%v)", err)}
+			ps.Pos = startpos
+			return
+		}
+		if ps.including(name) {
+			ps.Error = Error{pos: node.Child[0].Start, expected: fmt.Sprintf("source not already being included, %s is", name)}
+			ps.Pos = startpos
+			return
+		}
+
+		text, offsets, err := prepareInput(input, ps.cfg())
+		if err != nil {
+			ps.Error = Error{pos: node.Child[0].Start, expected: fmt.Sprintf("source to include (%v)", err)}
+			ps.Pos = startpos
+			return
+		}
+
+		// The included source is parsed with offset maps of its own, so its positions are
+		// mapped back to it rather than to the source including it.
+		end, cut, furthest := ps.Pos, ps.Cut, ps.furthest
+		outer, outerInput, outerOriginal, outerOffsets := ps.source, ps.Input, ps.original, ps.offsets
+		ps.includes = append(ps.includes, Source{Name: outer, Input: ps.sourceText(), Pos: ps.offsets.originalOffset(end)})
+		ps.source, ps.Input, ps.Pos, ps.Cut = name, text, 0, 0
+		ps.original, ps.offsets = input, offsets
+		contentParser(ps, &node.Child[1])
+		if !ps.Errored() {
+			ps.WS(ps)
+			if ps.Pos != len(ps.Input) {
+				ps.ErrorHere("end of " + name)
+			}
+		}
+		if ps.Errored() && ps.Error.sources == nil {
+			ps.Error.mapOffsets(offsets.originalOffset)
+			ps.Error.sources = append(append([]Source{}, ps.includes...), Source{Name: name, Input: input, Pos: ps.Error.pos})
+		}
+		ps.includes = ps.includes[:len(ps.includes)-1]
+		ps.source, ps.Input, ps.Pos, ps.Cut, ps.furthest = outer, outerInput, end, cut, furthest
+		ps.original, ps.offsets = outerOriginal, outerOffsets
+		if ps.Errored() {
+			ps.Pos = startpos
+			return
+		}
+		offsets.mapResult(&node.Child[1])
+		node.Child[1].SetMeta(SourceKey, name)
+		node.Start, node.End = node.Child[0].Start, node.Child[0].End
+	})
+}
+
+// including returns whether name is the source being parsed or one that included it.
+func (s *State) including(name string) bool {
+	if s.source == name {
+		return true
+	}
+	for _, source := range s.includes {
+		if source.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceText is the source being parsed as it was given, before decoding and normalization.
+func (s *State) sourceText() string {
+	if s.original == "" {
+		return s.Input
+	}
+	return s.original
+}
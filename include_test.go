@@ -0,0 +1,104 @@
+package goparsify
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInclude(t *testing.T) {
+	files := map[string]string{
+		"a.conf":    "x = 1\n#include \"b.conf\"\ny = 2",
+		"b.conf":    "z = 3",
+		"bad.conf":  "z = 3\nw =",
+		"deep.conf": "#include \"bad.conf\"",
+		"junk.conf": "z = 3 !",
+		"loop.conf": "#include \"loop.conf\"",
+		"crlf.conf": "z = 3\r\n\r\nw =",
+	}
+	load := func(n *Result) (string, string, error) {
+		name := n.Child[2].Token
+		input, ok := files[name]
+		if !ok {
+			return "", "", fmt.Errorf("no file %s", name)
+		}
+		return name, input, nil
+	}
+
+	directive := Seq("#include", Cut(), StringLit(`"`))
+	var line Parser
+	lines := Many(&line)
+	setting := Seq(Regex(`[a-z]+`), "=", Cut(), Regex(`[0-9]+`))
+	line = Any(Include(directive, load, lines), setting)
+
+	settings := func(n *Result) []string {
+		var found []string
+		Walk(n, func(n *Result, depth int) bool {
+			if len(n.Child) == 4 && n.Child[1].Token == "=" {
+				found = append(found, n.Child[0].Token+n.Child[3].Token)
+			}
+			return true
+		})
+		return found
+	}
+
+	t.Run("included", func(t *testing.T) {
+		result, err := RunTree(lines, files["a.conf"], WithSourceName("a.conf"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"x1", "z3", "y2"}, settings(&result))
+		source, _ := result.Child[1].Child[1].GetMeta(SourceKey)
+		require.Equal(t, "b.conf", source)
+	})
+
+	t.Run("error in an included source", func(t *testing.T) {
+		_, err := RunTree(lines, `#include "deep.conf"`, WithSourceName("main.conf"))
+		require.EqualError(t, err, "bad.conf offset 9: expected [0-9]+ (included from deep.conf offset 19, included from main.conf offset 20)")
+
+		sources := err.(*Error).Sources()
+		require.Len(t, sources, 3)
+		require.Equal(t, "main.conf", sources[0].Name)
+		require.Equal(t, "bad.conf", sources[2].Name)
+		require.Equal(t, files["bad.conf"], sources[2].Input)
+	})
+
+	t.Run("normalized sources", func(t *testing.T) {
+		input := "x = 1\r\n\r\n#include \"b.conf\"\r\n#include \"crlf.conf\""
+		_, err := RunTree(lines, input, WithSourceName("main.conf"), WithNormalizedNewlines())
+		require.EqualError(t, err, "crlf.conf offset 12: expected [0-9]+ (included from main.conf offset 48)")
+		require.Equal(t, Pos{Offset: 12, Rune: 12, Line: 3, Column: 4}, err.(*Error).Position())
+
+		input = "x = 1\r\n#include \"b.conf\"\r\ny = 2"
+		result, err := RunTree(lines, input, WithNormalizedNewlines())
+		require.NoError(t, err)
+		require.Equal(t, 7, result.Child[1].Start)
+		require.Equal(t, 31, result.Child[2].Child[3].End)
+		included := result.Child[1].Child[1].Child[0]
+		require.Equal(t, 0, included.Start)
+		require.Equal(t, 5, included.End)
+	})
+
+	t.Run("unnamed input", func(t *testing.T) {
+		_, err := RunTree(lines, `x = 1 #include "bad.conf"`)
+		require.EqualError(t, err, "bad.conf offset 9: expected [0-9]+ (included from offset 25)")
+	})
+
+	t.Run("trailing input", func(t *testing.T) {
+		_, err := RunTree(lines, `#include "junk.conf"`)
+		require.EqualError(t, err, "junk.conf offset 6: expected end of junk.conf (included from offset 20)")
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		_, err := RunTree(Include(directive, load, lines), `#include "c.conf"`)
+		require.EqualError(t, err, "offset 0: expected source to include (no file c.conf)")
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		_, err := RunTree(lines, files["loop.conf"], WithSourceName("loop.conf"))
+		require.Error(t, err)
+
+		_, ps := runParser(files["loop.conf"], line)
+		require.Equal(t, "loop.conf offset 0: expected source not already being included, loop.conf is (included from offset 20)", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+}
@@ -0,0 +1,71 @@
+package goparsify
+
+// ManyIter parses input as Run(Many(p, sep), input) would, but hands each match of p to yield
+// as soon as it's parsed instead of collecting them into the Child of one Result, so a huge
+// list can be processed as it's read and left early by returning false from yield. sep may be
+// nil for none.
+//
+// An error ending the parse, eg a Cut failing in p or input left unparsed after the last match,
+// is passed to yield last with an empty Result. Options work as they do for Run, except for
+// WithTrivia and WithMetrics which only make sense for a whole tree. The function returned has
+// the shape of an iter.Seq2, so from Go 1.23 it can be ranged over:
+//
+//	for item, err := range ManyIter(row, "\n", input) {
+func ManyIter(p Parserish, sep Parserish, input string, opts ...Option) func(yield func(Result, error) bool) {
+	opParser := Parsify(p)
+	var sepParser Parser
+	if sep != nil {
+		sepParser = Parsify(sep)
+	}
+
+	return func(yield func(Result, error) bool) {
+		cfg := newRunConfig(opts)
+		text, offsets, err := prepareInput(input, cfg)
+		if err != nil {
+			yield(Result{}, err)
+			return
+		}
+		ps, err := startParse(text, cfg, input, offsets)
+		if err != nil {
+			yield(Result{}, err)
+			return
+		}
+
+		for n := 1; ; n++ {
+			itemstart := ps.Pos
+			var item Result
+			opParser(ps, &item)
+			if ps.Errored() {
+				// Unless a Cut says otherwise the list ends before the item that didn't match.
+				if !ps.cutSince(ps.Pos) {
+					ps.Recover()
+				}
+				break
+			}
+			if ps.tooManyChildren(n, ps.pos(item.Start)) {
+				break
+			}
+			offsets.mapResult(&item)
+			if !yield(item, nil) {
+				return
+			}
+
+			if sepParser != nil {
+				sepParser(ps, &Result{})
+				if ps.Errored() {
+					ps.Recover()
+					break
+				}
+			}
+			if ps.Pos == itemstart {
+				// An item and separator that match nothing would match again forever.
+				break
+			}
+		}
+
+		if err := finishParse(ps, cfg); err != nil {
+			ps.Error.mapOffsets(offsets.originalOffset)
+			yield(Result{}, err)
+		}
+	}
+}
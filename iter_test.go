@@ -0,0 +1,89 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManyIter(t *testing.T) {
+	collect := func(seq func(yield func(Result, error) bool)) ([]string, error) {
+		var tokens []string
+		var err error
+		seq(func(item Result, e error) bool {
+			if e != nil {
+				err = e
+				return false
+			}
+			tokens = append(tokens, item.Token)
+			return true
+		})
+		return tokens, err
+	}
+
+	t.Run("yields each match", func(t *testing.T) {
+		tokens, err := collect(ManyIter(Chars("a-z"), ",", "a, bc,d"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "bc", "d"}, tokens)
+	})
+
+	t.Run("without a separator", func(t *testing.T) {
+		tokens, err := collect(ManyIter(Any("a", "b"), nil, "a b a"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "a"}, tokens)
+	})
+
+	t.Run("stops early", func(t *testing.T) {
+		var tokens []string
+		ManyIter(Chars("a-z"), ",", "a,b,c,!")(func(item Result, err error) bool {
+			require.NoError(t, err)
+			tokens = append(tokens, item.Token)
+			return len(tokens) < 2
+		})
+		require.Equal(t, []string{"a", "b"}, tokens)
+	})
+
+	t.Run("unparsed input", func(t *testing.T) {
+		tokens, err := collect(ManyIter(Chars("a-z"), ",", "a,b 1"))
+		require.Equal(t, []string{"a", "b"}, tokens)
+		require.EqualError(t, err, "left unparsed: 1")
+	})
+
+	t.Run("cut", func(t *testing.T) {
+		tag := Seq("<", Cut(), Chars("a-z"), ">")
+		tokens, err := collect(ManyIter(tag, nil, "<a> <b"))
+		require.Equal(t, []string{"<a>"}, tokens)
+		require.EqualError(t, err, "offset 6: unexpected end of input, expected >")
+	})
+
+	t.Run("stops at an item that matches nothing", func(t *testing.T) {
+		tokens, err := collect(ManyIter(Maybe("x"), nil, "xy", AllowTrailingInput()))
+		require.NoError(t, err)
+		require.Equal(t, []string{"x", ""}, tokens)
+
+		tree, err := RunTree(Many(Maybe("x")), "xy", AllowTrailingInput())
+		require.NoError(t, err)
+		assertSequence(t, tree, "x", "")
+	})
+
+	t.Run("limits the items", func(t *testing.T) {
+		tokens, err := collect(ManyIter(Chars("a-z"), ",", "a,b,c,d", WithMaxChildren(3)))
+		require.Equal(t, []string{"a", "b", "c"}, tokens)
+		require.EqualError(t, err, "offset 6: expected at most 3 items")
+	})
+
+	t.Run("same as Many", func(t *testing.T) {
+		input := "x1, y2, z3"
+		item := Seq(Chars("a-z"), Chars("0-9"))
+		tree, err := RunTree(Many(item, ","), input)
+		require.NoError(t, err)
+
+		var items []Result
+		ManyIter(item, ",", input)(func(r Result, err error) bool {
+			require.NoError(t, err)
+			items = append(items, r)
+			return true
+		})
+		require.Equal(t, tree.Child, items)
+	})
+}
@@ -38,6 +38,6 @@ func init() {
 
 // Unmarshall json string into map[string]interface{} or []interface{}
 func Unmarshal(input string) (interface{}, error) {
-	result, _, err := Run(_value, input, ASCIIWhitespace)
+	result, _, err := Run(_value, input, WithWhitespace(ASCIIWhitespace))
 	return result, err
 }
@@ -4,9 +4,8 @@ import (
 	stdlibJson "encoding/json"
 	"testing"
 
-	"os"
-
 	"github.com/ijt/goparsify"
+	"github.com/ijt/goparsify/parsifytest"
 	parsecJson "github.com/prataprc/goparsec/json"
 	"github.com/stretchr/testify/require"
 )
@@ -55,7 +54,6 @@ func BenchmarkUnmarshalParsec(b *testing.B) {
 }
 
 func BenchmarkUnmarshalParsify(b *testing.B) {
-	goparsify.EnableLogging(os.Stdout)
 	for i := 0; i < b.N; i++ {
 		_, err := Unmarshal(benchmarkString)
 		require.NoError(b, err)
@@ -161,3 +159,11 @@ const benchmarkString = `{"web-app": {
   "taglib": {
     "taglib-uri": "cofax.tld",
     "taglib-location": "/WEB-INF/tlds/cofax.tld"}}}`
+
+func TestAnalyze(t *testing.T) {
+	require.Empty(t, goparsify.Analyze(_value))
+}
+
+func TestGolden(t *testing.T) {
+	parsifytest.AssertGoldenCorpus(t, _value, "testdata/*.json", goparsify.WithWhitespace(goparsify.ASCIIWhitespace))
+}
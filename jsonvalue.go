@@ -0,0 +1,307 @@
+package goparsify
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSONValue matches a single RFC 8259 JSON value and binds it into .Result using the same
+// types as encoding/json, except that integers are bound as int64 like NumberLit does:
+//   - objects become map[string]interface{}
+//   - arrays become []interface{}
+//   - strings become string, with all escapes including surrogate pairs decoded
+//   - numbers become int64 or float64
+//   - true, false and null become true, false and nil
+//
+// Whitespace inside the value follows JSON rules rather than the State's WS parser, so it
+// can be embedded in grammars with their own notion of whitespace or comments. .Token holds
+// the raw JSON text.
+func JSONValue() Parser {
+	return NewParser("json value", func(ps *State, node *Result) {
+		ps.WS(ps)
+		start := ps.Pos
+		p := jsonParser{ps: ps, pos: ps.Pos}
+		v, ok := p.value()
+		if !ok || ps.tokenTooLong(start, p.pos-start) {
+			ps.Pos = start
+			return
+		}
+		node.Token = ps.Input[start:p.pos]
+		node.Start, node.End = start, p.pos
+		node.Result = v
+		ps.Pos = p.pos
+	})
+}
+
+// jsonParser is a small recursive descent parser. JSON is fixed and heavily used, so it's
+// written by hand for speed rather than composed from combinators like the json example package.
+type jsonParser struct {
+	ps  *State
+	pos int
+}
+
+func (p *jsonParser) fail(expected string) (interface{}, bool) {
+	p.ps.Error = Error{pos: p.pos, expected: expected}
+	return nil, false
+}
+
+func (p *jsonParser) skipWS() {
+	for p.pos < len(p.ps.Input) {
+		switch p.ps.Input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jsonParser) value() (interface{}, bool) {
+	input := p.ps.Input
+	if p.pos >= len(input) {
+		return p.fail("json value")
+	}
+	switch c := input[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		return p.string()
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	case strings.HasPrefix(input[p.pos:], "true"):
+		p.pos += 4
+		return true, true
+	case strings.HasPrefix(input[p.pos:], "false"):
+		p.pos += 5
+		return false, true
+	case strings.HasPrefix(input[p.pos:], "null"):
+		p.pos += 4
+		return nil, true
+	}
+	return p.fail("json value")
+}
+
+func (p *jsonParser) object() (interface{}, bool) {
+	p.pos++ // {
+	obj := map[string]interface{}{}
+	p.skipWS()
+	if p.pos < len(p.ps.Input) && p.ps.Input[p.pos] == '}' {
+		p.pos++
+		return obj, true
+	}
+	for {
+		p.skipWS()
+		if p.pos >= len(p.ps.Input) || p.ps.Input[p.pos] != '"' {
+			return p.fail(`"`)
+		}
+		key, ok := p.string()
+		if !ok {
+			return nil, false
+		}
+		p.skipWS()
+		if p.pos >= len(p.ps.Input) || p.ps.Input[p.pos] != ':' {
+			return p.fail(":")
+		}
+		p.pos++
+		p.skipWS()
+		v, ok := p.value()
+		if !ok {
+			return nil, false
+		}
+		obj[key.(string)] = v
+		p.skipWS()
+		if p.pos < len(p.ps.Input) {
+			switch p.ps.Input[p.pos] {
+			case ',':
+				p.pos++
+				continue
+			case '}':
+				p.pos++
+				return obj, true
+			}
+		}
+		return p.fail(", or }")
+	}
+}
+
+func (p *jsonParser) array() (interface{}, bool) {
+	p.pos++ // [
+	arr := []interface{}{}
+	p.skipWS()
+	if p.pos < len(p.ps.Input) && p.ps.Input[p.pos] == ']' {
+		p.pos++
+		return arr, true
+	}
+	for {
+		p.skipWS()
+		v, ok := p.value()
+		if !ok {
+			return nil, false
+		}
+		arr = append(arr, v)
+		p.skipWS()
+		if p.pos < len(p.ps.Input) {
+			switch p.ps.Input[p.pos] {
+			case ',':
+				p.pos++
+				continue
+			case ']':
+				p.pos++
+				return arr, true
+			}
+		}
+		return p.fail(", or ]")
+	}
+}
+
+func (p *jsonParser) string() (interface{}, bool) {
+	input := p.ps.Input
+	p.pos++ // opening quote
+	start := p.pos
+	var buf []byte
+
+	for p.pos < len(input) {
+		c := input[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			if buf == nil {
+				return input[start : p.pos-1], true
+			}
+			return string(buf), true
+		case c < 0x20:
+			return p.fail(`"`)
+		case c == '\\':
+			if buf == nil {
+				buf = append(make([]byte, 0, p.pos-start+16), input[start:p.pos]...)
+			}
+			if p.pos+1 >= len(input) {
+				p.pos++
+				return p.fail("escape sequence")
+			}
+			p.pos++
+			switch input[p.pos] {
+			case '"', '\\', '/':
+				buf = append(buf, input[p.pos])
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r, ok := p.hex4()
+				if !ok {
+					return nil, false
+				}
+				if utf16.IsSurrogate(r) {
+					// A high surrogate should be followed by an escaped low surrogate.
+					r2 := utf8.RuneError
+					if strings.HasPrefix(input[p.pos+1:], `\u`) {
+						p.pos += 2
+						if r2, ok = p.hex4(); !ok {
+							return nil, false
+						}
+					}
+					r = utf16.DecodeRune(r, r2)
+				}
+				buf = utf8.AppendRune(buf, r)
+			default:
+				return p.fail("escape sequence")
+			}
+			p.pos++
+		default:
+			if buf != nil {
+				buf = append(buf, c)
+			}
+			p.pos++
+		}
+	}
+	return p.fail(`"`)
+}
+
+// hex4 decodes the four hex digits following the u of a \u escape, leaving pos on the last one.
+func (p *jsonParser) hex4() (rune, bool) {
+	if p.pos+5 > len(p.ps.Input) {
+		p.pos++
+		p.fail("[a-f0-9]{4}")
+		return 0, false
+	}
+	r, ok := unhex(p.ps.Input[p.pos+1 : p.pos+5])
+	if !ok {
+		p.pos++
+		p.fail("[a-f0-9]{4}")
+		return 0, false
+	}
+	p.pos += 4
+	return r, true
+}
+
+func (p *jsonParser) number() (interface{}, bool) {
+	input := p.ps.Input
+	start := p.pos
+	float := false
+
+	if input[p.pos] == '-' {
+		p.pos++
+	}
+	switch {
+	case p.pos < len(input) && input[p.pos] == '0':
+		p.pos++
+	case p.pos < len(input) && input[p.pos] >= '1' && input[p.pos] <= '9':
+		p.pos = skipDigits(input, p.pos)
+	default:
+		return p.fail("digit")
+	}
+
+	if p.pos < len(input) && input[p.pos] == '.' {
+		float = true
+		p.pos++
+		if digitsEnd := skipDigits(input, p.pos); digitsEnd > p.pos {
+			p.pos = digitsEnd
+		} else {
+			return p.fail("digit")
+		}
+	}
+
+	if p.pos < len(input) && (input[p.pos] == 'e' || input[p.pos] == 'E') {
+		float = true
+		p.pos++
+		if p.pos < len(input) && (input[p.pos] == '-' || input[p.pos] == '+') {
+			p.pos++
+		}
+		if digitsEnd := skipDigits(input, p.pos); digitsEnd > p.pos {
+			p.pos = digitsEnd
+		} else {
+			return p.fail("digit")
+		}
+	}
+
+	if !float {
+		if i, err := strconv.ParseInt(input[start:p.pos], 10, 64); err == nil {
+			return i, true
+		}
+		// Too big for an int64, fall back to a float like encoding/json does.
+	}
+	f, err := strconv.ParseFloat(input[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return p.fail("number")
+	}
+	return f, true
+}
+
+func skipDigits(input string, pos int) int {
+	for pos < len(input) && input[pos] >= '0' && input[pos] <= '9' {
+		pos++
+	}
+	return pos
+}
@@ -0,0 +1,73 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONValue(t *testing.T) {
+	parser := JSONValue()
+
+	tests := map[string]interface{}{
+		`true`:                 true,
+		`false`:                false,
+		`null`:                 nil,
+		`-12`:                  int64(-12),
+		`0.5e+2`:               50.0,
+		`12345678901234567890`: 12345678901234567890.0,
+		`"a\"b\\c\/\n\t"`:      "a\"b\\c/\n\t",
+		`"é😀"`:                 "é😀",
+		`[]`:                   []interface{}{},
+		`{}`:                   map[string]interface{}{},
+		`[1, "two", [3]]`:      []interface{}{int64(1), "two", []interface{}{int64(3)}},
+		"{ \"a\" :\n{\"b\": [true, null]} }": map[string]interface{}{
+			"a": map[string]interface{}{"b": []interface{}{true, nil}},
+		},
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored(), p.Error.Error())
+			require.Equal(t, expected, result.Result)
+			require.Equal(t, input, result.Token)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	errors := map[string]string{
+		``:           "offset 0: expected json value",
+		`[1,]`:       "offset 3: expected json value",
+		`[1 2]`:      "offset 3: expected , or ]",
+		`{"a" 1}`:    "offset 5: expected :",
+		`{a: 1}`:     `offset 1: expected "`,
+		`"abc`:       `offset 4: expected "`,
+		`"\x"`:       "offset 2: expected escape sequence",
+		`"\u12"`:     "offset 3: expected [a-f0-9]{4}",
+		`01`:         "",
+		`-`:          "offset 1: expected digit",
+		`1.`:         "offset 2: expected digit",
+		`{"a": tru}`: "offset 6: expected json value",
+	}
+	for input, expected := range errors {
+		t.Run("error "+input, func(t *testing.T) {
+			_, p := runParser(input, parser)
+			if expected == "" {
+				// Only a prefix is valid, the rest is left for the caller to deal with.
+				require.NotEqual(t, "", p.Get())
+				return
+			}
+			require.Equal(t, expected, p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+
+	t.Run("embedded in a grammar", func(t *testing.T) {
+		logLine := Seq(Regex(`\d{4}-\d\d-\d\d`), Regex(`[A-Z]+`), parser).Map(func(n *Result) {
+			n.Result = n.Child[2].Result
+		})
+		result, _, err := Run(logLine, `2024-01-02 INFO {"user": "bob", "ms": 12}`)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"user": "bob", "ms": int64(12)}, result)
+	})
+}
@@ -0,0 +1,85 @@
+package goparsify
+
+// LeftRecursive lets parser refer to itself at the start of its own input, so left recursive
+// rules can be written the natural way:
+//
+//	var expr Parser
+//	expr = LeftRecursive(Any(Seq(&expr, "-", NumberLit()), NumberLit()))
+//
+// Without it a parser like this calls itself forever. References back to the parser must go
+// through the returned parser, eg via a pointer like &expr above.
+//
+// It works by growing a seed, as described by Warth et al in "Packrat Parsers Can Support Left
+// Recursion". The recursive reference fails at first, so parser matches only its non recursive
+// alternatives. That match is remembered and returned by the reference on the next attempt,
+// which repeats as long as each attempt matches more input than the last. Alternatives that
+// recurse are tried before the others, just as they are written.
+func LeftRecursive(parser Parserish) Parser {
+	p := Parsify(parser)
+	rule := &grammarRule{kind: "LeftRecursive()", leftRecursive: true}
+
+	return NewParser("LeftRecursive()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, p, false)
+			return
+		}
+
+		startpos := ps.Pos
+		key := leftRecursionKey{rule: rule, pos: startpos}
+		if seed, ok := ps.leftRecursion[key]; ok {
+			// A recursive call, answer with the match so far.
+			if !seed.ok {
+				ps.ErrorHere("LeftRecursive()")
+				return
+			}
+			*node = seed.result
+			ps.Restore(seed.end)
+			return
+		}
+
+		if ps.leftRecursion == nil {
+			ps.leftRecursion = map[leftRecursionKey]*leftRecursionSeed{}
+		}
+		seed := &leftRecursionSeed{}
+		ps.leftRecursion[key] = seed
+		defer delete(ps.leftRecursion, key)
+
+		// Each attempt starts over with the user state and captures from before the first.
+		start, cut := ps.Mark(), ps.Cut
+		for {
+			var result Result
+			ps.Restore(start)
+			ps.Cut = cut
+			p(ps, &result)
+			if ps.Errored() {
+				if !seed.ok || ps.cutSince(startpos) {
+					ps.Restore(start)
+					return
+				}
+				ps.Recover()
+				break
+			}
+			if seed.ok && ps.Pos <= seed.end.pos {
+				break
+			}
+			seed.result, seed.end, seed.cut, seed.ok = result, ps.Mark(), ps.Cut, true
+		}
+		*node = seed.result
+		ps.Restore(seed.end)
+		ps.Cut = seed.cut
+	})
+}
+
+type leftRecursionKey struct {
+	rule *grammarRule
+	pos  int
+}
+
+// leftRecursionSeed is the longest match so far of a LeftRecursive parser at a position.
+type leftRecursionSeed struct {
+	result Result
+	// end is where the match ends, with the user state and captures it left.
+	end Mark
+	cut int
+	ok  bool
+}
@@ -0,0 +1,90 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeftRecursive(t *testing.T) {
+	var expr Parser
+	expr = LeftRecursive(Any(
+		Seq(&expr, "-", NumberLit()).Map(func(n *Result) {
+			n.Result = n.Child[0].Result.(int64) - n.Child[2].Result.(int64)
+		}),
+		NumberLit(),
+	))
+
+	t.Run("grows to the longest match", func(t *testing.T) {
+		result, _, err := Run(expr, "10 - 2 - 3")
+		require.NoError(t, err)
+		require.Equal(t, int64(5), result)
+	})
+
+	t.Run("matches the base case alone", func(t *testing.T) {
+		result, _, err := Run(expr, "10")
+		require.NoError(t, err)
+		require.Equal(t, int64(10), result)
+	})
+
+	t.Run("fails without a base case", func(t *testing.T) {
+		_, p := runParser("x", expr)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("stops before trailing input", func(t *testing.T) {
+		result, p := runParser("3 - 1 -", expr)
+		require.False(t, p.Errored())
+		require.Equal(t, int64(2), result.Result)
+		require.Equal(t, " -", p.Get())
+	})
+
+	t.Run("keeps the user state and captures of the longest match only", func(t *testing.T) {
+		count := UpdateState(NumberLit(), func(state interface{}, n *Result) interface{} {
+			if state == nil {
+				return 1
+			}
+			return state.(int) + 1
+		})
+		var counted Parser
+		counted = LeftRecursive(Any(Seq(&counted, "-", count), count))
+		_, p := runParser("10 - 2 - 3", counted)
+		require.False(t, p.Errored())
+		require.Equal(t, 3, p.GetUserState())
+
+		var captured Parser
+		captured = LeftRecursive(Any(Seq(&captured, "-", Capture("last", NumberLit())), Capture("last", NumberLit())))
+		_, _, err := Run(Seq(captured, "=", MatchCaptured("last")), "1 - 2=2")
+		require.NoError(t, err)
+	})
+
+	t.Run("is not reported by Analyze", func(t *testing.T) {
+		require.Empty(t, Analyze(expr))
+	})
+}
+
+func TestLeftRecursiveCut(t *testing.T) {
+	var expr Parser
+	expr = LeftRecursive(Any(Seq(&expr, "+", Cut(), NumberLit()), NumberLit()))
+
+	_, p := runParser("1 + 2 +", expr)
+	require.True(t, p.Errored())
+	require.Equal(t, "offset 7: expected number", p.Error.Error())
+
+	_, p = runParser("1 + 2", expr)
+	require.False(t, p.Errored())
+	require.Equal(t, "", p.Get())
+}
+
+func TestLeftRecursiveNamed(t *testing.T) {
+	var list Parser
+	list = Named("list", LeftRecursive(Any(Seq(&list, ",", Chars("a-z")), Chars("a-z"))))
+
+	result, p := runParser("a,b,c", list)
+	require.False(t, p.Errored())
+	require.Equal(t, "list", result.Name)
+	require.Equal(t, "", p.Get())
+	require.Equal(t, "c", result.Child[2].Token)
+	require.Equal(t, "b", result.Child[0].Child[2].Token)
+}
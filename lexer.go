@@ -0,0 +1,269 @@
+package goparsify
+
+import (
+	"sort"
+	"strings"
+)
+
+// Lexer is what a grammar's tokens look like, worked out by ExtractLexer: the literals it
+// matches, the bytes any of its tokens can start with and which alternatives of each Any can.
+type Lexer struct {
+	literals []string
+	// byFirst holds the literals starting with each byte, the longest first.
+	byFirst [256][]string
+	// starts holds the bytes a token can start with, and anyStart is set when that couldn't be
+	// worked out, eg for parsers written by hand.
+	starts   [256]bool
+	anyStart bool
+	// choices holds the branches of each Any that can start with each byte, for the Anys where
+	// that leaves some of them out.
+	choices map[*grammarRule]*[256][]int
+}
+
+// ExtractLexer walks the grammar of p without parsing anything, the way ExportEBNF does, and
+// collects the literals it matches with Exact, the bytes its tokens can start with and those
+// each alternative of its Anys can. Pass it to Run with WithLexer to skip whitespace once per
+// position and go straight to the alternatives that can match, or use Next to scan a literal
+// token in a parser written by hand.
+func ExtractLexer(p Parserish) *Lexer {
+	w := &lexerWalker{
+		done:     map[*grammarRule]bool{},
+		literals: map[string]bool{},
+		lexer:    &Lexer{choices: map[*grammarRule]*[256][]int{}},
+		firsts:   &firstSetWalker{done: map[*grammarRule]firstSet{}},
+	}
+	w.firstsState = NewState("")
+	w.firstsState.analysis = w.firsts
+	ps := NewState("")
+	ps.analysis = w
+	w.child(ps, Parsify(p))
+
+	l := w.lexer
+	for literal := range w.literals {
+		l.literals = append(l.literals, literal)
+	}
+	sort.Strings(l.literals)
+	for _, literal := range l.literals {
+		l.byFirst[literal[0]] = append(l.byFirst[literal[0]], literal)
+	}
+	for b := range l.byFirst {
+		sort.SliceStable(l.byFirst[b], func(i, j int) bool { return len(l.byFirst[b][i]) > len(l.byFirst[b][j]) })
+	}
+	return l
+}
+
+// Literals returns the literals of the grammar, sorted.
+func (l *Lexer) Literals() []string {
+	return append([]string(nil), l.literals...)
+}
+
+// CanStart tells whether a token of the grammar can start with b. It is true of every byte
+// when the grammar has terminals that couldn't be looked into.
+func (l *Lexer) CanStart(b byte) bool {
+	return l.anyStart || l.starts[b]
+}
+
+// Next returns the longest literal of the grammar that input starts with, or "" if none does,
+// going straight to the literals starting with its first byte.
+func (l *Lexer) Next(input string) string {
+	if input == "" {
+		return ""
+	}
+	for _, literal := range l.byFirst[input[0]] {
+		if strings.HasPrefix(input, literal) {
+			return literal
+		}
+	}
+	return ""
+}
+
+// WithLexer makes the parse skip the whitespace at each position once, however many parsers
+// try to match there, and makes each Any of the grammar l was extracted from only try the
+// alternatives that can start with the next byte, the way AdaptiveAny does, as in grammars where
+// each alternative starts with a token of its own. When none of those match it tries all of
+// them, so it fails with the same error. It also lets parsers written by hand ask for the
+// literal there with State.NextLiteral. The whitespace skipper has to skip the same every time
+// it's run at a position, which all of those in this package do. Parsers that set one of their
+// own, like Embed or NoAutoWS, run it as usual.
+func WithLexer(l *Lexer) Option {
+	return func(cfg *runConfig) {
+		cfg.lexer = l
+	}
+}
+
+// lexerScan remembers where the whitespace skipped at the last position it was run at ends,
+// going straight there when run at the same position again, and the last literal scanned.
+type lexerScan struct {
+	lexer *Lexer
+	ws    VoidParser
+	input string
+	from  int
+	to    int
+	// literal is the literal at literalAt in literalInput, which is -1 until one is scanned.
+	literal      string
+	literalInput string
+	literalAt    int
+}
+
+func newLexerScan(l *Lexer, ws VoidParser) *lexerScan {
+	return &lexerScan{lexer: l, ws: ws, from: -1, literalAt: -1}
+}
+
+func (c *lexerScan) skip(s *State) {
+	if s.Pos == c.from && s.Input == c.input {
+		s.Pos = c.to
+		return
+	}
+	c.from, c.input = s.Pos, s.Input
+	c.ws(s)
+	c.to = s.Pos
+}
+
+// dispatch tries the parsers of the Any rule that can start with the next byte, returning
+// whether that settles the Any, as one of them matched or failed past a Cut. Otherwise the
+// state is left as it was, for the Any to try every parser. Any has skipped the whitespace and
+// made sure the input doesn't end here.
+func (c *lexerScan) dispatch(ps *State, node *Result, rule *grammarRule, parsers []Parser) bool {
+	table := c.lexer.choices[rule]
+	if table == nil || ps.tokens != nil {
+		return false
+	}
+	startpos, before := ps.Pos, ps.Error
+	last := len(parsers) - 1
+	for _, i := range table[ps.Input[startpos]] {
+		var window *branchWindow
+		if i < last {
+			window = ps.limitBranch()
+		}
+		parsers[i](ps, node)
+		ps.endBranch(window)
+		if !ps.Errored() || ps.cutSince(startpos) {
+			return true
+		}
+		ps.Recover()
+	}
+	ps.Pos, ps.Error = startpos, before
+	return false
+}
+
+// NextLiteral returns the longest literal of the grammar given to WithLexer that the next token
+// after any whitespace starts with, without consuming anything. It is "" if there is none or
+// the parse wasn't given a Lexer.
+func (s *State) NextLiteral() string {
+	if s.lexer == nil {
+		return ""
+	}
+	pos := s.Pos
+	s.WS(s)
+	c := s.lexer
+	if s.Pos != c.literalAt || s.Input != c.literalInput {
+		c.literal, c.literalInput, c.literalAt = c.lexer.Next(s.Get()), s.Input, s.Pos
+	}
+	s.Pos = pos
+	return c.literal
+}
+
+// lexerWalker collects what ExtractLexer finds as the grammar is walked.
+type lexerWalker struct {
+	done     map[*grammarRule]bool
+	literals map[string]bool
+	lexer    *Lexer
+	// reports counts the terminals reported, to tell parsers that can't be looked into.
+	reports int
+	// firsts works out the bytes the alternatives of each Any can start with.
+	firsts      *firstSetWalker
+	firstsState *State
+}
+
+// child runs p, taking it for a terminal that can start with anything if it reports nothing.
+func (w *lexerWalker) child(ps *State, p Parser) {
+	if p == nil {
+		return
+	}
+	n := w.reports
+	p(ps, &Result{})
+	ps.Recover()
+	if w.reports == n {
+		w.lexer.anyStart = true
+	}
+}
+
+// visit walks the parsers of rule the first time it is seen, which also stops at recursion.
+func (w *lexerWalker) visit(ps *State, rule *grammarRule, parsers ...Parser) {
+	w.reports++
+	if w.done[rule] {
+		return
+	}
+	w.done[rule] = true
+	for _, p := range parsers {
+		w.child(ps, p)
+	}
+}
+
+func (w *lexerWalker) seq(ps *State, rule *grammarRule, parsers []Parser) {
+	w.visit(ps, rule, parsers...)
+}
+
+func (w *lexerWalker) any(ps *State, rule *grammarRule, parsers []Parser) {
+	if !w.done[rule] && !rule.longest {
+		w.choices(rule, parsers)
+	}
+	w.visit(ps, rule, parsers...)
+}
+
+// choices records the parsers of rule that can start with each byte, if that leaves some out
+// for any of them.
+func (w *lexerWalker) choices(rule *grammarRule, parsers []Parser) {
+	firsts := make([]firstSet, len(parsers))
+	for i, p := range parsers {
+		firsts[i] = w.firsts.first(w.firstsState, p)
+	}
+	table := &[256][]int{}
+	filters := false
+	for b := range table {
+		for i, f := range firsts {
+			if f.any || f.nullable || f.bytes[b] {
+				table[b] = append(table[b], i)
+			}
+		}
+		filters = filters || len(table[b]) < len(parsers)
+	}
+	if filters {
+		w.lexer.choices[rule] = table
+	}
+}
+
+func (w *lexerWalker) many(ps *State, rule *grammarRule, min int, op, sep Parser) {
+	w.visit(ps, rule, op, sep)
+}
+
+func (w *lexerWalker) signalSeq(ps *State, rule *grammarRule, noise Parser, signals []Parser) {
+	w.visit(ps, rule, append([]Parser{noise}, signals...)...)
+}
+
+func (w *lexerWalker) wrap(ps *State, rule *grammarRule, p Parser, nullable bool) {
+	w.visit(ps, rule, p)
+}
+
+func (w *lexerWalker) exact(match string) {
+	w.reports++
+	if match != "" {
+		w.literals[match] = true
+		w.lexer.starts[match[0]] = true
+	}
+}
+
+func (w *lexerWalker) chars(description string, first func(b byte) bool, nullable bool) {
+	w.reports++
+	for b := range w.lexer.starts {
+		w.lexer.starts[b] = w.lexer.starts[b] || first(byte(b))
+	}
+}
+
+func (w *lexerWalker) terminal(description string) {
+	w.reports++
+	if description != "" {
+		// Markers like Cut match nothing at all.
+		w.lexer.anyStart = true
+	}
+}
@@ -0,0 +1,113 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractLexer(t *testing.T) {
+	var value Parser
+	list := Seq("[", Many(&value, ","), "]")
+	value = Any(Chars("0-9"), Seq("==", &value), Seq("=", &value), list)
+
+	lexer := ExtractLexer(&value)
+	require.Equal(t, []string{",", "=", "==", "[", "]"}, lexer.Literals())
+	require.True(t, lexer.CanStart('7'))
+	require.True(t, lexer.CanStart('['))
+	require.False(t, lexer.CanStart('x'))
+
+	require.Equal(t, "==", lexer.Next("== 1"))
+	require.Equal(t, "=", lexer.Next("=1"))
+	require.Equal(t, "", lexer.Next("1"))
+	require.Equal(t, "", lexer.Next(""))
+
+	t.Run("can start with anything given terminals it can't look into", func(t *testing.T) {
+		custom := func(ps *State, node *Result) {}
+		require.True(t, ExtractLexer(Seq("a", custom)).CanStart('x'))
+		require.True(t, ExtractLexer(Regex("[a-z]+")).CanStart('x'))
+		require.False(t, ExtractLexer(Seq("a", Cut(), "b")).CanStart('x'))
+	})
+}
+
+func TestWithLexer(t *testing.T) {
+	skipped := 0
+	ws := func(s *State) {
+		skipped++
+		ASCIIWhitespace(s)
+	}
+	parser := Some(Any("if", "else", "end", Chars("a-z")))
+	input := "  if x else y end"
+
+	want, err := RunTree(parser, input, WithWhitespace(ws))
+	require.NoError(t, err)
+	without := skipped
+
+	skipped = 0
+	got, err := RunTree(parser, input, WithWhitespace(ws), WithLexer(ExtractLexer(parser)))
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Less(t, skipped, without)
+
+	t.Run("only tries the alternatives that can start with the next byte", func(t *testing.T) {
+		lexer := ExtractLexer(Any("if", Seq("x", Cut(), "y"), Chars("0-9"), Maybe("z")))
+		require.Len(t, lexer.choices, 1)
+		for _, table := range lexer.choices {
+			require.Equal(t, []int{0, 3}, table['i'])
+			require.Equal(t, []int{1, 3}, table['x'])
+			require.Equal(t, []int{2, 3}, table['7'])
+			require.Equal(t, []int{3}, table['z'])
+		}
+		require.Empty(t, ExtractLexer(Any(Regex("[a-z]+"), Maybe("if"))).choices)
+		require.Empty(t, ExtractLexer(Longest("if", "x")).choices)
+	})
+
+	t.Run("matches and fails like Any", func(t *testing.T) {
+		parser := Some(Any(Seq("in", "x"), "in", Seq("var", Cut(), "x"), "var y", Chars("a-z"), Chars("0-9")))
+		lexer := ExtractLexer(parser)
+		for _, input := range []string{"in x", "in", "var x in 12", "var y", "ab 7", "in ?", "?"} {
+			want, wantErr := RunTree(parser, input)
+			got, err := RunTree(parser, input, WithLexer(lexer))
+			require.Equal(t, wantErr, err, input)
+			require.Len(t, got.Child, len(want.Child), input)
+			for i, child := range want.Child {
+				require.Equal(t, child.Token, got.Child[i].Token, input)
+				require.Equal(t, child.End, got.Child[i].End, input)
+			}
+		}
+	})
+
+	t.Run("tries wrappers that can match without their child whatever the next byte", func(t *testing.T) {
+		parser := Seq(Any(Recovering(Seq("let", Chars("a-z")), ";"), Chars("a-z")), ";")
+		want, err := RunTree(parser, "foo;")
+		require.NoError(t, err)
+		require.Len(t, RecoveredErrors(&want), 1)
+
+		got, err := RunTree(parser, "foo;", WithLexer(ExtractLexer(parser)))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	})
+
+	t.Run("scans the next literal", func(t *testing.T) {
+		keyword := func(ps *State, node *Result) {
+			literal := ps.NextLiteral()
+			if literal == "" {
+				ps.ErrorHere("keyword")
+				return
+			}
+			ps.WS(ps)
+			node.Token, node.Start, node.End = literal, ps.Pos, ps.Pos+len(literal)
+			ps.Advance(len(literal))
+		}
+		lexer := ExtractLexer(Any("if", "else", "elseif"))
+
+		tree, err := RunTree(Many(keyword), " elseif if", WithLexer(lexer))
+		require.NoError(t, err)
+		assertSequence(t, tree, "elseif", "if")
+
+		_, err = RunTree(Many(keyword), "if x", WithLexer(lexer))
+		require.EqualError(t, err, "left unparsed: x")
+
+		require.Equal(t, "", NewState("if").NextLiteral())
+	})
+}
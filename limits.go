@@ -0,0 +1,59 @@
+package goparsify
+
+import "fmt"
+
+// WithMaxInputSize fails the parse before it starts if the input is longer than n bytes, for
+// services parsing input from users. Zero, the default, means no limit.
+func WithMaxInputSize(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.maxInput = n
+	}
+}
+
+// WithMaxTokenLength fails the parse as soon as a token longer than n bytes is read, so input
+// from users can't make a terminal, like Chars, Until, Regex, StringLit, Int or JSONValue,
+// match and copy huge amounts of it. Every terminal whose match is as long as the input makes
+// it is limited, which leaves out only Exact, Insensitive and FixedBytes. Zero, the default,
+// means no limit.
+func WithMaxTokenLength(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.maxToken = n
+	}
+}
+
+// WithMaxChildren fails the parse as soon as a Many or Some matches more than n items, so
+// input from users can't grow a list without bound. Zero, the default, means no limit.
+func WithMaxChildren(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.maxChildren = n
+	}
+}
+
+// checkInputSize returns the error for input too long for the limit set by WithMaxInputSize.
+func checkInputSize(input string, cfg *runConfig) error {
+	if cfg.maxInput > 0 && len(input) > cfg.maxInput {
+		return &Error{pos: cfg.maxInput, expected: fmt.Sprintf("input of at most %d bytes", cfg.maxInput)}
+	}
+	return nil
+}
+
+// tokenTooLong returns whether n bytes are more than a token can have, failing the parse at
+// start, where the token starts, if so. Like WithMaxDepth the error is fatal, so no parser
+// backtracks out of it to take the input some other way.
+func (s *State) tokenTooLong(start, n int) bool {
+	if s.cfg().maxToken == 0 || n <= s.cfg().maxToken {
+		return false
+	}
+	s.fatalError(start, fmt.Sprintf("token of at most %d bytes", s.cfg().maxToken))
+	return true
+}
+
+// tooManyChildren returns whether a list of n items is longer than allowed, failing the parse
+// at pos, where the item past the limit starts, if so.
+func (s *State) tooManyChildren(n, pos int) bool {
+	if s.cfg().maxChildren == 0 || n <= s.cfg().maxChildren {
+		return false
+	}
+	s.fatalError(pos, fmt.Sprintf("at most %d items", s.cfg().maxChildren))
+	return true
+}
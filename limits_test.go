@@ -0,0 +1,125 @@
+package goparsify
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxInputSize(t *testing.T) {
+	_, _, err := Run(Chars("a-z"), "abcd", WithMaxInputSize(4))
+	require.NoError(t, err)
+
+	_, _, err = Run(Chars("a-z"), "abcde", WithMaxInputSize(4))
+	require.EqualError(t, err, "offset 4: expected input of at most 4 bytes")
+
+	var items int
+	ManyIter(Chars("a-z"), ",", "a,b,c", WithMaxInputSize(4))(func(r Result, err error) bool {
+		require.EqualError(t, err, "offset 4: expected input of at most 4 bytes")
+		items++
+		return true
+	})
+	require.Equal(t, 1, items)
+}
+
+func TestWithMaxTokenLength(t *testing.T) {
+	limit := WithMaxTokenLength(4)
+
+	// Every terminal whose match is as long as the input makes it, with a match one byte over
+	// the limit and one that fits it. The limit is 4 bytes unless max says otherwise.
+	tests := map[string]struct {
+		parser      Parserish
+		long, short string
+		max         int
+	}{
+		"chars":        {Chars("a-z"), "abcde", "abcd", 0},
+		"not chars":    {NotChars(";"), "abcde;", "abcd;", 0},
+		"until":        {Until(";"), "abcde;", "abcd;", 0},
+		"regex":        {Regex("[a-z]+"), "abcde", "abcd", 0},
+		"regex group":  {RegexGroups(`(?P<w>[a-z]+)`), "abcde", "abcd", 0},
+		"matcher":      {Matcher("word", func(s string) (int, bool) { return len(s), true }), "abcde", "abcd", 0},
+		"string lit":   {StringLit(`"`), `"abcd"`, `"ab"`, 0},
+		"number lit":   {NumberLit(), "12345", "1234", 0},
+		"int":          {Int(IntOptions{}), "12345", "1234", 0},
+		"float":        {Float(FloatOptions{}), "1.234", "1.23", 0},
+		"ident":        {Ident(IdentOptions{}), "abcde", "abcd", 0},
+		"graphemes":    {GraphemeChars("a-z"), "abcde", "abcd", 0},
+		"any grapheme": {AnyGrapheme(), "e\u0301\u0301", "e\u0301", 0},
+		"fuzzy":        {Fuzzy("abcde", 1), "abcde", "abce", 0},
+		"hex bytes":    {HexBytes(), "abcdef", "abcd", 0},
+		"base64":       {Base64(), "YWJjZA", "YWI=", 0},
+		"csv field":    {CSVField(',', '"'), "abcde", "abcd", 0},
+		"quoted field": {CSVField(',', '"'), `"abc"`, `"ab"`, 0},
+		"csv record":   {CSVRecord(',', '"'), "abcde,a", "abcd,a", 0},
+		"json value":   {JSONValue(), "[1,2]", "[12]", 0},
+		"duration":     {Duration(), "1h30m", "10ms", 0},
+		"date time":    {DateTime(), "2024-03-01T12:30:00.5Z", "2024-03-01T12:30:00Z", 20},
+		"email":        {Email(), "ab@cd", "a@bc", 0},
+		"url":          {URL(), "http://a.b/c", "http://a.b", 10},
+		"uuid":         {UUID(), "{123e4567-e89b-12d3-a456-426614174000}", "123e4567-e89b-12d3-a456-426614174000", 36},
+		"money":        {Money(), "12.50 EUR", "1.50 EUR", 8},
+		"ip":           {IP(), "1.2.3.45", "1.2.3.4", 7},
+		"cidr":         {CIDR(), "1.2.3.4/24", "1.2.3.4/8", 9},
+		"mac":          {MAC(), "00:1a:2b:3c:4d:5e:6f:70", "00:1a:2b:3c:4d:5e", 17},
+		"port":         {Port(), "443", "80", 2},
+		"host port":    {HostPort(), "ab.c:80", "a.b:80", 6},
+		"semver":       {Semver(), "1.2.34", "1.2.3", 5},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			max := test.max
+			if max == 0 {
+				max = 4
+			}
+			limit := WithMaxTokenLength(max)
+			_, _, err := Run(Seq("=", test.parser), "="+test.long, limit, AllowTrailingInput())
+			require.EqualError(t, err, fmt.Sprintf("offset 1: expected token of at most %d bytes", max))
+
+			_, _, err = Run(test.parser, test.short, limit, AllowTrailingInput())
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("match captured", func(t *testing.T) {
+		tag := Seq(Capture("tag", Seq(Chars("a-c"), Chars("d-z"))), ",", MatchCaptured("tag"))
+		_, _, err := Run(tag, "abcd,abcd", limit)
+		require.NoError(t, err)
+
+		_, _, err = Run(tag, "abcdef,abcdef", limit)
+		require.EqualError(t, err, "offset 7: expected token of at most 4 bytes")
+	})
+
+	t.Run("cuts", func(t *testing.T) {
+		_, _, err := Run(Any(Chars("a-z"), Seq(Chars("a-c"), Chars("d-z"))), "abcdef", limit)
+		require.EqualError(t, err, "offset 0: expected token of at most 4 bytes")
+	})
+
+	t.Run("heredoc", func(t *testing.T) {
+		heredoc := Heredoc(Seq("<<", Chars("A-Z")).Map(func(n *Result) { n.Token = n.Child[1].Token }))
+		_, _, err := Run(heredoc, "<<EOF\nab\nEOF", limit)
+		require.NoError(t, err)
+
+		_, _, err = Run(heredoc, "<<EOF\nab\ncd\nEOF", limit)
+		require.EqualError(t, err, "offset 6: expected token of at most 4 bytes")
+	})
+}
+
+func TestWithMaxChildren(t *testing.T) {
+	list := Many(Chars("a-z"), ",")
+
+	tree, err := RunTree(list, "a,b,c", WithMaxChildren(3))
+	require.NoError(t, err)
+	require.Len(t, tree.Child, 3)
+
+	_, err = RunTree(list, "a,b,c,d", WithMaxChildren(3))
+	require.EqualError(t, err, "offset 6: expected at most 3 items")
+
+	_, err = RunTree(Any(list, Chars("a-z,")), strings.Repeat("a,", 10), WithMaxChildren(3))
+	require.EqualError(t, err, "offset 6: expected at most 3 items")
+	// The error stands after the included source is done with and its cuts are put back.
+	load := func(n *Result) (string, string, error) { return "list", "a,b,c,d", nil }
+	_, err = RunTree(Any(Include("#list", load, list), Chars("#a-z")), "#list", WithMaxChildren(3))
+	require.EqualError(t, err, "list offset 6: expected at most 3 items (included from offset 5)")
+}
@@ -2,6 +2,7 @@ package goparsify
 
 import (
 	"bytes"
+	"math"
 	"strconv"
 	"unicode/utf8"
 )
@@ -12,9 +13,12 @@ import (
 //  - unicode sequences, eg \uBEEF
 func StringLit(allowedQuotes string) Parser {
 	return NewParser("string literal", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("string literal")
+		}
 		ps.WS(ps)
 
-		if !stringContainsByte(allowedQuotes, ps.Input[ps.Pos]) {
+		if ps.Pos >= len(ps.Input) || !stringContainsByte(allowedQuotes, ps.Input[ps.Pos]) {
 			ps.ErrorHere(allowedQuotes)
 			return
 		}
@@ -26,6 +30,9 @@ func StringLit(allowedQuotes string) Parser {
 		var buf *bytes.Buffer
 
 		for end < inputLen {
+			if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+				return
+			}
 			switch ps.Input[end] {
 			case '\\':
 				if end+1 >= inputLen {
@@ -40,15 +47,13 @@ func StringLit(allowedQuotes string) Parser {
 				c := ps.Input[end+1]
 				if c == 'u' {
 					if end+6 >= inputLen {
-						ps.Error.expected = "[a-f0-9]{4}"
-						ps.Error.pos = end + 2
+						ps.errorInToken(ps.Pos, end+2, "[a-f0-9]{4}")
 						return
 					}
 
 					r, ok := unhex(ps.Input[end+2 : end+6])
 					if !ok {
-						ps.Error.expected = "[a-f0-9]"
-						ps.Error.pos = end + 2
+						ps.errorInToken(ps.Pos, end+2, "[a-f0-9]")
 						return
 					}
 					buf.WriteRune(r)
@@ -58,6 +63,7 @@ func StringLit(allowedQuotes string) Parser {
 					end += 2
 				}
 			case quote:
+				node.Start, node.End = ps.Pos, end+1
 				if buf == nil {
 					node.Token = ps.Input[ps.Pos+1 : end]
 					ps.Pos = end + 1
@@ -88,7 +94,17 @@ func StringLit(allowedQuotes string) Parser {
 
 // NumberLit matches a floating point or integer number and returns it as a int64 or float64 in .Result
 func NumberLit() Parser {
+	return NumberLitWith(NumberOptions{})
+}
+
+// NumberLitWith is like NumberLit, but opts say what to do with numbers that don't fit in an
+// int64 or float64, eg binding them as a *big.Int or *big.Float for JSON or failing on those
+// that would be rounded for financial formats.
+func NumberLitWith(opts NumberOptions) Parser {
 	return NewParser("number literal", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("number literal")
+		}
 		ps.WS(ps)
 		end := ps.Pos
 		float := false
@@ -129,16 +145,31 @@ func NumberLit() Parser {
 			return
 		}
 
+		if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+			return
+		}
+		tok := ps.Input[ps.Pos:end]
 		var err error
 		if float {
-			node.Result, err = strconv.ParseFloat(ps.Input[ps.Pos:end], 10)
+			node.Result, err = parseFloat(tok, opts.Overflow, opts.Exact)
 		} else {
-			node.Result, err = strconv.ParseInt(ps.Input[ps.Pos:end], 10, 64)
+			var v int64
+			v, err = strconv.ParseInt(tok, 10, 64)
+			node.Result = v
+			if isRangeErr(err) {
+				saturated := int64(math.MaxInt64)
+				if tok[0] == '-' {
+					saturated = math.MinInt64
+				}
+				node.Result, err = overflowInt(tok, 10, opts.Overflow, saturated, err)
+			}
 		}
 		if err != nil {
+			node.Result = nil
 			ps.ErrorHere("number")
 			return
 		}
+		node.Start, node.End = ps.Pos, end
 		ps.Pos = end
 	})
 }
@@ -1,6 +1,8 @@
 package goparsify
 
 import (
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -32,6 +34,11 @@ func TestStringLit(t *testing.T) {
 		require.Equal(t, `1`, p.Get())
 	})
 
+	t.Run("test end of input", func(t *testing.T) {
+		_, p := runParser(` `, parser)
+		require.Equal(t, `"'`, p.Error.expected)
+	})
+
 	t.Run("test unterminated string", func(t *testing.T) {
 		_, p := runParser(`"hello `, parser)
 		require.Equal(t, `"`, p.Error.expected)
@@ -173,4 +180,47 @@ func TestNumberLit(t *testing.T) {
 		require.Equal(t, "offset 0: expected number", p.Error.Error())
 		require.Equal(t, 0, p.Pos)
 	})
+
+	t.Run("overflow", func(t *testing.T) {
+		_, p := runParser("12345678901234567890", parser)
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+	})
+}
+
+func TestNumberLitWith(t *testing.T) {
+	t.Run("big", func(t *testing.T) {
+		parser := NumberLitWith(NumberOptions{Overflow: OverflowBig})
+		result, p := runParser("-12345678901234567890", parser)
+		require.Equal(t, "", p.Get())
+		want, _ := new(big.Int).SetString("-12345678901234567890", 10)
+		require.Equal(t, want, result.Result)
+
+		result, _ = runParser("0012345678901234567890", parser)
+		want, _ = new(big.Int).SetString("12345678901234567890", 10)
+		require.Equal(t, want, result.Result)
+
+		result, _ = runParser("1.25", parser)
+		require.Equal(t, 1.25, result.Result)
+	})
+
+	t.Run("saturate", func(t *testing.T) {
+		parser := NumberLitWith(NumberOptions{Overflow: OverflowSaturate})
+		result, _ := runParser("-12345678901234567890", parser)
+		require.Equal(t, int64(math.MinInt64), result.Result)
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		parser := NumberLitWith(NumberOptions{Overflow: OverflowRaw, Exact: true})
+		result, _ := runParser("19.99", parser)
+		require.Equal(t, "19.99", result.Result)
+
+		result, _ = runParser("19.5", parser)
+		require.Equal(t, 19.5, result.Result)
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		_, p := runParser("19.99", NumberLitWith(NumberOptions{Exact: true}))
+		require.Equal(t, "offset 0: expected number", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
 }
@@ -0,0 +1,264 @@
+// Package lsp turns parse results into the types of the Language Server Protocol, for language
+// servers built on goparsify: positions in UTF-16 code units, diagnostics from parse errors,
+// document symbols from named rules and folding ranges from nested results.
+//
+// The types marshal to JSON the way the protocol expects, but only cover the fields this
+// package fills in.
+package lsp
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ijt/goparsify"
+)
+
+// Position is a zero-based line and character offset in UTF-16 code units, as the protocol
+// counts them.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document, from Start up to but not including End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Document converts between the byte offsets goparsify uses and Positions.
+type Document struct {
+	text string
+	// lines holds the offset each line starts at.
+	lines []int
+}
+
+// NewDocument indexes the lines of text.
+func NewDocument(text string) *Document {
+	d := &Document{text: text, lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	return d
+}
+
+// Position returns the position of the byte offset, which is clamped to the text.
+func (d *Document) Position(offset int) Position {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+	character := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// Offset returns the byte offset of p. Positions past the end of a line are taken as its end,
+// as the protocol asks.
+func (d *Document) Offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset, end := d.lines[p.Line], len(d.text)
+	if p.Line+1 < len(d.lines) {
+		end = d.lines[p.Line+1] - 1
+	}
+	for character := 0; offset < end && character < p.Character; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		character += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// Range returns the range of the bytes from start to end.
+func (d *Document) Range(start, end int) Range {
+	return Range{Start: d.Position(start), End: d.Position(end)}
+}
+
+// DiagnosticSeverity is how bad a Diagnostic is.
+type DiagnosticSeverity int
+
+// The severities of the protocol.
+const (
+	SeverityError DiagnosticSeverity = iota + 1
+	SeverityWarning
+	SeverityInformation
+	SeverityHint
+)
+
+// Diagnostic is a problem found in a document.
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// Diagnostics returns the diagnostics for the error returned by parsing the document, or none
+// if err is nil. Errors in a source included with goparsify.Include are reported at the
+// directive that included it.
+func Diagnostics(d *Document, err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	diagnostic := Diagnostic{Severity: SeverityError, Source: "goparsify", Message: err.Error()}
+
+	var perr *goparsify.Error
+	var unparsed goparsify.UnparsedInputError
+	switch {
+	case errors.As(err, &perr) && len(perr.Sources()) > 0:
+		diagnostic.Range = d.Range(perr.Sources()[0].Pos, perr.Sources()[0].Pos)
+	case errors.As(err, &perr):
+		diagnostic.Range = d.Range(perr.Pos(), perr.Pos())
+		diagnostic.Message = "expected " + perr.Expected()
+		if perr.Message() != "" {
+			diagnostic.Message = perr.Message()
+		}
+	case errors.As(err, &unparsed):
+		diagnostic.Range = d.Range(len(d.text)-len(unparsed.Remaining()), len(d.text))
+		diagnostic.Message = "unexpected input"
+	}
+	return []Diagnostic{diagnostic}
+}
+
+// SymbolKind is the kind of a DocumentSymbol.
+type SymbolKind int
+
+// The symbol kinds of the protocol.
+const (
+	File SymbolKind = iota + 1
+	Module
+	Namespace
+	Package
+	Class
+	Method
+	Property
+	Field
+	Constructor
+	Enum
+	Interface
+	Function
+	Variable
+	Constant
+	String
+	Number
+	Boolean
+	Array
+	Object
+	Key
+	Null
+	EnumMember
+	Struct
+	Event
+	Operator
+	TypeParameter
+)
+
+// DocumentSymbol is something declared in a document, eg a function, for outlines and
+// breadcrumbs.
+type DocumentSymbol struct {
+	Name string     `json:"name"`
+	Kind SymbolKind `json:"kind"`
+	// Range covers the whole declaration and SelectionRange just its name.
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// SymbolRule says how the results of a named rule become symbols.
+type SymbolRule struct {
+	Kind SymbolKind
+	// Name is the name of the rule under it that holds the name of the symbol, eg ident. The
+	// symbol is named by the token of the first result of that rule. Without one, or if it isn't
+	// found, the symbol is named by the first line of its text.
+	Name string
+}
+
+// Symbols returns the symbols of the tree rooted at r, made from the results of the named
+// rules in rules. Symbols found under another are its children.
+func Symbols(d *Document, r *goparsify.Result, rules map[string]SymbolRule) []DocumentSymbol {
+	var children []DocumentSymbol
+	for i := range r.Child {
+		children = append(children, Symbols(d, &r.Child[i], rules)...)
+	}
+	if rule, ok := rules[r.Name]; ok && r.Name != "" {
+		return []DocumentSymbol{newSymbol(d, r, rule, children)}
+	}
+	return children
+}
+
+func newSymbol(d *Document, r *goparsify.Result, rule SymbolRule, children []DocumentSymbol) DocumentSymbol {
+	symbol := DocumentSymbol{
+		Name:           firstLine(d.text[r.Start:r.End]),
+		Kind:           rule.Kind,
+		Range:          d.Range(r.Start, r.End),
+		SelectionRange: d.Range(r.Start, r.End),
+		Children:       children,
+	}
+	if rule.Name == "" {
+		return symbol
+	}
+	found := false
+	goparsify.Walk(r, func(n *goparsify.Result, depth int) bool {
+		if found {
+			return false
+		}
+		if n.Name != rule.Name || n == r {
+			return true
+		}
+		symbol.Name = n.Token
+		symbol.SelectionRange = d.Range(n.Start, n.End)
+		found = true
+		return false
+	})
+	return symbol
+}
+
+// firstLine shortens the text of a whole declaration to its first line, to name a symbol by.
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// FoldingRange is a range of lines that can be folded away.
+type FoldingRange struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+// FoldingRanges returns a folding range for each result of the tree rooted at r that has
+// children and spans several lines. Of the results starting on the same line only the
+// outermost is kept, as editors can only fold a line one way.
+func FoldingRanges(d *Document, r *goparsify.Result) []FoldingRange {
+	var ranges []FoldingRange
+	folded := map[int]bool{}
+	goparsify.Walk(r, func(n *goparsify.Result, depth int) bool {
+		if len(n.Child) == 0 {
+			return false
+		}
+		start, end := d.Position(n.Start).Line, d.Position(n.End).Line
+		if start < end && !folded[start] {
+			folded[start] = true
+			ranges = append(ranges, FoldingRange{StartLine: start, EndLine: end})
+		}
+		return true
+	})
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].StartLine < ranges[j].StartLine })
+	return ranges
+}
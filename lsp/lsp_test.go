@@ -0,0 +1,106 @@
+package lsp
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument(t *testing.T) {
+	d := NewDocument("ab\n😀é x\n")
+
+	for offset, want := range map[int]Position{
+		0:  {0, 0},
+		2:  {0, 2},
+		3:  {1, 0},
+		7:  {1, 2},
+		9:  {1, 3},
+		10: {1, 4},
+		12: {2, 0},
+		99: {2, 0},
+	} {
+		require.Equal(t, want, d.Position(offset), "offset %d", offset)
+	}
+
+	require.Equal(t, 9, d.Offset(Position{1, 3}))
+	require.Equal(t, 11, d.Offset(Position{1, 99}))
+	require.Equal(t, 12, d.Offset(Position{5, 0}))
+	require.Equal(t, Range{Position{0, 1}, Position{1, 0}}, d.Range(1, 3))
+}
+
+func TestDiagnostics(t *testing.T) {
+	parser := Seq("let", Chars("a-z"), "=", Chars("0-9"))
+	input := "let x =\n  y"
+	d := NewDocument(input)
+
+	_, err := RunTree(parser, input)
+	require.Equal(t, []Diagnostic{{
+		Range:    Range{Position{1, 2}, Position{1, 2}},
+		Severity: SeverityError,
+		Source:   "goparsify",
+		Message:  "expected 0-9",
+	}}, Diagnostics(d, err))
+
+	_, err = RunTree(Seq("let", Chars("a-z")), input)
+	require.Equal(t, []Diagnostic{{
+		Range:    Range{Position{0, 6}, Position{1, 3}},
+		Severity: SeverityError,
+		Source:   "goparsify",
+		Message:  "unexpected input",
+	}}, Diagnostics(d, err))
+
+	port := func(ps *State, node *Result) {
+		ps.ErrorHereWithMessage("port", "expected a port, got x")
+	}
+	_, err = RunTree(port, input)
+	require.Equal(t, "expected a port, got x", Diagnostics(d, err)[0].Message)
+
+	require.Equal(t, "oops", Diagnostics(d, errors.New("oops"))[0].Message)
+	require.Nil(t, Diagnostics(d, nil))
+}
+
+func TestSymbols(t *testing.T) {
+	var block Parser
+	ident := Named("ident", Chars("a-z"))
+	field := Named("field", Seq(ident, ":", ident))
+	def := Named("type", Seq("type", ident, &block))
+	block = Seq("{", Some(Any(def, field)), "}")
+	input := "type point {\n  x: int\n  type inner {\n    y: int\n  }\n}"
+	d := NewDocument(input)
+
+	tree, err := RunTree(def, input)
+	require.NoError(t, err)
+
+	symbols := Symbols(d, &tree, map[string]SymbolRule{
+		"type":  {Kind: Struct, Name: "ident"},
+		"field": {Kind: Field},
+	})
+	require.Equal(t, []DocumentSymbol{{
+		Name:           "point",
+		Kind:           Struct,
+		Range:          Range{Position{0, 0}, Position{5, 1}},
+		SelectionRange: Range{Position{0, 5}, Position{0, 10}},
+		Children: []DocumentSymbol{{
+			Name:           "x: int",
+			Kind:           Field,
+			Range:          Range{Position{1, 2}, Position{1, 8}},
+			SelectionRange: Range{Position{1, 2}, Position{1, 8}},
+		}, {
+			Name:           "inner",
+			Kind:           Struct,
+			Range:          Range{Position{2, 2}, Position{4, 3}},
+			SelectionRange: Range{Position{2, 7}, Position{2, 12}},
+			Children: []DocumentSymbol{{
+				Name:           "y: int",
+				Kind:           Field,
+				Range:          Range{Position{3, 4}, Position{3, 10}},
+				SelectionRange: Range{Position{3, 4}, Position{3, 10}},
+			}},
+		}},
+	}}, symbols)
+
+	// The fields and types inside the braces of point fold on their own too.
+	require.Equal(t, []FoldingRange{{0, 5}, {1, 4}, {2, 4}}, FoldingRanges(d, &tree))
+}
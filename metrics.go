@@ -0,0 +1,77 @@
+package goparsify
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// Metrics is told about every parse run with WithMetrics, to count parses, errors and input
+// sizes and time them, eg exporting them to expvar with ExpvarMetrics, to OpenTelemetry with
+// the otelmetrics subpackage or to another metrics library with a MetricsFunc.
+type Metrics interface {
+	// ObserveParse is called after each parse with the name given to WithMetrics. It can be
+	// called from several goroutines at once.
+	ObserveParse(name string, r ParseReport)
+}
+
+// ParseReport describes a finished parse, see Metrics.
+type ParseReport struct {
+	// InputBytes is the length of the input.
+	InputBytes int
+	// Consumed is how much of the input the parser matched.
+	Consumed int
+	Took     time.Duration
+	// Err is what Run returned, nil if the parse succeeded.
+	Err error
+}
+
+// MetricsFunc makes a function of the right signature a Metrics, eg to record a Prometheus
+// counter.
+type MetricsFunc func(name string, r ParseReport)
+
+// ObserveParse calls f.
+func (f MetricsFunc) ObserveParse(name string, r ParseReport) {
+	f(name, r)
+}
+
+// WithMetrics reports the parse to m under name, usually the name of the grammar, once it is
+// done.
+func WithMetrics(name string, m Metrics) Option {
+	return func(cfg *runConfig) {
+		cfg.metricsName, cfg.metrics = name, m
+	}
+}
+
+// ExpvarMetrics publishes the metrics of parses with expvar, as a map from each name given to
+// WithMetrics to the counters parses, errors, input_bytes and nanoseconds.
+type ExpvarMetrics struct {
+	mu     sync.Mutex
+	m      *expvar.Map
+	byName map[string]*expvar.Map
+}
+
+// NewExpvarMetrics publishes the metrics as the expvar variable name. Like expvar.NewMap it
+// panics if the name is already taken, so call it once, eg in a package variable.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{m: expvar.NewMap(name), byName: map[string]*expvar.Map{}}
+}
+
+// ObserveParse adds the parse to the counters of name.
+func (e *ExpvarMetrics) ObserveParse(name string, r ParseReport) {
+	e.mu.Lock()
+	counters, ok := e.byName[name]
+	if !ok {
+		counters = new(expvar.Map).Init()
+		e.byName[name] = counters
+		e.m.Set(name, counters)
+	}
+	e.mu.Unlock()
+
+	counters.Add("parses", 1)
+	if r.Err != nil {
+		counters.Add("errors", 1)
+	}
+	counters.Add("input_bytes", int64(r.InputBytes))
+	counters.Add("nanoseconds", r.Took.Nanoseconds())
+}
@@ -0,0 +1,50 @@
+package goparsify
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMetrics(t *testing.T) {
+	var mu sync.Mutex
+	var reports []ParseReport
+	m := WithMetrics("number", MetricsFunc(func(name string, r ParseReport) {
+		require.Equal(t, "number", name)
+		mu.Lock()
+		reports = append(reports, r)
+		mu.Unlock()
+	}))
+
+	_, _, err := Run(NumberLit(), "12", m)
+	require.NoError(t, err)
+	_, _, err = Run(NumberLit(), "x", m)
+	require.Error(t, err)
+	_, _, err = RunPrefix(NumberLit(), "3 4", m)
+	require.NoError(t, err)
+
+	require.Len(t, reports, 3)
+	require.Equal(t, 2, reports[0].InputBytes)
+	require.Equal(t, 2, reports[0].Consumed)
+	require.NoError(t, reports[0].Err)
+	require.EqualError(t, reports[1].Err, "offset 0: expected number")
+	require.Equal(t, 2, reports[2].Consumed)
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	metrics := NewExpvarMetrics("goparsify_test_parses")
+	_, _, _ = Run(NumberLit(), "12", WithMetrics("number", metrics))
+	_, _, _ = Run(NumberLit(), "x", WithMetrics("number", metrics))
+	_, _, _ = Run("a", "a", WithMetrics("a", metrics))
+
+	var published map[string]map[string]int64
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("goparsify_test_parses").String()), &published))
+	require.Equal(t, int64(2), published["number"]["parses"])
+	require.Equal(t, int64(1), published["number"]["errors"])
+	require.Equal(t, int64(3), published["number"]["input_bytes"])
+	require.Equal(t, int64(1), published["a"]["parses"])
+	require.Zero(t, published["a"]["errors"])
+}
@@ -0,0 +1,191 @@
+package goparsify
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Amount is bound into .Result by Money. The value is kept in the currency's minor units
+// (eg cents) so no precision is lost to floating point.
+type Amount struct {
+	// Currency is an ISO 4217 code such as USD.
+	Currency string
+	// Minor is the amount in minor units, eg 123450 for $1,234.50 or 500 for ¥500.
+	Minor int64
+}
+
+// String formats the amount with a full stop as the decimal separator, eg "USD 1234.50".
+func (a Amount) String() string {
+	decimals := currencyDecimals(a.Currency)
+	sign := ""
+	minor := a.Minor
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if decimals == 0 {
+		return a.Currency + " " + sign + digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	return a.Currency + " " + sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+var currencySymbols = map[string]string{
+	"$": "USD", "US$": "USD", "€": "EUR", "£": "GBP", "¥": "JPY", "₹": "INR", "₩": "KRW",
+	"₽": "RUB", "₺": "TRY", "₪": "ILS", "₫": "VND", "฿": "THB", "₴": "UAH", "₦": "NGN",
+	"C$": "CAD", "A$": "AUD", "NZ$": "NZD", "HK$": "HKD", "R$": "BRL", "CHF": "CHF",
+}
+
+var currencyCodes = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2,
+	"COP": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "MYR": 2,
+	"NGN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PHP": 2, "PKR": 2, "PLN": 2, "RON": 2, "RUB": 2,
+	"SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2,
+	"VND": 0, "ZAR": 2,
+}
+
+func currencyDecimals(code string) int {
+	if d, ok := currencyCodes[code]; ok {
+		return d
+	}
+	return 2
+}
+
+// Money matches an amount of money with a currency symbol or ISO 4217 code before or after
+// it, eg $1,234.50, -€5, 1 234,50 EUR or JPY 500, and binds it into .Result as an Amount.
+//
+// Digit grouping with commas, full stops, spaces or apostrophes is understood. The final
+// comma or full stop is treated as the decimal separator unless it's followed by exactly
+// three digits, so 1.234 EUR is a thousand and 1,5 EUR is one and a half. An amount with more
+// decimal places than its currency has doesn't match.
+func Money() Parser {
+	return NewParser("money", func(ps *State, node *Result) {
+		ps.WS(ps)
+		input := ps.Get()
+		pos := 0
+
+		neg := false
+		if strings.HasPrefix(input, "-") {
+			neg = true
+			pos++
+		}
+
+		currency, w := scanCurrency(input[pos:])
+		if w > 0 {
+			pos += w
+			pos += scanMoneySpace(input[pos:])
+			if !neg && strings.HasPrefix(input[pos:], "-") {
+				neg = true
+				pos++
+			}
+		}
+
+		whole, fraction, w := scanAmount(input[pos:])
+		if w == 0 {
+			ps.ErrorHere("money")
+			return
+		}
+		pos += w
+
+		if currency == "" {
+			space := scanMoneySpace(input[pos:])
+			if currency, w = scanCurrency(input[pos+space:]); w == 0 {
+				ps.ErrorHere("money")
+				return
+			}
+			pos += space + w
+		}
+
+		if ps.tokenTooLong(ps.Pos, pos) {
+			return
+		}
+		decimals := currencyDecimals(currency)
+		if len(fraction) > decimals {
+			ps.ErrorHere("money")
+			return
+		}
+		minor, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10, 64)
+		if err != nil {
+			ps.ErrorHere("money")
+			return
+		}
+		if neg {
+			minor = -minor
+		}
+
+		node.Token = input[:pos]
+		node.Start, node.End = ps.Pos, ps.Pos+pos
+		node.Result = Amount{Currency: currency, Minor: minor}
+		ps.Advance(pos)
+	})
+}
+
+// scanCurrency matches the longest known currency symbol or code at the start of s.
+func scanCurrency(s string) (code string, width int) {
+	for symbol, c := range currencySymbols {
+		if len(symbol) > width && strings.HasPrefix(s, symbol) {
+			code, width = c, len(symbol)
+		}
+	}
+	if width == 0 && len(s) >= 3 {
+		if _, ok := currencyCodes[s[:3]]; ok && (len(s) == 3 || !isLetter(s[3])) {
+			return s[:3], 3
+		}
+	}
+	return code, width
+}
+
+// scanMoneySpace returns the width of the optional space between an amount and its currency.
+func scanMoneySpace(s string) int {
+	r, w := utf8.DecodeRuneInString(s)
+	if r == ' ' || r == '\u00a0' || r == '\u202f' {
+		return w
+	}
+	return 0
+}
+
+// scanAmount returns the digits of the whole and fractional part of a grouped number at the
+// start of s, and how many bytes it covered.
+func scanAmount(s string) (whole, fraction string, width int) {
+	end := skipDigits(s, 0)
+	if end == 0 {
+		return "", "", 0
+	}
+	digits := s[:end]
+
+	var groupSep rune
+	for end < len(s) {
+		sep, w := utf8.DecodeRuneInString(s[end:])
+		if !isMoneySeparator(sep) || groupSep != 0 && sep != groupSep && sep != '.' && sep != ',' {
+			break
+		}
+		groupEnd := skipDigits(s, end+w)
+		group := s[end+w : groupEnd]
+		if group == "" {
+			break
+		}
+		if len(group) == 3 && (groupSep == 0 || sep == groupSep) && digits != "0" {
+			groupSep = sep
+			digits += group
+			end = groupEnd
+			continue
+		}
+		if sep == '.' || sep == ',' {
+			return digits, group, groupEnd
+		}
+		break
+	}
+	return digits, "", end
+}
+
+func isMoneySeparator(r rune) bool {
+	switch r {
+	case ',', '.', ' ', '\'', '\u00a0', '\u202f', '\u2009':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoney(t *testing.T) {
+	tests := map[string]Amount{
+		"$1,234.50":        {Currency: "USD", Minor: 123450},
+		"$5":               {Currency: "USD", Minor: 500},
+		"-€5":              {Currency: "EUR", Minor: -500},
+		"€-5,25":           {Currency: "EUR", Minor: -525},
+		"1 234,50 EUR":     {Currency: "EUR", Minor: 123450},
+		"1 234,50 €":       {Currency: "EUR", Minor: 123450},
+		"1.234 EUR":        {Currency: "EUR", Minor: 123400},
+		"1,5 EUR":          {Currency: "EUR", Minor: 150},
+		"JPY 500":          {Currency: "JPY", Minor: 500},
+		"¥1,000":           {Currency: "JPY", Minor: 1000},
+		"CHF 1'000.05":     {Currency: "CHF", Minor: 100005},
+		"12.345,678 KWD":   {Currency: "KWD", Minor: 12345678},
+		"US$0.99":          {Currency: "USD", Minor: 99},
+		"£1,234,567.8":     {Currency: "GBP", Minor: 123456780},
+		"1,234.50 dollars": {},
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, Money())
+			if expected.Currency == "" {
+				require.True(t, p.Errored())
+				return
+			}
+			require.False(t, p.Errored(), p.Error.Error())
+			require.Equal(t, expected, result.Result)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	t.Run("too many decimals", func(t *testing.T) {
+		_, p := runParser("$0.125", Money())
+		require.Equal(t, "offset 0: expected money", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+
+		_, p = runParser("¥1.5", Money())
+		require.Equal(t, "offset 0: expected money", p.Error.Error())
+	})
+
+	t.Run("leaves the rest", func(t *testing.T) {
+		result, p := runParser("$12.50, thanks", Money())
+		require.Equal(t, "$12.50", result.Token)
+		require.Equal(t, ", thanks", p.Get())
+	})
+
+	t.Run("codes must not run into words", func(t *testing.T) {
+		_, p := runParser("5 EURO", Money())
+		require.Equal(t, "offset 0: expected money", p.Error.Error())
+	})
+
+	t.Run("with SignalSeq", func(t *testing.T) {
+		total := SignalSeq(Regex(`\S+`), Exact("Total:"), Money()).Map(func(n *Result) {
+			n.Result = n.Child[1].Result
+		})
+		result, _, err := Run(total, "Coffee 3.50 Total: $7.25 Card")
+		require.NoError(t, err)
+		require.Equal(t, Amount{Currency: "USD", Minor: 725}, result)
+	})
+}
+
+func TestAmount_String(t *testing.T) {
+	require.Equal(t, "USD 1234.50", Amount{Currency: "USD", Minor: 123450}.String())
+	require.Equal(t, "EUR -0.05", Amount{Currency: "EUR", Minor: -5}.String())
+	require.Equal(t, "JPY 500", Amount{Currency: "JPY", Minor: 500}.String())
+}
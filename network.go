@@ -0,0 +1,241 @@
+package goparsify
+
+import (
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Endpoint is bound into .Result by the HostPort parser.
+type Endpoint struct {
+	// Host is a hostname or IP address. IPv6 addresses have their brackets removed.
+	Host string
+	Port uint16
+}
+
+// String formats the Endpoint the same way net.JoinHostPort does.
+func (hp Endpoint) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(int(hp.Port)))
+}
+
+// IP matches an IPv4 or IPv6 address and binds it into .Result as a netip.Addr.
+func IP() Parser {
+	return NewParser("ip address", ipImpl("ip address", func(a netip.Addr) bool { return true }))
+}
+
+// IPv4 matches a dotted decimal IPv4 address and binds it into .Result as a netip.Addr.
+func IPv4() Parser {
+	return NewParser("ipv4 address", ipImpl("ipv4 address", netip.Addr.Is4))
+}
+
+// IPv6 matches an IPv6 address, including IPv4-mapped forms like ::ffff:1.2.3.4, and binds
+// it into .Result as a netip.Addr.
+func IPv6() Parser {
+	return NewParser("ipv6 address", ipImpl("ipv6 address", netip.Addr.Is6))
+}
+
+func ipImpl(expected string, accept func(netip.Addr) bool) Parser {
+	return func(ps *State, node *Result) {
+		ps.WS(ps)
+		var addr netip.Addr
+		end := longestValidPrefix(ps.Get(), isIPByte, func(s string) bool {
+			a, err := netip.ParseAddr(s)
+			if err != nil || !accept(a) {
+				return false
+			}
+			addr = a
+			return true
+		})
+		if end == 0 {
+			ps.ErrorHere(expected)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, end) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+end]
+		node.Start, node.End = ps.Pos, ps.Pos+end
+		node.Result = addr
+		ps.Advance(end)
+	}
+}
+
+// CIDR matches an IP network in CIDR notation such as 10.0.0.0/8 or 2001:db8::/32
+// and binds it into .Result as a netip.Prefix.
+func CIDR() Parser {
+	return NewParser("cidr", func(ps *State, node *Result) {
+		ps.WS(ps)
+		var prefix netip.Prefix
+		end := longestValidPrefix(ps.Get(), func(c byte) bool { return isIPByte(c) || c == '/' }, func(s string) bool {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return false
+			}
+			prefix = p
+			return true
+		})
+		if end == 0 {
+			ps.ErrorHere("cidr")
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, end) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+end]
+		node.Start, node.End = ps.Pos, ps.Pos+end
+		node.Result = prefix
+		ps.Advance(end)
+	})
+}
+
+// MAC matches a hardware address in any of the formats accepted by net.ParseMAC,
+// eg 00:1a:2b:3c:4d:5e, 00-1A-2B-3C-4D-5E or 001a.2b3c.4d5e, and binds it into .Result
+// as a net.HardwareAddr.
+func MAC() Parser {
+	return NewParser("mac address", func(ps *State, node *Result) {
+		ps.WS(ps)
+		var mac net.HardwareAddr
+		end := longestValidPrefix(ps.Get(), func(c byte) bool { return isHexDigit(c) || c == ':' || c == '-' || c == '.' }, func(s string) bool {
+			m, err := net.ParseMAC(s)
+			if err != nil {
+				return false
+			}
+			mac = m
+			return true
+		})
+		if end == 0 {
+			ps.ErrorHere("mac address")
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, end) {
+			return
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+end]
+		node.Start, node.End = ps.Pos, ps.Pos+end
+		node.Result = mac
+		ps.Advance(end)
+	})
+}
+
+// Port matches a decimal port number between 0 and 65535 and binds it into .Result as a uint16.
+func Port() Parser {
+	return NewParser("port", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end := ps.Pos
+		for end < len(ps.Input) && end-ps.Pos < 5 && ps.Input[end] >= '0' && ps.Input[end] <= '9' {
+			end++
+		}
+		if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+			return
+		}
+		port, err := strconv.ParseUint(ps.Input[ps.Pos:end], 10, 16)
+		if err != nil || end < len(ps.Input) && ps.Input[end] >= '0' && ps.Input[end] <= '9' {
+			ps.ErrorHere("port")
+			return
+		}
+		node.Token = ps.Input[ps.Pos:end]
+		node.Result = uint16(port)
+		node.Start, node.End = ps.Pos, end
+		ps.Pos = end
+	})
+}
+
+// HostPort matches host:port where host is a hostname, an IPv4 address or a bracketed
+// IPv6 address, eg example.com:80, 10.0.0.1:22 or [::1]:8080. The pair is bound into
+// .Result as an Endpoint.
+func HostPort() Parser {
+	port := Port()
+	return NewParser("host:port", func(ps *State, node *Result) {
+		ps.WS(ps)
+		start := ps.Pos
+		var host string
+		end := start
+		if end < len(ps.Input) && ps.Input[end] == '[' {
+			closing := end + 1
+			for closing < len(ps.Input) && ps.Input[closing] != ']' {
+				closing++
+			}
+			if closing >= len(ps.Input) {
+				ps.ErrorHere("host:port")
+				return
+			}
+			if a, err := netip.ParseAddr(ps.Input[end+1 : closing]); err != nil || !a.Is6() {
+				ps.ErrorHere("host:port")
+				return
+			}
+			host = ps.Input[end+1 : closing]
+			end = closing + 1
+		} else {
+			for end < len(ps.Input) && isHostnameByte(ps.Input[end]) {
+				end++
+			}
+			host = ps.Input[start:end]
+		}
+		if host == "" {
+			ps.ErrorHere("host:port")
+			return
+		}
+		if end >= len(ps.Input) || ps.Input[end] != ':' {
+			ps.errorInToken(ps.Pos, end, ":")
+			return
+		}
+
+		ps.Pos = end + 1
+		oldWS := ps.WS
+		ps.WS = NoWhitespace
+		var portNode Result
+		port(ps, &portNode)
+		ps.WS = oldWS
+		if ps.Errored() {
+			ps.Pos = start
+			return
+		}
+		if ps.tokenTooLong(start, ps.Pos-start) {
+			ps.Pos = start
+			return
+		}
+
+		node.Token = ps.Input[start:ps.Pos]
+		node.Start, node.End = start, ps.Pos
+		node.Result = Endpoint{Host: host, Port: portNode.Result.(uint16)}
+	})
+}
+
+// longestValidPrefix returns the length of the longest prefix of s made of allowed bytes
+// that valid accepts, or 0 if there is none. Address syntaxes are full of optional parts,
+// so it is simpler to let the standard library judge candidates than to reimplement them.
+// Candidates never split a run of digits, so 1.2.3.456 doesn't match as 1.2.3.45, but the
+// digits after a '.' or '/' are decimal, so 1.2.3.4abc matches as 1.2.3.4.
+func longestValidPrefix(s string, allowed func(byte) bool, valid func(string) bool) int {
+	longest := 0
+	for longest < len(s) && allowed(s[longest]) {
+		longest++
+	}
+	for end := longest; end > 0; end-- {
+		if end < longest && splitsDigits(s, end) {
+			continue
+		}
+		if valid(s[:end]) {
+			return end
+		}
+	}
+	return 0
+}
+
+// splitsDigits returns whether ending a candidate at end splits a run of digits: decimal ones
+// after a '.' or '/', as in IPv4 addresses and prefix lengths, and hex ones otherwise.
+func splitsDigits(s string, end int) bool {
+	if i := strings.LastIndexAny(s[:end], ".:/-"); i >= 0 && (s[i] == '.' || s[i] == '/') {
+		return isDigit(s[end-1]) && isDigit(s[end])
+	}
+	return isHexDigit(s[end-1]) && isHexDigit(s[end])
+}
+
+func isIPByte(c byte) bool {
+	return isHexDigit(c) || c == ':' || c == '.'
+}
+
+func isHostnameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_'
+}
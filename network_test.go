@@ -0,0 +1,124 @@
+package goparsify
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIP(t *testing.T) {
+	t.Run("ipv4", func(t *testing.T) {
+		result, p := runParser("10.0.0.1 accept", IP())
+		require.Equal(t, netip.MustParseAddr("10.0.0.1"), result.Result)
+		require.Equal(t, "10.0.0.1", result.Token)
+		require.Equal(t, " accept", p.Get())
+	})
+
+	t.Run("ipv6", func(t *testing.T) {
+		result, p := runParser("2001:db8::1", IP())
+		require.Equal(t, netip.MustParseAddr("2001:db8::1"), result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("stops at letters after an ipv4 address", func(t *testing.T) {
+		result, p := runParser("1.2.3.4abc", IP())
+		require.Equal(t, netip.MustParseAddr("1.2.3.4"), result.Result)
+		require.Equal(t, "abc", p.Get())
+
+		result, p = runParser("10.0.0.0/8abc", CIDR())
+		require.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), result.Result)
+		require.Equal(t, "abc", p.Get())
+	})
+
+	t.Run("does not swallow a port", func(t *testing.T) {
+		result, p := runParser("10.0.0.1:80", IP())
+		require.Equal(t, "10.0.0.1", result.Token)
+		require.Equal(t, ":80", p.Get())
+	})
+
+	t.Run("rejects out of range octets", func(t *testing.T) {
+		_, p := runParser("256.1.1.1", IP())
+		require.Equal(t, "offset 0: expected ip address", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("families", func(t *testing.T) {
+		_, p := runParser("::1", IPv4())
+		require.Equal(t, "offset 0: expected ipv4 address", p.Error.Error())
+
+		_, p = runParser("127.0.0.1", IPv6())
+		require.Equal(t, "offset 0: expected ipv6 address", p.Error.Error())
+
+		result, _ := runParser("::ffff:1.2.3.4", IPv6())
+		require.Equal(t, netip.MustParseAddr("::ffff:1.2.3.4"), result.Result)
+	})
+}
+
+func TestCIDR(t *testing.T) {
+	result, p := runParser("192.168.0.0/16 deny", CIDR())
+	require.Equal(t, netip.MustParsePrefix("192.168.0.0/16"), result.Result)
+	require.Equal(t, " deny", p.Get())
+
+	result, _ = runParser("2001:db8::/32", CIDR())
+	require.Equal(t, netip.MustParsePrefix("2001:db8::/32"), result.Result)
+
+	_, p = runParser("10.0.0.0/33", CIDR())
+	require.Equal(t, "offset 0: expected cidr", p.Error.Error())
+}
+
+func TestMAC(t *testing.T) {
+	want, _ := net.ParseMAC("00:1a:2b:3c:4d:5e")
+	for _, input := range []string{"00:1a:2b:3c:4d:5e", "00-1A-2B-3C-4D-5E", "001a.2b3c.4d5e"} {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, MAC())
+			require.Equal(t, want, result.Result)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	_, p := runParser("00:1a:2b", MAC())
+	require.Equal(t, "offset 0: expected mac address", p.Error.Error())
+}
+
+func TestPort(t *testing.T) {
+	result, p := runParser("8080", Port())
+	require.Equal(t, uint16(8080), result.Result)
+	require.Equal(t, "", p.Get())
+
+	_, p = runParser("65536", Port())
+	require.Equal(t, "offset 0: expected port", p.Error.Error())
+
+	_, p = runParser("123456", Port())
+	require.Equal(t, "offset 0: expected port", p.Error.Error())
+}
+
+func TestHostPort(t *testing.T) {
+	tests := map[string]Endpoint{
+		"example.com:80": {Host: "example.com", Port: 80},
+		"10.0.0.1:22":    {Host: "10.0.0.1", Port: 22},
+		"[::1]:8080":     {Host: "::1", Port: 8080},
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, HostPort())
+			require.Equal(t, expected, result.Result)
+			require.Equal(t, input, result.Token)
+			require.Equal(t, "", p.Get())
+			require.Equal(t, input, expected.String())
+		})
+	}
+
+	t.Run("missing port", func(t *testing.T) {
+		_, p := runParser("example.com", HostPort())
+		require.Equal(t, "offset 11: expected :", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("bad port", func(t *testing.T) {
+		_, p := runParser("example.com:http", HostPort())
+		require.Equal(t, "offset 12: expected port", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
@@ -0,0 +1,141 @@
+package goparsify
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithNormalization parses the input after normalizing it with normalize, eg norm.NFC.String
+// from golang.org/x/text/unicode/norm, so an é typed as an e and a combining accent still
+// matches Exact("é"). The literals of the grammar have to be in the same form. Tokens are
+// normalized but errors and the spans of the Results are at offsets into the input as it was
+// given, so they still point at what the user wrote.
+//
+// The input is normalized one grapheme cluster at a time, which is how offsets are mapped back;
+// the standard normalization forms don't change text across cluster boundaries.
+func WithNormalization(normalize func(string) string) Option {
+	return func(cfg *runConfig) {
+		cfg.normalize = normalize
+	}
+}
+
+// WithoutBOM skips the byte order mark at the start of the input, if there is one, so files
+// saved by editors that write one parse the same as those that don't. A UTF-16 one decoded by
+// WithEncoding is skipped too. The spans of the Results are still offsets into the input as it
+// was given.
+func WithoutBOM() Option {
+	return func(cfg *runConfig) {
+		cfg.withoutBOM = true
+	}
+}
+
+// WithNormalizedNewlines turns the \r\n and \r line endings of the input into \n before
+// parsing, so a grammar written for one kind of line ending parses files from Windows and old
+// Macs, and Column and the likes of heredocs count their lines. Tokens have \n in them but
+// errors and the spans of the Results are at offsets into the input as it was given.
+func WithNormalizedNewlines() Option {
+	return func(cfg *runConfig) {
+		cfg.newlines = true
+	}
+}
+
+// offsetMap maps offsets into normalized input back to the input as it was given.
+type offsetMap struct {
+	// normalized and original hold the offsets at which each cluster starts in the two.
+	normalized, original []int
+	// linear is set when the offsets inside a cluster map one to one, rather than all to its
+	// start.
+	linear bool
+}
+
+// normalizeInput normalizes input one grapheme cluster at a time.
+func normalizeInput(input string, normalize func(string) string) (string, *offsetMap) {
+	m := &offsetMap{}
+	var out []byte
+	for pos := 0; pos < len(input); {
+		n := nextGrapheme(input[pos:])
+		m.normalized = append(m.normalized, len(out))
+		m.original = append(m.original, pos)
+		out = append(out, normalize(input[pos:pos+n])...)
+		pos += n
+	}
+	m.normalized = append(m.normalized, len(out))
+	m.original = append(m.original, len(input))
+	return string(out), m
+}
+
+// originalOffset maps an offset into the normalized input to the start of the cluster it is
+// in in the original.
+func (m *offsetMap) originalOffset(offset int) int {
+	i := sort.SearchInts(m.normalized, offset+1) - 1
+	if i < 0 {
+		return 0
+	}
+	if m.linear {
+		return m.original[i] + offset - m.normalized[i]
+	}
+	return m.original[i]
+}
+
+// stripBOM removes the byte order mark from the start of input.
+func stripBOM(input string) (string, *offsetMap) {
+	const bom = "\ufeff"
+	if !strings.HasPrefix(input, bom) {
+		return input, nil
+	}
+	return input[len(bom):], &offsetMap{normalized: []int{0}, original: []int{len(bom)}, linear: true}
+}
+
+// normalizeNewlines turns the \r\n and \r of input into \n. Only \r\n changes the length of
+// the input, so the text in between maps one to one.
+func normalizeNewlines(input string) (string, *offsetMap) {
+	if strings.IndexByte(input, '\r') < 0 {
+		return input, nil
+	}
+	m := &offsetMap{normalized: []int{0}, original: []int{0}, linear: true}
+	var b strings.Builder
+	b.Grow(len(input))
+	for i := 0; i < len(input); i++ {
+		if input[i] != '\r' {
+			b.WriteByte(input[i])
+			continue
+		}
+		b.WriteByte('\n')
+		if i+1 < len(input) && input[i+1] == '\n' {
+			i++
+			m.normalized = append(m.normalized, b.Len())
+			m.original = append(m.original, i+1)
+		}
+	}
+	return b.String(), m
+}
+
+// offsetMaps maps offsets back through each of the steps the input was rewritten by, the
+// first of which is applied last.
+type offsetMaps []*offsetMap
+
+func (ms offsetMaps) originalOffset(offset int) int {
+	for i := len(ms) - 1; i >= 0; i-- {
+		offset = ms[i].originalOffset(offset)
+	}
+	return offset
+}
+
+// mapResult maps the spans of r and its children, and the errors Recovering left in them, back
+// to the input, leaving out the results of Include, which are spans of other sources mapped by
+// it.
+func (ms offsetMaps) mapResult(r *Result) {
+	if len(ms) == 0 {
+		return
+	}
+	Walk(r, func(n *Result, depth int) bool {
+		if _, included := n.GetMeta(SourceKey); included {
+			return false
+		}
+		n.Start, n.End = ms.originalOffset(n.Start), ms.originalOffset(n.End)
+		if err, ok := n.Meta[ErrorKey].(*Error); ok {
+			err.mapOffsets(ms.originalOffset)
+		}
+		return true
+	})
+}
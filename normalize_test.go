@@ -0,0 +1,88 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nfc composes the few sequences the tests use, standing in for norm.NFC.String.
+var nfc = strings.NewReplacer("e\u0301", "\u00e9", "o\u0308", "\u00f6").Replace
+
+func TestWithNormalization(t *testing.T) {
+	parser := Seq("caf\u00e9", Chars("a-z\u00f6"), "!")
+	input := "cafe\u0301 bo\u0308rk !"
+
+	_, err := RunTree(parser, input)
+	require.EqualError(t, err, "offset 0: expected caf\u00e9")
+
+	tree, err := RunTree(parser, input, WithNormalization(nfc))
+	require.NoError(t, err)
+	require.Equal(t, "b\u00f6rk", tree.Child[1].Token)
+	require.Equal(t, "bo\u0308rk", input[tree.Child[1].Start:tree.Child[1].End])
+	require.Equal(t, len(input)-1, tree.Child[2].Start)
+
+	_, err = RunTree(parser, "cafe\u0301 bo\u0308rk?", WithNormalization(nfc))
+	require.EqualError(t, err, "offset 13: expected !")
+}
+
+func TestWithoutBOM(t *testing.T) {
+	parser := Seq("a", Chars("0-9"))
+
+	_, err := RunTree(parser, "\ufeffa1")
+	require.EqualError(t, err, "offset 0: expected a")
+
+	tree, err := RunTree(parser, "\ufeffa 12", WithoutBOM())
+	require.NoError(t, err)
+	require.Equal(t, 3, tree.Child[0].Start)
+	require.Equal(t, 5, tree.Child[1].Start)
+	require.Equal(t, 7, tree.Child[1].End)
+
+	_, err = RunTree(parser, "\ufeffa b", WithoutBOM())
+	require.EqualError(t, err, "offset 5: expected 0-9")
+
+	tree, err = RunTree(parser, "a1", WithoutBOM())
+	require.NoError(t, err)
+	require.Equal(t, 1, tree.Child[1].Start)
+
+	t.Run("utf-16", func(t *testing.T) {
+		tree, err := RunTree(parser, "\xff\xfea\x001\x00", WithEncoding(utf16LE{}), WithoutBOM())
+		require.NoError(t, err)
+		require.Equal(t, 2, tree.Child[0].Start)
+		require.Equal(t, 4, tree.Child[1].Start)
+	})
+}
+
+func TestWithNormalizedNewlines(t *testing.T) {
+	line := Seq(Chars("a-z"), "\n")
+	lines := Many(line)
+	input := "ab\r\ncd\ref\r\n"
+	opts := []Option{WithWhitespace(HorizontalWhitespace), WithNormalizedNewlines()}
+
+	_, err := RunTree(lines, input, WithWhitespace(HorizontalWhitespace))
+	require.Error(t, err)
+
+	tree, err := RunTree(lines, input, opts...)
+	require.NoError(t, err)
+	require.Len(t, tree.Child, 3)
+	require.Equal(t, "\n", tree.Child[0].Child[1].Token)
+	require.Equal(t, 2, tree.Child[0].Child[1].Start)
+	require.Equal(t, 4, tree.Child[0].Child[1].End)
+	require.Equal(t, 4, tree.Child[1].Child[0].Start)
+	require.Equal(t, 7, tree.Child[2].Child[0].Start)
+	require.Equal(t, len(input), tree.End)
+
+	_, err = RunTree(lines, "ab\r\ncd\r\n12", opts...)
+	require.EqualError(t, err, "left unparsed: 12")
+	_, err = RunTree(Seq(line, line, line), "ab\r\ncd\r\n12\r\n", opts...)
+	require.EqualError(t, err, "offset 8: expected a-z")
+
+	t.Run("columns", func(t *testing.T) {
+		var column int
+		parser := Seq(line, Chars("a-z"), func(ps *State, node *Result) { column = ps.Column() })
+		_, err := RunTree(parser, "ab\rcd", opts...)
+		require.NoError(t, err)
+		require.Equal(t, 3, column)
+	})
+}
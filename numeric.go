@@ -0,0 +1,279 @@
+package goparsify
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// OverflowPolicy decides what Int, Float and NumberLitWith do with a literal that is
+// syntactically valid but does not fit in the bound type.
+type OverflowPolicy int
+
+const (
+	// OverflowError fails the match, reporting the literal as out of range.
+	OverflowError OverflowPolicy = iota
+	// OverflowSaturate clamps integers to their min/max value and turns floats into ±Inf.
+	OverflowSaturate
+	// OverflowBig binds a *big.Int or *big.Float instead, with enough precision for every digit
+	// of the literal. Literals that fit are bound as usual.
+	OverflowBig
+	// OverflowRaw binds the literal as it was written, as a string, for the caller to convert.
+	// Literals that fit are bound as usual.
+	OverflowRaw
+)
+
+// IntOptions configures Int.
+type IntOptions struct {
+	// Unsigned rejects a leading sign and binds a uint64 instead of an int64.
+	Unsigned bool
+	// Overflow says what to do when the literal does not fit in 64 bits.
+	Overflow OverflowPolicy
+}
+
+// FloatOptions configures Float.
+type FloatOptions struct {
+	// Overflow says what to do when the literal is beyond the range of a float64.
+	Overflow OverflowPolicy
+	// Exact treats literals that a float64 can only hold rounded, eg 0.1 or 9007199254740993, as
+	// not fitting either, for formats where rounding loses data. They are then handled by
+	// Overflow, except that OverflowSaturate binds them rounded.
+	Exact bool
+}
+
+// NumberOptions configures NumberLitWith.
+type NumberOptions struct {
+	// Overflow says what to do when an integer does not fit in an int64, or a float is beyond
+	// the range of a float64.
+	Overflow OverflowPolicy
+	// Exact is as for FloatOptions.
+	Exact bool
+}
+
+// Int matches an integer literal using Go's syntax and binds it into .Result as an int64,
+// or a uint64 if opts.Unsigned is set. It understands:
+//   - an optional leading + or -
+//   - 0x, 0o and 0b prefixes for hex, octal and binary, and a leading 0 for octal
+//   - underscores between digits, eg 1_000_000
+func Int(opts IntOptions) Parser {
+	return NewParser("integer", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end := ps.Pos
+		inputLen := len(ps.Input)
+
+		neg := false
+		if !opts.Unsigned && end < inputLen && (ps.Input[end] == '-' || ps.Input[end] == '+') {
+			neg = ps.Input[end] == '-'
+			end++
+		}
+
+		digitsStart := end
+		end = scanIntDigits(ps.Input, end)
+		if end == digitsStart {
+			ps.ErrorHere("integer")
+			return
+		}
+
+		if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+			return
+		}
+		tok := ps.Input[ps.Pos:end]
+		var err error
+		if opts.Unsigned {
+			var v uint64
+			v, err = strconv.ParseUint(tok, 0, 64)
+			node.Result = v
+			if isRangeErr(err) {
+				node.Result, err = overflowInt(tok, 0, opts.Overflow, uint64(math.MaxUint64), err)
+			}
+		} else {
+			var v int64
+			v, err = strconv.ParseInt(tok, 0, 64)
+			node.Result = v
+			if isRangeErr(err) {
+				saturated := int64(math.MaxInt64)
+				if neg {
+					saturated = math.MinInt64
+				}
+				node.Result, err = overflowInt(tok, 0, opts.Overflow, saturated, err)
+			}
+		}
+		if err != nil {
+			node.Result = nil
+			if isRangeErr(err) {
+				ps.ErrorHere("integer in range")
+			} else {
+				ps.ErrorHere("integer")
+			}
+			return
+		}
+
+		node.Token = tok
+		node.Start, node.End = ps.Pos, end
+		ps.Pos = end
+	})
+}
+
+// Float matches a decimal floating point literal using Go's syntax and binds it into
+// .Result as a float64. Integers are accepted too. It understands:
+//   - an optional leading + or -
+//   - underscores between digits, eg 1_000.5
+//   - an optional fraction and exponent, eg .5, 1e10, 2.5E-3
+func Float(opts FloatOptions) Parser {
+	return NewParser("float", func(ps *State, node *Result) {
+		ps.WS(ps)
+		end := ps.Pos
+		inputLen := len(ps.Input)
+
+		if end < inputLen && (ps.Input[end] == '-' || ps.Input[end] == '+') {
+			end++
+		}
+
+		mantissaStart := end
+		end = scanDecimalDigits(ps.Input, end)
+		if end < inputLen && ps.Input[end] == '.' {
+			end = scanDecimalDigits(ps.Input, end+1)
+		}
+		if end-mantissaStart == 0 || ps.Input[mantissaStart:end] == "." {
+			ps.ErrorHere("float")
+			return
+		}
+
+		if end < inputLen && (ps.Input[end] == 'e' || ps.Input[end] == 'E') {
+			exp := end + 1
+			if exp < inputLen && (ps.Input[exp] == '-' || ps.Input[exp] == '+') {
+				exp++
+			}
+			// Only consume the exponent if it has digits, so 1else leaves "else" behind.
+			if expEnd := scanDecimalDigits(ps.Input, exp); expEnd > exp {
+				end = expEnd
+			}
+		}
+
+		if ps.tokenTooLong(ps.Pos, end-ps.Pos) {
+			return
+		}
+		tok := ps.Input[ps.Pos:end]
+		v, err := parseFloat(tok, opts.Overflow, opts.Exact)
+		if err != nil {
+			if isRangeErr(err) {
+				ps.ErrorHere("float in range")
+			} else if err == errInexact {
+				ps.ErrorHere("exact float")
+			} else {
+				ps.ErrorHere("float")
+			}
+			return
+		}
+
+		node.Token = tok
+		node.Result = v
+		node.Start, node.End = ps.Pos, end
+		ps.Pos = end
+	})
+}
+
+// scanIntDigits returns the end of the run of integer digits starting at pos, including
+// any base prefix and underscores. Validation is left to strconv.
+func scanIntDigits(input string, pos int) int {
+	if pos+1 < len(input) && input[pos] == '0' {
+		switch input[pos+1] {
+		case 'x', 'X':
+			end := pos + 2
+			for end < len(input) && (isHexDigit(input[end]) || input[end] == '_') {
+				end++
+			}
+			if end > pos+2 {
+				return end
+			}
+		case 'o', 'O', 'b', 'B':
+			if end := scanDecimalDigits(input, pos+2); end > pos+2 {
+				return end
+			}
+		}
+	}
+	// Without digits after it a prefix isn't one, so 0x is the integer 0 followed by x.
+	return scanDecimalDigits(input, pos)
+}
+
+// scanDecimalDigits returns the end of the run of decimal digits and underscores starting at pos.
+func scanDecimalDigits(input string, pos int) int {
+	for pos < len(input) && (input[pos] >= '0' && input[pos] <= '9' || input[pos] == '_') {
+		pos++
+	}
+	return pos
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// errInexact is the error of a float literal that a float64 can't hold exactly.
+var errInexact = errors.New("inexact float")
+
+// overflowInt returns what to bind for the integer literal tok in base, which failed to parse
+// with the range error err, under policy.
+func overflowInt(tok string, base int, policy OverflowPolicy, saturated interface{}, err error) (interface{}, error) {
+	switch policy {
+	case OverflowSaturate:
+		return saturated, nil
+	case OverflowBig:
+		if v, ok := new(big.Int).SetString(tok, base); ok {
+			return v, nil
+		}
+	case OverflowRaw:
+		return tok, nil
+	}
+	return nil, err
+}
+
+// parseFloat parses the float literal tok, applying policy if it is out of range or, with
+// exact, can't be held exactly by a float64.
+func parseFloat(tok string, policy OverflowPolicy, exact bool) (interface{}, error) {
+	v, err := strconv.ParseFloat(tok, 64)
+	if err == nil && (!exact || exactFloat(tok) || policy == OverflowSaturate) {
+		return v, nil
+	}
+	if err != nil && !isRangeErr(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = errInexact
+	}
+
+	switch policy {
+	case OverflowSaturate:
+		return v, nil
+	case OverflowBig:
+		// Four bits a byte covers every decimal digit.
+		prec := uint(len(tok)) * 4
+		if prec < 64 {
+			prec = 64
+		}
+		if f, _, err := new(big.Float).SetPrec(prec).Parse(tok, 0); err == nil {
+			return f, nil
+		}
+	case OverflowRaw:
+		return tok, nil
+	}
+	return nil, err
+}
+
+// exactFloat returns whether the float literal tok is held exactly by a float64.
+func exactFloat(tok string) bool {
+	f, _, err := new(big.Float).SetPrec(53).Parse(tok, 0)
+	if err != nil || f.Acc() != big.Exact {
+		return false
+	}
+	_, acc := f.Float64()
+	return acc == big.Exact
+}
+
+func isRangeErr(err error) bool {
+	return errors.Is(err, strconv.ErrRange)
+}
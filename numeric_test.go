@@ -0,0 +1,177 @@
+package goparsify
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInt(t *testing.T) {
+	parser := Int(IntOptions{})
+
+	tests := map[string]int64{
+		"1234":      1234,
+		"+12":       12,
+		"-12":       -12,
+		"1_000_000": 1000000,
+		"0x1F":      31,
+		"0XdEaD":    0xdead,
+		"0o17":      15,
+		"017":       15,
+		"0b101":     5,
+		"-0x10":     -16,
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored())
+			require.Equal(t, expected, result.Result)
+			require.Equal(t, input, result.Token)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	t.Run("partial match", func(t *testing.T) {
+		result, p := runParser("12.5", parser)
+		require.Equal(t, int64(12), result.Result)
+		require.Equal(t, ".5", p.Get())
+	})
+
+	t.Run("prefix without digits", func(t *testing.T) {
+		result, p := runParser("0x", parser)
+		require.False(t, p.Errored())
+		require.Equal(t, int64(0), result.Result)
+		require.Equal(t, "0", result.Token)
+		require.Equal(t, "x", p.Get())
+
+		result, p = runParser("0b;", parser)
+		require.Equal(t, int64(0), result.Result)
+		require.Equal(t, "b;", p.Get())
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("foo", parser)
+		require.Equal(t, "offset 0: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("misplaced underscore", func(t *testing.T) {
+		_, p := runParser("1__0", parser)
+		require.Equal(t, "offset 0: expected integer", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("overflow errors by default", func(t *testing.T) {
+		_, p := runParser("9223372036854775808", parser)
+		require.Equal(t, "offset 0: expected integer in range", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("overflow saturates", func(t *testing.T) {
+		saturating := Int(IntOptions{Overflow: OverflowSaturate})
+		result, _ := runParser("9223372036854775808", saturating)
+		require.Equal(t, int64(math.MaxInt64), result.Result)
+
+		result, _ = runParser("-9223372036854775809", saturating)
+		require.Equal(t, int64(math.MinInt64), result.Result)
+	})
+
+	t.Run("overflow binds big ints", func(t *testing.T) {
+		result, p := runParser("0x1_0000_0000_0000_0000", Int(IntOptions{Overflow: OverflowBig}))
+		require.Equal(t, "", p.Get())
+		want, _ := new(big.Int).SetString("18446744073709551616", 10)
+		require.Equal(t, want, result.Result)
+
+		result, _ = runParser("12", Int(IntOptions{Overflow: OverflowBig}))
+		require.Equal(t, int64(12), result.Result)
+	})
+
+	t.Run("overflow keeps the literal", func(t *testing.T) {
+		result, _ := runParser("-99999999999999999999", Int(IntOptions{Overflow: OverflowRaw}))
+		require.Equal(t, "-99999999999999999999", result.Result)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		unsigned := Int(IntOptions{Unsigned: true})
+		result, p := runParser("18446744073709551615", unsigned)
+		require.Equal(t, uint64(math.MaxUint64), result.Result)
+		require.Equal(t, "", p.Get())
+
+		_, p = runParser("-1", unsigned)
+		require.Equal(t, "offset 0: expected integer", p.Error.Error())
+	})
+}
+
+func TestFloat(t *testing.T) {
+	parser := Float(FloatOptions{})
+
+	tests := map[string]float64{
+		"12":         12,
+		"12.34":      12.34,
+		"+1.5":       1.5,
+		"-.25":       -.25,
+		"1_000.5":    1000.5,
+		"2.5E-3":     2.5e-3,
+		"1e10":       1e10,
+		"-12.34e+02": -1234,
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			result, p := runParser(input, parser)
+			require.False(t, p.Errored())
+			require.Equal(t, expected, result.Result)
+			require.Equal(t, "", p.Get())
+		})
+	}
+
+	t.Run("exponent without digits is left behind", func(t *testing.T) {
+		result, p := runParser("1else", parser)
+		require.Equal(t, float64(1), result.Result)
+		require.Equal(t, "else", p.Get())
+	})
+
+	t.Run("non matching string", func(t *testing.T) {
+		_, p := runParser("-.", parser)
+		require.Equal(t, "offset 0: expected float", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("overflow errors by default", func(t *testing.T) {
+		_, p := runParser("1e400", parser)
+		require.Equal(t, "offset 0: expected float in range", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+
+	t.Run("overflow saturates", func(t *testing.T) {
+		result, _ := runParser("-1e400", Float(FloatOptions{Overflow: OverflowSaturate}))
+		require.Equal(t, math.Inf(-1), result.Result)
+	})
+
+	t.Run("overflow binds big floats", func(t *testing.T) {
+		result, _ := runParser("1.5e400", Float(FloatOptions{Overflow: OverflowBig}))
+		f, ok := result.Result.(*big.Float)
+		require.True(t, ok)
+		require.Equal(t, "1.5e+400", f.Text('g', 10))
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		exact := Float(FloatOptions{Exact: true})
+		result, p := runParser("2.5", exact)
+		require.False(t, p.Errored())
+		require.Equal(t, 2.5, result.Result)
+
+		_, p = runParser("0.1", exact)
+		require.Equal(t, "offset 0: expected exact float", p.Error.Error())
+
+		_, p = runParser("9007199254740993", exact)
+		require.Equal(t, "offset 0: expected exact float", p.Error.Error())
+
+		result, _ = runParser("0.1", Float(FloatOptions{Exact: true, Overflow: OverflowRaw}))
+		require.Equal(t, "0.1", result.Result)
+
+		result, _ = runParser("0.1", Float(FloatOptions{Exact: true, Overflow: OverflowSaturate}))
+		require.Equal(t, 0.1, result.Result)
+	})
+}
@@ -0,0 +1,232 @@
+package goparsify
+
+import "io"
+
+// Option configures a single call to Run. Options only affect the parse they are passed to,
+// so concurrent parses can use different settings.
+type Option func(*runConfig)
+
+type runConfig struct {
+	ws       VoidParser
+	trace    io.Writer
+	traceOut io.Writer
+	attempts io.Writer
+	hooks    Hooks
+	longest  bool
+	prune    bool
+	pool     bool
+	trivia   bool
+	user     interface{}
+	source   string
+	resolver func(kind, name string) bool
+
+	allowTrailing bool
+	maxDepth      int
+	maxInput      int
+	maxToken      int
+	maxChildren   int
+	metricsName   string
+	metrics       Metrics
+	invalidUTF8   UTF8Policy
+	normalize     func(string) string
+	decoder       Decoder
+	withoutBOM    bool
+	newlines      bool
+	continuations []string
+	lexer         *Lexer
+	branchBudget  int
+	// tokens is set by RunTokens to parse them in place of the input.
+	tokens []Token
+
+	maxParses       int
+	maxExplorations int
+	// ambiguity is set by RunAllParses for each run of the grammar.
+	ambiguity *choiceExplorer
+}
+
+// defaultRunConfig is the configuration of runs without options, shared so they don't make
+// one. It must never be changed.
+var defaultRunConfig = &runConfig{}
+
+func newRunConfig(opts []Option) *runConfig {
+	cfg := &runConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// apply sets up a freshly created State to parse with the configuration.
+func (cfg *runConfig) apply(ps *State) {
+	if cfg.ws != nil {
+		ps.WS = cfg.ws
+	}
+	if len(cfg.continuations) > 0 {
+		ps.WS = skipContinuations(ps.WS, cfg.continuations)
+	}
+	if cfg.lexer != nil {
+		ps.lexer = newLexerScan(cfg.lexer, ps.WS)
+		ps.WS = ps.lexer.skip
+	}
+	if cfg.trace != nil {
+		ps.trace = newTracer(cfg.trace)
+	}
+	if cfg.attempts != nil {
+		ps.attempts = newAttemptRecorder(cfg.attempts)
+	}
+	ps.user = cfg.user
+	ps.source = cfg.source
+	ps.config = cfg
+}
+
+// WithWhitespace sets the parser used to skip whitespace before each token. The default is
+// UnicodeWhitespace, ASCIIWhitespace is faster if you don't need it.
+func WithWhitespace(ws VoidParser) Option {
+	return func(cfg *runConfig) {
+		cfg.ws = ws
+	}
+}
+
+// WithLineContinuation makes the whitespace skipped before each token take in line
+// continuations too, a backslash and a newline unless other sequences are given, so a grammar
+// where newlines matter, like a shell's or a Makefile's, can have lines continued anywhere
+// without a rule between every pair of tokens:
+//
+//	Run(command, input, WithWhitespace(HorizontalWhitespace), WithLineContinuation())
+func WithLineContinuation(sequences ...string) Option {
+	if len(sequences) == 0 {
+		sequences = []string{"\\\r\n", "\\\n"}
+	}
+	return func(cfg *runConfig) {
+		cfg.continuations = sequences
+	}
+}
+
+// WithTrace writes an indented tree of every parser entered and exited during the parse to w,
+// showing what each one consumed and how long it took. Like EnableLogging it only has an
+// effect when built with -tags debug: without the tag it is a no-op and w is never written
+// to. Trace works in every build.
+func WithTrace(w io.Writer) Option {
+	return func(cfg *runConfig) {
+		cfg.trace = w
+	}
+}
+
+// WithTraceOutput sets where the parsers wrapped with Trace write to, instead of os.Stderr.
+func WithTraceOutput(w io.Writer) Option {
+	return func(cfg *runConfig) {
+		cfg.traceOut = w
+	}
+}
+
+// WithAttempts writes every parser run during the parse to w as a line of JSON holding the parser,
+// where it started and ended and whether it matched. Read them back with ReadAttempts and pass
+// them to RenderAttempts to see where the parser backtracks. Like WithTrace it only has an
+// effect when built with -tags debug: without the tag it is a no-op and w is never written to.
+func WithAttempts(w io.Writer) Option {
+	return func(cfg *runConfig) {
+		cfg.attempts = w
+	}
+}
+
+// WithHooks calls h around every parser run during the parse. Like WithTrace it only has an
+// effect when built with -tags debug: without the tag it is a no-op and h is never called, as
+// parsers aren't instrumented at all then to keep them fast.
+func WithHooks(h Hooks) Option {
+	return func(cfg *runConfig) {
+		cfg.hooks = h
+	}
+}
+
+// WithMaxParses sets the number of parses RunAllParses looks for before giving up on finding
+// more. The default is 100. Run ignores it.
+func WithMaxParses(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.maxParses = n
+	}
+}
+
+// WithMaxExplorations sets the number of times RunAllParses runs the grammar, once for each
+// combination of Any branches, before giving up on exploring more. The default is 10000. Run
+// ignores it.
+func WithMaxExplorations(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.maxExplorations = n
+	}
+}
+
+// WithLongestMatch makes every Any act like Longest, trying all of its branches and taking
+// the one that consumes the most input. It suits tokenizer style grammars where maximal munch
+// is wanted everywhere, without rewriting them.
+func WithLongestMatch() Option {
+	return func(cfg *runConfig) {
+		cfg.longest = true
+	}
+}
+
+// WithPruneEmpty makes every Seq leave out the children that matched nothing but whitespace
+// and carry no name, result or annotations, like a Maybe that didn't match. See PruneEmpty to
+// do this for a single parser.
+func WithPruneEmpty() Option {
+	return func(cfg *runConfig) {
+		cfg.prune = true
+	}
+}
+
+// WithPooling makes Seq and Many take the slices they keep their children in from the ones
+// given back by Result.Release, for services that parse on every request and release each tree
+// when they're done with it.
+func WithPooling() Option {
+	return func(cfg *runConfig) {
+		cfg.pool = true
+	}
+}
+
+// WithTrivia makes RunTree keep the input around each result that isn't part of its
+// children, like whitespace and separators, so Reconstruct can rebuild the input exactly.
+func WithTrivia() Option {
+	return func(cfg *runConfig) {
+		cfg.trivia = true
+	}
+}
+
+// WithUserState sets the state the grammar starts the parse with, see State.SetUserState.
+func WithUserState(state interface{}) Option {
+	return func(cfg *runConfig) {
+		cfg.user = state
+	}
+}
+
+// WithSourceName names the input, eg after the file it was read from, for errors in sources
+// it includes to say where they were included from, see Include.
+func WithSourceName(name string) Option {
+	return func(cfg *runConfig) {
+		cfg.source = name
+	}
+}
+
+// WithResolver sets the callback Resolved asks whether a name of some kind exists, eg a column
+// in a schema, so unknown names are errors where they are in the input rather than found after
+// the parse.
+func WithResolver(resolve func(kind, name string) bool) Option {
+	return func(cfg *runConfig) {
+		cfg.resolver = resolve
+	}
+}
+
+// AllowTrailingInput makes Run and RunTree succeed when the parser matches a prefix of the
+// input, rather than returning an UnparsedInputError for the rest.
+func AllowTrailingInput() Option {
+	return func(cfg *runConfig) {
+		cfg.allowTrailing = true
+	}
+}
+
+// WithMaxDepth limits how deeply references to parsers, eg the &value in a recursive grammar,
+// can nest, so deeply nested input fails with an error instead of exhausting the stack. Zero,
+// the default, means no limit.
+func WithMaxDepth(n int) Option {
+	return func(cfg *runConfig) {
+		cfg.maxDepth = n
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/ijt/goparsify/otelmetrics
+
+go 1.20
+
+require (
+	github.com/ijt/goparsify v0.0.0
+	github.com/stretchr/testify v1.4.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
+	go.opentelemetry.io/otel/sdk/metric v1.24.0
+)
+
+replace github.com/ijt/goparsify => ../
@@ -0,0 +1,62 @@
+// Package otelmetrics records the parses run with goparsify.WithMetrics as OpenTelemetry metrics.
+// It is a module of its own so that goparsify doesn't depend on OpenTelemetry:
+//
+//	m, err := otelmetrics.New(otel.Meter("example.com/config"))
+//	if err != nil {
+//		return err
+//	}
+//	result, _, err := goparsify.Run(grammar, input, goparsify.WithMetrics("config", m))
+package otelmetrics
+
+import (
+	"context"
+
+	"github.com/ijt/goparsify"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metrics records parses with the instruments goparsify.parses and goparsify.errors, counting
+// them, goparsify.input_bytes, the size of their input, and goparsify.duration, how long they
+// took in seconds. Each has the attribute parser set to the name given to WithMetrics.
+type Metrics struct {
+	parses     metric.Int64Counter
+	errors     metric.Int64Counter
+	inputBytes metric.Int64Histogram
+	duration   metric.Float64Histogram
+}
+
+var _ goparsify.Metrics = (*Metrics)(nil)
+
+// New makes the instruments with meter, failing if it can't make one of them.
+func New(meter metric.Meter) (*Metrics, error) {
+	parses, err := meter.Int64Counter("goparsify.parses", metric.WithDescription("Parses run."))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter("goparsify.errors", metric.WithDescription("Parses that failed."))
+	if err != nil {
+		return nil, err
+	}
+	inputBytes, err := meter.Int64Histogram("goparsify.input_bytes", metric.WithDescription("Size of the input parsed."), metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("goparsify.duration", metric.WithDescription("Time taken to parse."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &Metrics{parses: parses, errors: errors, inputBytes: inputBytes, duration: duration}, nil
+}
+
+// ObserveParse records the parse under the attribute parser=name.
+func (m *Metrics) ObserveParse(name string, r goparsify.ParseReport) {
+	ctx := context.Background()
+	attrs := metric.WithAttributes(attribute.String("parser", name))
+	m.parses.Add(ctx, 1, attrs)
+	if r.Err != nil {
+		m.errors.Add(ctx, 1, attrs)
+	}
+	m.inputBytes.Record(ctx, int64(r.InputBytes), attrs)
+	m.duration.Record(ctx, r.Took.Seconds(), attrs)
+}
@@ -0,0 +1,56 @@
+package otelmetrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	require.NoError(t, err)
+
+	_, _, err = goparsify.Run(goparsify.NumberLit(), "12", goparsify.WithMetrics("number", m))
+	require.NoError(t, err)
+	_, _, err = goparsify.Run(goparsify.NumberLit(), "x", goparsify.WithMetrics("number", m))
+	require.Error(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	data := map[string]metricdata.Aggregation{}
+	for _, metrics := range rm.ScopeMetrics[0].Metrics {
+		data[metrics.Name] = metrics.Data
+	}
+
+	require.Equal(t, int64(2), counted(t, data["goparsify.parses"], "number"))
+	require.Equal(t, int64(1), counted(t, data["goparsify.errors"], "number"))
+
+	inputBytes, ok := data["goparsify.input_bytes"].(metricdata.Histogram[int64])
+	require.True(t, ok)
+	require.Len(t, inputBytes.DataPoints, 1)
+	require.Equal(t, uint64(2), inputBytes.DataPoints[0].Count)
+	require.Equal(t, int64(3), inputBytes.DataPoints[0].Sum)
+
+	duration, ok := data["goparsify.duration"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, duration.DataPoints, 1)
+	require.Equal(t, uint64(2), duration.DataPoints[0].Count)
+}
+
+// counted returns the count of the counter data for the parser called name.
+func counted(t *testing.T, data metricdata.Aggregation, name string) int64 {
+	sum, ok := data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	for _, p := range sum.DataPoints {
+		if v, _ := p.Attributes.Value("parser"); v.AsString() == name {
+			return p.Value
+		}
+	}
+	return 0
+}
@@ -2,9 +2,12 @@ package goparsify
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode/utf8"
 )
 
@@ -14,6 +17,11 @@ import (
 //   - A parser that errors must set state.Error
 //   - A parser that errors must not change state.Pos
 //   - A parser that consumed some input should advance state.Pos
+//
+// The parsers in this package keep everything that changes during a parse in the State, so once
+// a grammar has been built it can be run from any number of goroutines at once. Parsers written
+// by hand should do the same, keeping what they need in the State with SetUserState rather than
+// in variables they close over.
 type Parser func(*State, *Result)
 
 // Map shorthand for Map(p, func())
@@ -25,7 +33,9 @@ func (p Parser) Map(f func(n *Result)) Parser {
 type VoidParser func(*State)
 
 // Parserish types are any type that can be turned into a Parser by Parsify
-// These currently include *Parser and string literals.
+// These currently include *Parser, string literals, *regexp.Regexp, which is anchored to
+// match where the parser is, and matchers of type func(input string) (consumed int, ok bool),
+// see Matcher.
 //
 // This makes recursive grammars cleaner and allows string literals to be used directly in most contexts.
 // eg, matching balanced paren:
@@ -43,27 +53,58 @@ type Parserish interface{}
 // any Parser that accepts a Parser as an argument. It should never be called during
 // instead call it during parser creation so there is no runtime cost.
 //
-// See Parserish for details.
+// It panics if p can't be turned into a parser, see TryParsify. See Parserish for details.
 func Parsify(p Parserish) Parser {
+	parser, err := TryParsify(p)
+	if err != nil {
+		panic(err)
+	}
+	return parser
+}
+
+// TryParsify is like Parsify but returns an error if p can't be turned into a parser, eg an int
+// or a nil *Parser, for code building grammars out of values it didn't write itself.
+func TryParsify(p Parserish) (Parser, error) {
+	if p == nil {
+		return nil, fmt.Errorf("cant turn nil into a parser")
+	}
+	if v := reflect.ValueOf(p); (v.Kind() == reflect.Func || v.Kind() == reflect.Ptr) && v.IsNil() {
+		return nil, fmt.Errorf("cant turn a nil `%T` into a parser", p)
+	}
+
 	switch p := p.(type) {
 	case func(*State, *Result):
-		return p
+		return p, nil
 	case Parser:
-		return p
+		return p, nil
 	case *Parser:
-		// Todo: Maybe capture this stack and on nil show it? Is there a good error library to do this?
 		return func(ptr *State, node *Result) {
+			if *p == nil {
+				panic(fmt.Errorf("a `*Parser` was used before the parser it points to was set"))
+			}
+			if ptr.cfg().maxDepth > 0 && ptr.depth >= ptr.cfg().maxDepth && ptr.analysis == nil {
+				// No parser may backtrack out of the error into more nesting.
+				ptr.fatalError(ptr.Pos, fmt.Sprintf("input nested at most %d deep", ptr.cfg().maxDepth))
+				return
+			}
+			ptr.depth++
 			(*p)(ptr, node)
-		}
+			ptr.depth--
+		}, nil
 	case string:
-		return Exact(p)
+		return Exact(p), nil
+	case *regexp.Regexp:
+		return regexParser(p.String(), "/"+p.String()+"/", anchoredRegex(p.String())), nil
+	case func(string) (int, bool):
+		return Matcher("custom match", p), nil
+	case optionalSignal:
+		return Maybe(p.parser), nil
 	case func(*State):
 		return func(ptr *State, node *Result) {
 			p(ptr)
-		}
-	default:
-		panic(fmt.Errorf("cant turn a `%T` into a parser", p))
+		}, nil
 	}
+	return nil, fmt.Errorf("cant turn a `%T` into a parser", p)
 }
 
 // ParsifyAll calls Parsify on all parsers
@@ -78,43 +119,229 @@ func ParsifyAll(parsers ...Parserish) []Parser {
 // Run applies some input to a parser and returns the result, failing if the input isnt fully consumed.
 // It is a convenience method for the most common way to invoke a parser.
 // The parsedStr return value is the subset of the input string that was parsed.
-func Run(parser Parserish, input string, ws ...VoidParser) (result interface{}, parsedStr string, err error) {
+// See Option for ways to configure the parse.
+func Run(parser Parserish, input string, opts ...Option) (result interface{}, parsedStr string, err error) {
+	ret, err := RunTree(parser, input, opts...)
+	return ret.Result, ret.Token, err
+}
+
+// RunTree is like Run but returns the whole Result tree, eg to Dump it, walk it or rebuild the
+// input from it with Reconstruct.
+func RunTree(parser Parserish, input string, opts ...Option) (Result, error) {
+	cfg := defaultRunConfig
+	if len(opts) > 0 {
+		cfg = newRunConfig(opts)
+	}
+	ret, _, err := run(parser, input, cfg)
+	return ret, err
+}
+
+// RunPrefix is like Run with AllowTrailingInput, parsing a prefix of the input and returning
+// the rest of it after any whitespace, eg to parse one expression off a line and carry on
+// with what follows.
+func RunPrefix(parser Parserish, input string, opts ...Option) (result interface{}, rest string, err error) {
+	cfg := newRunConfig(opts)
+	cfg.allowTrailing = true
+	ret, ps, err := run(parser, input, cfg)
+	if err != nil {
+		return nil, input, err
+	}
+	return ret.Result, ps.Get(), nil
+}
+
+// MustRun is like Run but panics if the parse fails, for tests and for initializing package
+// variables from literals known to be valid.
+func MustRun(parser Parserish, input string, opts ...Option) interface{} {
+	result, _, err := Run(parser, input, opts...)
+	if err != nil {
+		panic(fmt.Errorf("goparsify: parsing %q: %w", input, err))
+	}
+	return result
+}
+
+// RunAs is like Run but returns the result as a T, failing if it is something else, eg
+// RunAs[int64](NumberLit(), "42").
+func RunAs[T any](parser Parserish, input string, opts ...Option) (T, error) {
+	var zero T
+	result, _, err := Run(parser, input, opts...)
+	if err != nil {
+		return zero, err
+	}
+	value, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("result is a %T, not a %T", result, zero)
+	}
+	return value, nil
+}
+
+func run(parser Parserish, input string, cfg *runConfig) (Result, *State, error) {
+	if cfg.metrics == nil {
+		return runParse(parser, input, cfg)
+	}
+	start := time.Now()
+	ret, ps, err := runParse(parser, input, cfg)
+	cfg.metrics.ObserveParse(cfg.metricsName, ParseReport{
+		InputBytes: len(input),
+		Consumed:   ps.offset(ps.Pos),
+		Took:       time.Since(start),
+		Err:        err,
+	})
+	return ret, ps, err
+}
+
+func runParse(parser Parserish, input string, cfg *runConfig) (Result, *State, error) {
+	text, offsets, err := prepareInput(input, cfg)
+	if err != nil {
+		return Result{}, NewState(input), err
+	}
+	ret, ps, err := parseInput(parser, text, cfg, input, offsets)
+	offsets.mapResult(ret)
+	if len(ps.Error.sources) == 0 {
+		// Errors in included sources were mapped by Include.
+		ps.Error.mapOffsets(offsets.originalOffset)
+		if len(offsets) > 0 && ps.Error.indexed {
+			ps.Error.index(input)
+		}
+	}
+	return *ret, ps, err
+}
+
+// prepareInput decodes and normalizes input as configured, returning the text to parse and
+// how to map offsets into it back to the input.
+func prepareInput(input string, cfg *runConfig) (string, offsetMaps, error) {
+	if err := checkInputSize(input, cfg); err != nil {
+		return "", nil, err
+	}
+	if cfg.tokens != nil {
+		if cfg.changesInput() {
+			return "", nil, errChangesTokenSource
+		}
+		return input, nil, nil
+	}
+	var offsets offsetMaps
+	if cfg.decoder != nil {
+		decoded, m, err := decodeInput(input, cfg.decoder)
+		if err != nil {
+			return "", nil, err
+		}
+		input, offsets = decoded, append(offsets, m)
+	}
+	if cfg.withoutBOM {
+		if stripped, m := stripBOM(input); m != nil {
+			input, offsets = stripped, append(offsets, m)
+		}
+	}
+	if cfg.newlines {
+		if normalized, m := normalizeNewlines(input); m != nil {
+			input, offsets = normalized, append(offsets, m)
+		}
+	}
+	if cfg.normalize != nil {
+		normalized, m := normalizeInput(input, cfg.normalize)
+		input, offsets = normalized, append(offsets, m)
+	}
+	return input, offsets, nil
+}
+
+// parseInput parses input, the text prepared from original, whose offsets map back to it. The
+// Result is returned by reference so that mapping it doesn't make another.
+func parseInput(parser Parserish, input string, cfg *runConfig, original string, offsets offsetMaps) (*Result, *State, error) {
 	p := Parsify(parser)
+	ret := &Result{}
+	ps, err := startParse(input, cfg, original, offsets)
+	if err != nil {
+		return ret, ps, err
+	}
+
+	p(ps, ret)
+	if err := finishParse(ps, cfg); err != nil {
+		return ret, ps, err
+	}
+
+	if cfg.trivia {
+		addTrivia(ret, ps.Input)
+	}
+	return ret, ps, nil
+}
+
+// startParse creates the State to parse input, the text prepared from original, with, failing
+// if the input is rejected for being invalid UTF-8.
+func startParse(input string, cfg *runConfig, original string, offsets offsetMaps) (*State, error) {
+	if cfg.invalidUTF8 == ReplaceInvalidUTF8 {
+		input = replaceInvalidUTF8(input)
+	}
 	ps := NewState(input)
-	if len(ws) > 0 {
-		ps.WS = ws[0]
+	cfg.apply(ps)
+	ps.original, ps.offsets = original, offsets
+	if cfg.tokens != nil {
+		ps.WS = NoWhitespace
+		ps.tokens = cfg.tokens
+	}
+
+	if cfg.invalidUTF8 == RejectInvalidUTF8 {
+		if i := invalidUTF8(input); i >= 0 {
+			ps.Error = Error{pos: i, expected: "valid UTF-8"}
+			ps.indexError()
+			return ps, &ps.Error
+		}
 	}
+	return ps, nil
+}
 
-	ret := Result{}
-	p(ps, &ret)
+// finishParse returns the error the parse ended with, if any, or whether it left input
+// unparsed.
+func finishParse(ps *State, cfg *runConfig) error {
 	ps.WS(ps)
 
 	if ps.Error.expected != "" {
-		return ret.Result, ret.Token, &ps.Error
+		ps.Error.eof = ps.Error.pos >= ps.length() && len(ps.Error.sources) == 0
+		ps.Error.incomplete = ps.Error.eof || ps.furthest >= ps.length()
+		if ps.tokens != nil {
+			ps.Error.mapOffsets(ps.tokenOffset)
+		}
+		ps.indexError()
+		return &ps.Error
 	}
 
-	if ps.Get() != "" {
-		return ret.Result, ret.Token, UnparsedInputError{ps.Get()}
+	if ps.Pos < ps.length() && !cfg.allowTrailing {
+		return UnparsedInputError{remaining: ps.Input[ps.offset(ps.Pos):], incomplete: ps.furthest >= ps.length()}
 	}
-
-	return ret.Result, ret.Token, nil
+	return nil
 }
 
 // Cut prevents backtracking beyond this point. Usually used after keywords when you
 // are sure this is the correct path. Improves performance and error reporting.
 func Cut() Parser {
 	return func(ps *State, node *Result) {
-		ps.Cut = ps.Pos
+		if ps.analysis != nil {
+			ps.analysis.terminal("")
+		}
+		ps.Commit()
 	}
 }
 
 // NamedRegex works like Regex except that it takes a name that is used in
 // error messages. This is expecially helpful when the pattern is long.
 func NamedRegex(name, pattern string) Parser {
-	re := mustCompile("^(" + pattern + ")")
-	return NewParser(pattern, func(ps *State, node *Result) {
+	description := name
+	if name == pattern {
+		description = "/" + pattern + "/"
+	}
+	return regexParser(name, description, anchoredRegex(pattern))
+}
+
+// regexParser matches re, which must come from anchoredRegex, expecting name.
+func regexParser(name, description string, re *regexp.Regexp) Parser {
+	return NewParser(name, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
 		ps.WS(ps)
 		if match := re.FindString(ps.Get()); match != "" {
+			if ps.tokenTooLong(ps.Pos, len(match)) {
+				return
+			}
+			node.Start, node.End = ps.Pos, ps.Pos+len(match)
 			ps.Advance(len(match))
 			node.Token = match
 			return
@@ -123,19 +350,18 @@ func NamedRegex(name, pattern string) Parser {
 	})
 }
 
-var patToRegexMu sync.Mutex
-var patToRegex = make(map[string]*regexp.Regexp)
+// regexCache holds the regexps compiled by anchoredRegex by pattern, so grammars built over and
+// over, eg one per request, compile each pattern once.
+var regexCache sync.Map
 
-func mustCompile(pat string) *regexp.Regexp {
-	patToRegexMu.Lock()
-	defer patToRegexMu.Unlock()
-	rx, ok := patToRegex[pat]
-	if ok {
-		return rx
+// anchoredRegex compiles pattern so it can only match at the start of the input, and never
+// scans past it. It anchors with \A rather than ^ so flags like (?m) can't loosen it.
+func anchoredRegex(pattern string) *regexp.Regexp {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
 	}
-	rx = regexp.MustCompile(pat)
-	patToRegex[pat] = rx
-	return rx
+	re, _ := regexCache.LoadOrStore(pattern, regexp.MustCompile(`\A(?:`+pattern+`)`))
+	return re.(*regexp.Regexp)
 }
 
 // Regex returns a match if the regex successfully matches
@@ -143,15 +369,79 @@ func Regex(pattern string) Parser {
 	return NamedRegex(pattern, pattern)
 }
 
+// RegexGroups is like Regex but also sets a child for each capture group of the pattern, with
+// the text the group matched in .Token and the group's name, if it has one, in .Name, so it can
+// be looked up with Result.Get. Groups that took no part in the match are left empty.
+func RegexGroups(pattern string) Parser {
+	re := anchoredRegex(pattern)
+	names := re.SubexpNames()
+
+	return NewParser(pattern, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("/" + pattern + "/")
+		}
+		ps.WS(ps)
+		loc := re.FindStringSubmatchIndex(ps.Get())
+		if loc == nil || loc[1] == 0 {
+			ps.ErrorHere(pattern)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, loc[1]) {
+			return
+		}
+
+		node.Child = make([]Result, len(names)-1)
+		for i := range node.Child {
+			group := &node.Child[i]
+			group.Name = names[i+1]
+			group.Start, group.End = ps.Pos, ps.Pos
+			if start, end := loc[2*i+2], loc[2*i+3]; start >= 0 {
+				group.Token = ps.Input[ps.Pos+start : ps.Pos+end]
+				group.Start, group.End = ps.Pos+start, ps.Pos+end
+			}
+		}
+		node.Token = ps.Input[ps.Pos : ps.Pos+loc[1]]
+		node.Start, node.End = ps.Pos, ps.Pos+loc[1]
+		ps.Advance(loc[1])
+	})
+}
+
+// Matcher turns match into a parser, eg to reuse matching code written without this package.
+// match is given the input after any whitespace and returns how many bytes of it match, or
+// false if it doesn't. The match will be stored in .Token and name is used in error messages.
+func Matcher(name string, match func(input string) (consumed int, ok bool)) Parser {
+	return NewParser(name, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(name)
+		}
+		ps.WS(ps)
+		n, ok := match(ps.Get())
+		if !ok {
+			ps.ErrorHere(name)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, n) {
+			return
+		}
+		node.Start, node.End = ps.Pos, ps.Pos+n
+		node.Token = ps.Input[ps.Pos : ps.Pos+n]
+		ps.Advance(n)
+	})
+}
+
 // Exact will fully match the exact string supplied, or error. The match will be stored in .Token
 func Exact(match string) Parser {
 	return NewParser(match, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.exact(match)
+		}
 		ps.WS(ps)
 		if !strings.HasPrefix(ps.Get(), match) {
 			ps.ErrorHere(match)
 			return
 		}
 
+		node.Start, node.End = ps.Pos, ps.Pos+len(match)
 		ps.Advance(len(match))
 
 		node.Token = match
@@ -159,15 +449,22 @@ func Exact(match string) Parser {
 }
 
 // Insensitive fully matches the exact string supplied without caring about
-// case, or error. The match will be stored in .Token
+// case, or error. The match will be stored in .Token as it was written in the input, and match
+// in .Result, so keywords come out in the spelling the grammar gives them whatever case they
+// were written in.
 func Insensitive(match string) Parser {
 	return NewParser(match, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(strconv.Quote(match) + " in any case")
+		}
 		ps.WS(ps)
 		if !hasPrefixInsensitive(ps.Get(), match) {
 			ps.ErrorHere(match)
 			return
 		}
 		node.Token = ps.Get()[:len(match)]
+		node.Result = match
+		node.Start, node.End = ps.Pos, ps.Pos+len(match)
 		ps.Advance(len(match))
 	})
 }
@@ -195,37 +492,13 @@ func parseRepetition(defaultMin, defaultMax int, repetition ...int) (min int, ma
 	return min, max
 }
 
-// parseMatcher turns a string in the format a-f01234A-F into:
-//   - an alphabet of matches string(01234)
-//   - a set of ranges [][]rune{{'a', 'f'}, {'A', 'F'}}
-func parseMatcher(matcher string) (alphabet string, ranges [][]rune) {
-	runes := []rune(matcher)
-	for i := 0; i < len(runes); {
-		if i+2 < len(runes) && runes[i+1] == '-' && runes[i] != '\\' {
-			start := runes[i]
-			end := runes[i+2]
-			if start <= end {
-				ranges = append(ranges, []rune{start, end})
-			} else {
-				ranges = append(ranges, []rune{end, start})
-			}
-			i += 3 // we just consumed 3 bytes: range start, hyphen, and range end
-			continue
-		} else if i+1 < len(runes) && runes[i] == '\\' {
-			alphabet += string(runes[i+1])
-			i += 2 // we just consumed 2 bytes: escape and the char
-		} else {
-			alphabet += string(runes[i])
-			i++
-		}
-	}
-
-	return alphabet, ranges
-}
-
 // Chars is the swiss army knife of character matches. It can match:
 //   - ranges: Chars("a-z") will match one or more lowercase letter
 //   - alphabets: Chars("abcd") will match one or more of the letters abcd in any order
+//   - unicode classes: Chars(`\p{L}`) will match one or more letters of any script and Chars(`\P{L}`)
+//     anything else. Classes are named as in the Categories, Scripts and Properties tables of the
+//     unicode package, eg \p{Nd} or \p{Greek}, and one letter names can go without braces, eg \pL
+//   - escapes: a \ matches the character after it, eg Chars(`+\-`) matches pluses and minuses
 //   - min and max: Chars("a-z0-9", 4, 6) will match 4-6 lowercase alphanumeric characters
 //
 // the above can be combined in any order
@@ -241,49 +514,90 @@ func NotChars(matcher string, repetition ...int) Parser {
 
 func charsImpl(matcher string, stopOn bool, repetition ...int) Parser {
 	min, max := parseRepetition(1, -1, repetition...)
-	alphabet, ranges := parseMatcher(matcher)
+	class := parseCharClass(matcher)
+	description := charsDescription(matcher, stopOn, min, max)
+	expected := matcher
+	if matcher == "" {
+		// An error expecting "" would be no error at all.
+		expected = description
+		if stopOn {
+			expected = "any character"
+		}
+	}
+	first := func(b byte) bool {
+		// Multibyte runes could be anything as far as their first byte goes.
+		return b >= utf8.RuneSelf || class.contains(rune(b)) != stopOn
+	}
 
 	return func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.chars(description, first, min == 0)
+		}
 		ps.WS(ps)
-		matched := 0
+		// matched counts bytes and count runes, which min and max are in.
+		matched, count := 0, 0
 		for ps.Pos+matched < len(ps.Input) {
-			if max != -1 && matched >= max {
+			if max != -1 && count >= max {
 				break
 			}
 
 			r, w := utf8.DecodeRuneInString(ps.Input[ps.Pos+matched:])
-
-			anyMatched := strings.ContainsRune(alphabet, r)
-			if !anyMatched {
-				for _, rng := range ranges {
-					if r >= rng[0] && r <= rng[1] {
-						anyMatched = true
-					}
-				}
-			}
-
-			if anyMatched == stopOn {
+			if class.contains(r) == stopOn {
 				break
 			}
 
 			matched += w
+			count++
 		}
 
-		if matched < min {
-			ps.ErrorHere(matcher)
+		if count < min {
+			ps.ErrorHere(expected)
+			return
+		}
+		if ps.tokenTooLong(ps.Pos, matched) {
 			return
 		}
 
 		node.Token = ps.Input[ps.Pos : ps.Pos+matched]
+		node.Start, node.End = ps.Pos, ps.Pos+matched
 		ps.Advance(matched)
 	}
 }
 
+// charsDescription describes a Chars or NotChars parser like a regex character class.
+func charsDescription(matcher string, stopOn bool, min, max int) string {
+	class := "[" + matcher + "]"
+	if stopOn {
+		class = "[^" + matcher + "]"
+	}
+	switch {
+	case min == 1 && max == 1:
+		return class
+	case min == 0 && max == -1:
+		return class + "*"
+	case min == 1 && max == -1:
+		return class + "+"
+	case max == -1:
+		return fmt.Sprintf("%s{%d,}", class, min)
+	case min == max:
+		return fmt.Sprintf("%s{%d}", class, min)
+	}
+	return fmt.Sprintf("%s{%d,%d}", class, min, max)
+}
+
 // Until will consume all input until one of the given terminator sequences is found. If you want to stop when seeing
 // single characters see NotChars instead
 func Until(terminators ...string) Parser {
+	var quoted []string
+	for _, terminator := range terminators {
+		quoted = append(quoted, strconv.Quote(terminator))
+	}
+	description := "anything until " + strings.Join(quoted, " or ")
 
 	return NewParser("Until", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
 		startPos := ps.Pos
 	loop:
 		for ps.Pos < len(ps.Input) {
@@ -298,6 +612,11 @@ func Until(terminators ...string) Parser {
 		if ps.Pos == startPos {
 			ps.ErrorHere("something")
 		}
+		if ps.tokenTooLong(startPos, ps.Pos-startPos) {
+			ps.Pos = startPos
+			return
+		}
 		node.Token = ps.Input[startPos:ps.Pos]
+		node.Start, node.End = startPos, ps.Pos
 	})
 }
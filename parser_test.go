@@ -1,6 +1,9 @@
 package goparsify
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -33,9 +36,100 @@ func TestParsify(t *testing.T) {
 		require.Equal(t, "ff", result.Token)
 	})
 
+	t.Run("regexps", func(t *testing.T) {
+		node, ps := runParser("ab12", Parsify(regexp.MustCompile(`(?i)[A-Z]+`)))
+		require.Equal(t, "ab", node.Token)
+		require.Equal(t, "12", ps.Get())
+
+		// The regexp is anchored, so it doesn't skip ahead to a match further on.
+		_, ps = runParser("12ab", Parsify(regexp.MustCompile(`[a-z]+`)))
+		require.Equal(t, "offset 0: expected [a-z]+", ps.Error.Error())
+	})
+
+	t.Run("match funcs", func(t *testing.T) {
+		digits := func(input string) (int, bool) {
+			n := len(input) - len(strings.TrimLeft(input, "0123456789"))
+			return n, n > 0
+		}
+		node, ps := runParser("  123ab", Parsify(digits))
+		require.Equal(t, "123", node.Token)
+		require.Equal(t, 2, node.Start)
+		require.Equal(t, "ab", ps.Get())
+
+		_, ps = runParser("ab", Parsify(digits))
+		require.Equal(t, "offset 0: expected custom match", ps.Error.Error())
+	})
+
 	require.Panics(t, func() {
 		Parsify(1)
 	})
+
+	t.Run("unset *parsers", func(t *testing.T) {
+		var parser Parser
+		parserfied := Parsify(Seq("a", &parser))
+		require.Equal(t, "a `*Parser` was used before the parser it points to was set", panicMessage(func() {
+			runParser("a", parserfied)
+		}))
+	})
+}
+
+// panicMessage returns what f panics with, as a string.
+func panicMessage(f func()) (message string) {
+	defer func() { message = fmt.Sprint(recover()) }()
+	f()
+	return ""
+}
+
+func TestTryParsify(t *testing.T) {
+	parser, err := TryParsify("a")
+	require.NoError(t, err)
+	node, _ := runParser("a", parser)
+	require.Equal(t, "a", node.Token)
+
+	var nilParser Parser
+	var nilPointer *Parser
+	var nilRegexp *regexp.Regexp
+	tests := map[string]struct {
+		parserish Parserish
+		err       string
+	}{
+		"int":         {1, "cant turn a `int` into a parser"},
+		"nil":         {nil, "cant turn nil into a parser"},
+		"nil Parser":  {nilParser, "cant turn a nil `goparsify.Parser` into a parser"},
+		"nil pointer": {nilPointer, "cant turn a nil `*goparsify.Parser` into a parser"},
+		"nil regexp":  {nilRegexp, "cant turn a nil `*regexp.Regexp` into a parser"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser, err := TryParsify(test.parserish)
+			require.Nil(t, parser)
+			require.EqualError(t, err, test.err)
+			require.Panics(t, func() { Parsify(test.parserish) })
+		})
+	}
+
+	t.Run("construction", func(t *testing.T) {
+		require.Equal(t, "cant turn a nil `goparsify.Parser` into a parser", panicMessage(func() { Seq("a", nilParser) }))
+		require.Equal(t, "Any() needs at least one parser", panicMessage(func() { Any() }))
+		require.Panics(t, func() { Longest() })
+		require.Panics(t, func() { AdaptiveAny() })
+	})
+}
+
+func TestMatcher(t *testing.T) {
+	word := Matcher("word", func(input string) (int, bool) {
+		i := strings.IndexByte(input, ' ')
+		if i < 0 {
+			i = len(input)
+		}
+		return i, i > 0
+	})
+	result, err := RunTree(Many(word), "hello big world")
+	require.NoError(t, err)
+	assertSequence(t, result, "hello", "big", "world")
+
+	_, err = RunTree(Seq(word, word), "hello")
+	require.EqualError(t, err, "offset 5: unexpected end of input, expected word")
 }
 
 func TestParsifyAll(t *testing.T) {
@@ -95,6 +189,17 @@ func TestInsensitive(t *testing.T) {
 		require.Equal(t, "obar", ps.Get())
 	})
 
+	t.Run("keeps the spelling given in Result", func(t *testing.T) {
+		node, _ := runParser("Select", Insensitive("SELECT"))
+		require.Equal(t, "Select", node.Token)
+		require.Equal(t, "SELECT", node.Result)
+
+		tree, err := RunTree(Seq(Insensitive("select"), Insensitive("from")), "SELECT From")
+		require.NoError(t, err)
+		require.Equal(t, "select", tree.Child[0].Result)
+		require.Equal(t, "from", tree.Child[1].Result)
+	})
+
 	t.Run("success char", func(t *testing.T) {
 		node, ps := runParser("foobar", Insensitive("f"))
 		require.Equal(t, "f", node.Token)
@@ -163,6 +268,38 @@ func TestChars(t *testing.T) {
 		require.False(t, ps.Errored())
 	})
 
+	t.Run("unicode classes", func(t *testing.T) {
+		node, ps := runParser("Grüße, 世界", Chars(`\p{L}`))
+		require.Equal(t, "Grüße", node.Token)
+		require.Equal(t, ", 世界", ps.Get())
+
+		node, _ = runParser("αβγ123", Chars(`\p{Greek}\pN`))
+		require.Equal(t, "αβγ123", node.Token)
+
+		node, _ = runParser("١٢٣x", Chars(`\p{Nd}`))
+		require.Equal(t, "١٢٣", node.Token)
+
+		node, _ = runParser("12, ab", Chars(`\P{L}`))
+		require.Equal(t, "12, ", node.Token)
+
+		node, _ = runParser("x😀🙃!", Chars("x\U0001F600-\U0001F64F"))
+		require.Equal(t, "x😀🙃", node.Token)
+
+		defer func() {
+			require.EqualError(t, recover().(error), `unknown unicode class Klingon in "\\p{Klingon}"`)
+		}()
+		Chars(`\p{Klingon}`)
+	})
+
+	t.Run("counts runes", func(t *testing.T) {
+		node, ps := runParser("ééé", Chars("é", 1, 2))
+		require.Equal(t, "éé", node.Token)
+		require.Equal(t, "é", ps.Get())
+
+		_, ps = runParser("é", Chars("é", 2))
+		require.True(t, ps.Errored())
+	})
+
 	t.Run("no match", func(t *testing.T) {
 		_, ps := runParser("ffffff", Chars("0-9"))
 		require.Equal(t, "offset 0: expected 0-9", ps.Error.Error())
@@ -189,6 +326,12 @@ func TestChars(t *testing.T) {
 		require.False(t, ps.Errored())
 	})
 
+	t.Run("test any character at the end of the input", func(t *testing.T) {
+		_, ps := runParser("", NotChars("", 1, 1))
+		require.True(t, ps.Errored())
+		require.Equal(t, "offset 0: expected any character", ps.Error.Error())
+	})
+
 	require.Panics(t, func() {
 		Chars("a-b", 1, 2, 3)
 	})
@@ -234,6 +377,37 @@ func TestRegex(t *testing.T) {
 	})
 }
 
+func TestAnchoredRegex(t *testing.T) {
+	require.Same(t, anchoredRegex("[a-z]+"), anchoredRegex("[a-z]+"))
+
+	// (?m) would let ^ match after the newline, but the anchoring doesn't depend on it.
+	_, ps := runParser("a\nb", Regex("(?m)^b|x"))
+	require.True(t, ps.Errored())
+	_, ps = runParser("a\nb", RegexGroups("(?m)(?:\n|^)b|x"))
+	require.True(t, ps.Errored())
+}
+
+func TestRegexGroups(t *testing.T) {
+	date := RegexGroups(`(?P<year>\d{4})-(?P<month>\d\d)(?:-(\d\d))?`)
+
+	node, ps := runParser(" 2024-02-29 rest", date)
+	require.False(t, ps.Errored())
+	require.Equal(t, "2024-02-29", node.Token)
+	require.Equal(t, "2024", node.Get("year").Token)
+	require.Equal(t, 6, node.Get("month").Start)
+	assertSequence(t, node, "2024", "02", "29")
+	require.Equal(t, "", node.Child[2].Name)
+	require.Equal(t, " rest", ps.Get())
+
+	node, _ = runParser("2024-02", date)
+	assertSequence(t, node, "2024", "02", "")
+	require.Nil(t, node.Get("day"))
+
+	// The pattern is anchored as a whole, not just its first alternative.
+	_, ps = runParser("x1", RegexGroups(`a|(\d)`))
+	require.Equal(t, "offset 0: expected a|(\\d)", ps.Error.Error())
+}
+
 func TestNamedRegex(t *testing.T) {
 	t.Run("Error message shows name, not underlying regex", func(t *testing.T) {
 		_, p2 := runParser("fox", NamedRegex("fowl", `hens|roosters`))
@@ -272,6 +446,12 @@ func TestParseString(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, "offset 0: expected hello", err.Error())
 	})
+
+	t.Run("only makes the state and result without options", func(t *testing.T) {
+		p := Any("hello", "help")
+		require.Equal(t, 2.0, testing.AllocsPerRun(100, func() { _, _, _ = Run(p, "hello") }))
+		require.Equal(t, 2.0, testing.AllocsPerRun(100, func() { _, _, _ = Run(p, "world") }))
+	})
 }
 
 func TestAutoWS(t *testing.T) {
@@ -317,3 +497,71 @@ func runParser(input string, parser Parser) (Result, *State) {
 	parser(ps, &result)
 	return result, ps
 }
+
+func TestAllowTrailingInput(t *testing.T) {
+	_, _, err := Run("hello", "hello world")
+	require.EqualError(t, err, "left unparsed: world")
+
+	_, parsed, err := Run("hello", "hello world", AllowTrailingInput())
+	require.NoError(t, err)
+	require.Equal(t, "hello", parsed)
+
+	_, _, err = Run("goodbye", "hello world", AllowTrailingInput())
+	require.EqualError(t, err, "offset 0: expected goodbye")
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	var value Parser
+	value = Any(Seq("[", Maybe(&value), "]"), "x")
+
+	_, _, err := Run(&value, "[[[x]]]", WithMaxDepth(4))
+	require.NoError(t, err)
+
+	_, _, err = Run(&value, "[[[[x]]]]", WithMaxDepth(4))
+	require.EqualError(t, err, "offset 4: expected input nested at most 4 deep")
+
+	_, _, err = Run(Many(&value), "[x] [[[[x]]]]", WithMaxDepth(4))
+	require.EqualError(t, err, "offset 8: expected input nested at most 4 deep")
+
+	_, _, err = Run(&value, strings.Repeat("[", 1000)+strings.Repeat("]", 1000))
+	require.NoError(t, err)
+}
+
+func TestRunPrefix(t *testing.T) {
+	number := NumberLit()
+
+	result, rest, err := RunPrefix(number, "12 + 3")
+	require.NoError(t, err)
+	require.Equal(t, int64(12), result)
+	require.Equal(t, "+ 3", rest)
+
+	result, rest, err = RunPrefix(number, "12 ")
+	require.NoError(t, err)
+	require.Equal(t, int64(12), result)
+	require.Equal(t, "", rest)
+
+	_, rest, err = RunPrefix(number, "+ 3")
+	require.EqualError(t, err, "offset 0: expected number")
+	require.Equal(t, "+ 3", rest)
+}
+
+func TestMustRun(t *testing.T) {
+	require.Equal(t, int64(42), MustRun(NumberLit(), "42"))
+	defer func() {
+		err, _ := recover().(error)
+		require.EqualError(t, err, `goparsify: parsing "x": offset 0: expected number`)
+	}()
+	MustRun(NumberLit(), "x")
+}
+
+func TestRunAs(t *testing.T) {
+	n, err := RunAs[int64](NumberLit(), "42")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), n)
+
+	_, err = RunAs[string](NumberLit(), "42")
+	require.EqualError(t, err, "result is a int64, not a string")
+
+	_, err = RunAs[int64](NumberLit(), "4 2")
+	require.EqualError(t, err, "left unparsed: 2")
+}
@@ -0,0 +1,58 @@
+package parsifytest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ijt/goparsify"
+)
+
+// FuzzParser runs p on the inputs generated by go test -fuzz, starting from seeds, and checks
+// that it doesn't panic and that what it gives back passes AssertInvariants. Without -fuzz only
+// the seeds and the inputs saved in testdata/fuzz are run, so it works as a regression test too.
+//
+//	func FuzzValue(f *testing.F) {
+//		parsifytest.FuzzParser(f, value, `[1, "a"]`, `{"b": null}`)
+//	}
+func FuzzParser(f *testing.F, p goparsify.Parserish, seeds ...string) {
+	f.Helper()
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	parser := goparsify.Parsify(p)
+	f.Fuzz(func(t *testing.T, input string) {
+		tree, err := goparsify.RunTree(parser, input)
+		AssertInvariants(t, input, tree, err)
+	})
+}
+
+// AssertInvariants checks what RunTree gave back for input against what holds for any parser:
+// errors are at an offset within the input, spans are within the input and every token is part
+// of it. FuzzParser uses it, and fuzz tests that need options for RunTree can use it directly.
+//
+// Parsers that make up tokens of their own with Map, or decode escapes like StringLit does, don't
+// meet the last rule and should be fuzzed with checks of their own.
+func AssertInvariants(t testing.TB, input string, tree goparsify.Result, err error) bool {
+	t.Helper()
+	var perr *goparsify.Error
+	if errors.As(err, &perr) && (perr.Pos() < 0 || perr.Pos() > len(input)) {
+		t.Errorf("parsing %q: error at offset %d, outside the input of length %d", input, perr.Pos(), len(input))
+		return false
+	}
+
+	ok := true
+	goparsify.Walk(&tree, func(n *goparsify.Result, depth int) bool {
+		switch {
+		case n.Start < 0 || n.Start > n.End || n.End > len(input):
+			t.Errorf("parsing %q: span %d..%d of %q isn't within the input of length %d", input, n.Start, n.End, n.Token, len(input))
+		case !strings.Contains(input, n.Token):
+			t.Errorf("parsing %q: token %q isn't in the input", input, n.Token)
+		default:
+			return true
+		}
+		ok = false
+		return false
+	})
+	return ok
+}
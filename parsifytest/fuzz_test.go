@@ -0,0 +1,37 @@
+package parsifytest
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzList(f *testing.F) {
+	var value Parser
+	list := Seq("[", Cut(), Many(&value, ","), "]")
+	value = Any(Chars("0-9"), Fuzzy("null", 1), list)
+
+	FuzzParser(f, value, "1", "[1, [2, nul], []]", "[1,", "[[[")
+}
+
+func TestAssertInvariants(t *testing.T) {
+	tree, err := RunTree(Seq("a", "b"), "a b")
+	require.NoError(t, err)
+	require.True(t, AssertInvariants(t, "a b", tree, err))
+
+	_, err = RunTree(Seq("a", "b"), "a c")
+	require.True(t, AssertInvariants(t, "a c", Result{}, err))
+	require.True(t, AssertInvariants(t, "a c", Result{}, errors.New("not a parse error")))
+
+	r := &recorder{TB: t}
+	tree.Child[1].Token = "c"
+	require.False(t, AssertInvariants(r, "a b", tree, nil))
+	require.Equal(t, []string{`parsing "a b": token "c" isn't in the input`}, r.failures)
+
+	r = &recorder{TB: t}
+	tree.Child[1].End = 4
+	require.False(t, AssertInvariants(r, "a b", tree, nil))
+	require.Equal(t, []string{`parsing "a b": span 2..4 of "c" isn't within the input of length 3`}, r.failures)
+}
@@ -0,0 +1,114 @@
+package parsifytest
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ijt/goparsify"
+)
+
+// Update is set by the -update flag of test binaries using this package, to rewrite golden files
+// instead of comparing against them. Tests with golden files of their own can use it rather than
+// defining the flag again.
+var Update = flag.Bool("update", false, "rewrite golden files instead of comparing against them")
+
+// AssertGolden parses input with p and checks that the tree it builds, written with Result.Dump,
+// matches the golden file, so a grammar can be refactored and still be known to build the same
+// trees. A parse that fails is written as its error. Run the tests with -update to write the
+// golden file instead, when it's new or the trees are meant to change, and review the change in
+// the diff.
+func AssertGolden(t testing.TB, p goparsify.Parserish, input string, golden string, opts ...goparsify.Option) bool {
+	t.Helper()
+	got := goldenTree(p, input, opts)
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Errorf("writing golden file: %v", err)
+			return false
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Errorf("writing golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("%s doesn't exist, run the test with -update to write it", golden)
+		return false
+	}
+	if err != nil {
+		t.Errorf("reading golden file: %v", err)
+		return false
+	}
+	if got != string(want) {
+		line, gotLine, wantLine := firstDifference(got, string(want))
+		t.Errorf("tree differs from %s at line %d, run the test with -update if this is meant to change:\n\tgot  %s\n\twant %s", golden, line, gotLine, wantLine)
+		return false
+	}
+	return true
+}
+
+// AssertGoldenCorpus runs AssertGolden in a subtest for each file matching pattern, eg
+// testdata/*.json, with its golden file next to it named with .golden added on.
+func AssertGoldenCorpus(t *testing.T, p goparsify.Parserish, pattern string, opts ...goparsify.Option) bool {
+	t.Helper()
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Errorf("finding the corpus: %v", err)
+		return false
+	}
+	if len(paths) == 0 {
+		t.Errorf("no files match %s", pattern)
+		return false
+	}
+
+	ok := true
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".golden") {
+			continue
+		}
+		path := path
+		ok = t.Run(filepath.Base(path), func(t *testing.T) {
+			input, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			AssertGolden(t, p, string(input), path+".golden", opts...)
+		}) && ok
+	}
+	return ok
+}
+
+// goldenTree is what AssertGolden writes for the parse of input.
+func goldenTree(p goparsify.Parserish, input string, opts []goparsify.Option) string {
+	tree, err := goparsify.RunTree(p, input, opts...)
+	if err != nil {
+		return "error: " + err.Error() + "\n"
+	}
+	var b strings.Builder
+	tree.Dump(&b)
+	return b.String()
+}
+
+// firstDifference finds the first line that differs between a and b, counting from 1.
+func firstDifference(a, b string) (line int, aLine, bLine string) {
+	as, bs := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := 0; ; i++ {
+		if i >= len(as) || i >= len(bs) || as[i] != bs[i] {
+			return i + 1, lineAt(as, i), lineAt(bs, i)
+		}
+	}
+}
+
+func lineAt(lines []string, i int) string {
+	if i >= len(lines) {
+		return "(end of file)"
+	}
+	return lines[i]
+}
@@ -0,0 +1,66 @@
+package parsifytest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertGolden(t *testing.T) {
+	list := Named("list", Seq("[", Many(Named("item", Chars("a-z")), ","), "]"))
+	golden := filepath.Join(t.TempDir(), "testdata", "list.golden")
+	defer resetUpdate(*Update)
+	*Update = false
+
+	r := &recorder{TB: t}
+	require.False(t, AssertGolden(r, list, "[a, b]", golden))
+	require.Equal(t, []string{golden + " doesn't exist, run the test with -update to write it"}, r.failures)
+
+	*Update = true
+	require.True(t, AssertGolden(t, list, "[a, b]", golden))
+	*Update = false
+
+	tree, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, `list 0..6 "[a, b]"
+  0..1 "["
+  1..5 ""
+    item 1..2 "a"
+    item 4..5 "b"
+  5..6 "]"
+`, string(tree))
+
+	require.True(t, AssertGolden(t, list, "[a, b]", golden))
+
+	require.NoError(t, os.WriteFile(golden, []byte(strings.Replace(string(tree), `"b"`, `"c"`, 1)), 0o644))
+	r = &recorder{TB: t}
+	require.False(t, AssertGolden(r, list, "[a, b]", golden))
+	require.Equal(t, []string{"tree differs from " + golden + ` at line 5, run the test with -update if this is meant to change:
+	got      item 4..5 "b"
+	want     item 4..5 "c"`}, r.failures)
+}
+
+func TestAssertGoldenCorpus(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ok.txt"), []byte("a b"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.txt"), []byte("a c"), 0o644))
+	parser := Seq("a", "b")
+	defer resetUpdate(*Update)
+
+	*Update = true
+	require.True(t, AssertGoldenCorpus(t, parser, filepath.Join(dir, "*.txt")))
+	*Update = false
+
+	bad, err := os.ReadFile(filepath.Join(dir, "bad.txt.golden"))
+	require.NoError(t, err)
+	require.Equal(t, "error: offset 2: expected b\n", string(bad))
+
+	// The golden files written alongside the corpus aren't taken as inputs.
+	require.True(t, AssertGoldenCorpus(t, parser, filepath.Join(dir, "*")))
+}
+
+func resetUpdate(update bool) { *Update = update }
@@ -0,0 +1,86 @@
+// Package parsifytest has helpers for testing parsers built with goparsify, so tests of a grammar
+// can be written as tables of inputs and what they should parse to.
+//
+// The helpers report failures with t.Errorf and return whether they passed, so a table can be
+// checked to the end and still show every case that went wrong.
+package parsifytest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ijt/goparsify"
+)
+
+// AssertParses runs p on input and checks that it parses all of it to want, compared with the
+// result using reflect.DeepEqual, so want must be the same type the parser gives, eg 2.0 and
+// not 2 for a parser giving float64s.
+func AssertParses(t testing.TB, p goparsify.Parserish, input string, want interface{}, opts ...goparsify.Option) bool {
+	t.Helper()
+	got, _, err := goparsify.Run(p, input, opts...)
+	if err != nil {
+		t.Errorf("parsing %q: %v", input, err)
+		return false
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsing %q: got %#v, want %#v", input, got, want)
+		return false
+	}
+	return true
+}
+
+// AssertFailsAt runs p on input and checks that it fails with a *goparsify.Error at offset,
+// expecting the text given by expected.
+func AssertFailsAt(t testing.TB, p goparsify.Parserish, input string, offset int, expected string, opts ...goparsify.Option) bool {
+	t.Helper()
+	_, _, err := goparsify.Run(p, input, opts...)
+	if err == nil {
+		t.Errorf("parsing %q: got no error, want one at offset %d expecting %s", input, offset, expected)
+		return false
+	}
+	var perr *goparsify.Error
+	if !errors.As(err, &perr) {
+		t.Errorf("parsing %q: got %v, want an error at offset %d expecting %s", input, err, offset, expected)
+		return false
+	}
+	if perr.Pos() != offset || perr.Expected() != expected {
+		t.Errorf("parsing %q: got error at offset %d expecting %s, want offset %d expecting %s", input, perr.Pos(), perr.Expected(), offset, expected)
+		return false
+	}
+	return true
+}
+
+// AssertTree runs p on input and checks that the tree it builds, written with Result.SExpr, is
+// want, eg (pair "a b" (_ "a") (b "b")) for Named("pair", Seq("a", Named("b", "b"))) on
+// "a b".
+func AssertTree(t testing.TB, p goparsify.Parserish, input string, want string, opts ...goparsify.Option) bool {
+	t.Helper()
+	tree, err := goparsify.RunTree(p, input, opts...)
+	if err != nil {
+		t.Errorf("parsing %q: %v", input, err)
+		return false
+	}
+	var b strings.Builder
+	tree.SExpr(&b)
+	if got := b.String(); got != want {
+		t.Errorf("parsing %q: got tree\n\t%s\nwant\n\t%s", input, got, want)
+		return false
+	}
+	return true
+}
+
+// AssertTokens checks that the children of node have the tokens given, in order.
+func AssertTokens(t testing.TB, node goparsify.Result, tokens ...string) bool {
+	t.Helper()
+	got := []string{}
+	for _, child := range node.Child {
+		got = append(got, child.Token)
+	}
+	if want := append([]string{}, tokens...); !reflect.DeepEqual(got, want) {
+		t.Errorf("got child tokens %q, want %q", got, want)
+		return false
+	}
+	return true
+}
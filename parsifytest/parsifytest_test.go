@@ -0,0 +1,72 @@
+package parsifytest
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a testing.TB that keeps the failures reported to it.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+var sum = Seq(NumberLit(), "+", NumberLit()).Map(func(n *Result) {
+	n.Result = n.Child[0].Result.(int64) + n.Child[2].Result.(int64)
+})
+
+func TestAssertParses(t *testing.T) {
+	require.True(t, AssertParses(t, sum, "1 + 2", int64(3)))
+
+	r := &recorder{TB: t}
+	require.False(t, AssertParses(r, sum, "1 + 2", 3))
+	require.Equal(t, []string{`parsing "1 + 2": got 3, want 3`}, r.failures)
+
+	r = &recorder{TB: t}
+	require.False(t, AssertParses(r, sum, "1 +", int64(1)))
+	require.Equal(t, []string{`parsing "1 +": offset 3: unexpected end of input, expected number`}, r.failures)
+}
+
+func TestAssertFailsAt(t *testing.T) {
+	require.True(t, AssertFailsAt(t, sum, "1 + x", 4, "number"))
+
+	r := &recorder{TB: t}
+	require.False(t, AssertFailsAt(r, sum, "1 + x", 2, "+"))
+	require.Equal(t, []string{`parsing "1 + x": got error at offset 4 expecting number, want offset 2 expecting +`}, r.failures)
+
+	r = &recorder{TB: t}
+	require.False(t, AssertFailsAt(r, sum, "1 + 2", 4, "number"))
+	require.Equal(t, []string{`parsing "1 + 2": got no error, want one at offset 4 expecting number`}, r.failures)
+
+	r = &recorder{TB: t}
+	require.False(t, AssertFailsAt(r, "a", "a b", 2, "a"))
+	require.Equal(t, []string{`parsing "a b": got left unparsed: b, want an error at offset 2 expecting a`}, r.failures)
+}
+
+func TestAssertTree(t *testing.T) {
+	pair := Named("pair", Seq("a", Named("b", "b")))
+	require.True(t, AssertTree(t, pair, "a b", `(pair "a b" (_ "a") (b "b"))`))
+
+	r := &recorder{TB: t}
+	require.False(t, AssertTree(r, pair, "a b", `(pair "")`))
+	require.Equal(t, []string{"parsing \"a b\": got tree\n\t(pair \"a b\" (_ \"a\") (b \"b\"))\nwant\n\t(pair \"\")"}, r.failures)
+}
+
+func TestAssertTokens(t *testing.T) {
+	tree, err := RunTree(Seq("a", "b"), "a b")
+	require.NoError(t, err)
+	require.True(t, AssertTokens(t, tree, "a", "b"))
+
+	r := &recorder{TB: t}
+	require.False(t, AssertTokens(r, tree, "a"))
+	require.Equal(t, []string{`got child tokens ["a" "b"], want ["a"]`}, r.failures)
+}
@@ -0,0 +1,54 @@
+// Command peggen turns a PEG grammar file into Go code defining goparsify parsers for it,
+// see peg.Generate. It is meant to be run from a go:generate comment:
+//
+//	//go:generate go run github.com/ijt/goparsify/peg/cmd/peggen list.peg
+//
+// which writes list_peg.go in the package containing the comment.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ijt/goparsify/peg"
+)
+
+func main() {
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package name of the generated file, defaults to the package running go generate")
+	prefix := flag.String("prefix", "", "prefix for the generated type and function names")
+	output := flag.String("o", "", "file to write, defaults to the grammar file name with _peg.go in place of its extension")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: peggen [flags] grammar.peg\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *output, peg.GenerateOptions{Package: *pkg, Prefix: *prefix}); err != nil {
+		fmt.Fprintln(os.Stderr, "peggen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output string, opts peg.GenerateOptions) error {
+	grammar, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = strings.TrimSuffix(input, filepath.Ext(input)) + "_peg.go"
+	}
+	opts.Source = filepath.Base(input)
+
+	src, err := peg.Generate(string(grammar), opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+	return os.WriteFile(output, src, 0o644)
+}
@@ -0,0 +1,177 @@
+package peg
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateOptions configures the Go code written by Generate.
+type GenerateOptions struct {
+	// Package is the name of the package the generated file belongs to.
+	Package string
+	// Prefix is put in front of the names of the generated Actions, BaseActions and NewParser,
+	// so several grammars can share a package.
+	Prefix string
+	// Source is the file the grammar was read from, mentioned in the generated header.
+	Source string
+}
+
+// Generate writes Go source defining the same parser Compile would build from grammar, so
+// grammars can be reviewed as text but compiled with the rest of the program. It is meant to
+// be run by peggen from a go:generate comment.
+//
+// The generated code declares an Actions interface with a method for each rule, named after
+// the rule in CamelCase, which is called with the Result of every match of that rule. The
+// BaseActions type implements all of them without doing anything, so handwritten actions
+// embed it and override the rules they care about. NewParser returns a parser for the first
+// rule calling the given actions.
+func Generate(grammar string, opts GenerateOptions) ([]byte, error) {
+	defs, err := parseGrammar(grammar)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Package == "" {
+		return nil, fmt.Errorf("peg: no package name given")
+	}
+
+	methods := map[string]string{}
+	byMethod := map[string]string{}
+	for _, def := range defs {
+		method := camelCase(def.name)
+		if method == "" {
+			return nil, fmt.Errorf("peg: offset %d: rule %s has no letters to name its action after", def.pos, def.name)
+		}
+		if other, ok := byMethod[method]; ok {
+			return nil, fmt.Errorf("peg: offset %d: rules %s and %s would both have an action named %s", def.pos, other, def.name, method)
+		}
+		methods[def.name] = method
+		byMethod[method] = def.name
+	}
+
+	g := &generator{methods: methods}
+	var body bytes.Buffer
+	for _, def := range defs {
+		fmt.Fprintf(&body, "\t%s = goparsify.Rule(%q, %s).Map(actions.%s)\n", g.varName(def.name), def.name, g.expr(def.expr), methods[def.name])
+	}
+
+	actionsType := opts.Prefix + "Actions"
+	baseType := "Base" + opts.Prefix + "Actions"
+
+	var out bytes.Buffer
+	if opts.Source != "" {
+		fmt.Fprintf(&out, "// Code generated by peggen from %s. DO NOT EDIT.\n\n", opts.Source)
+	} else {
+		fmt.Fprintf(&out, "// Code generated by peggen. DO NOT EDIT.\n\n")
+	}
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+	fmt.Fprintf(&out, "import (\n\t\"github.com/ijt/goparsify\"\n")
+	if g.usesPeg {
+		fmt.Fprintf(&out, "\t\"github.com/ijt/goparsify/peg\"\n")
+	}
+	fmt.Fprintf(&out, ")\n\n")
+
+	fmt.Fprintf(&out, "// %s is called with the result of each rule of the grammar as it matches.\n", actionsType)
+	fmt.Fprintf(&out, "// Embed %s to only implement some of them.\n", baseType)
+	fmt.Fprintf(&out, "type %s interface {\n", actionsType)
+	for _, def := range defs {
+		fmt.Fprintf(&out, "\t%s(n *goparsify.Result)\n", methods[def.name])
+	}
+	fmt.Fprintf(&out, "}\n\n")
+
+	fmt.Fprintf(&out, "// %s implements %s without doing anything.\n", baseType, actionsType)
+	fmt.Fprintf(&out, "type %s struct{}\n\n", baseType)
+	for _, def := range defs {
+		fmt.Fprintf(&out, "// %s is called with each match of %s.\n", methods[def.name], def.name)
+		fmt.Fprintf(&out, "func (%s) %s(n *goparsify.Result) {}\n\n", baseType, methods[def.name])
+	}
+
+	fmt.Fprintf(&out, "// New%sParser returns a parser for %s, calling actions as rules match.\n", opts.Prefix, defs[0].name)
+	fmt.Fprintf(&out, "func New%sParser(actions %s) goparsify.Parser {\n", opts.Prefix, actionsType)
+	var vars []string
+	for _, def := range defs {
+		vars = append(vars, g.varName(def.name))
+	}
+	fmt.Fprintf(&out, "\tvar %s goparsify.Parser\n", strings.Join(vars, ", "))
+	out.Write(body.Bytes())
+	fmt.Fprintf(&out, "\treturn %s\n}\n", g.varName(defs[0].name))
+
+	return format.Source(out.Bytes())
+}
+
+type generator struct {
+	methods map[string]string
+	usesPeg bool
+}
+
+// varName is the local variable holding a rule in NewParser. The prefix keeps rule names that
+// are Go keywords or shadow other names from breaking the build.
+func (g *generator) varName(rule string) string {
+	return "rule" + g.methods[rule]
+}
+
+func (g *generator) expr(e expr) string {
+	var children []string
+	for _, child := range e.children {
+		children = append(children, g.expr(child))
+	}
+
+	switch e.kind {
+	case choiceExpr:
+		g.usesPeg = true
+		return "peg.Choice(" + strings.Join(children, ", ") + ")"
+	case sequenceExpr:
+		return "goparsify.Seq(" + strings.Join(children, ", ") + ")"
+	case optionalExpr:
+		return "goparsify.Maybe(" + children[0] + ")"
+	case manyExpr:
+		return "goparsify.Many(" + children[0] + ")"
+	case someExpr:
+		return "goparsify.Some(" + children[0] + ")"
+	case andExpr:
+		g.usesPeg = true
+		return "peg.And(" + children[0] + ")"
+	case notExpr:
+		g.usesPeg = true
+		return "peg.Not(" + children[0] + ")"
+	case literalExpr:
+		return "goparsify.Exact(" + strconv.Quote(e.text) + ")"
+	case classExpr:
+		return "goparsify.Chars(" + strconv.Quote(e.text) + ", 1, 1)"
+	case notClassExpr:
+		return "goparsify.NotChars(" + strconv.Quote(e.text) + ", 1, 1)"
+	case anyCharExpr:
+		return `goparsify.NotChars("", 1, 1)`
+	case cutExpr:
+		return "goparsify.Cut()"
+	case refExpr:
+		return "&" + g.varName(e.text)
+	}
+	panic(fmt.Errorf("unknown expression kind %d", e.kind))
+}
+
+// camelCase turns a rule name like key_value into KeyValue.
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if s != "" && !unicode.IsLetter([]rune(s)[0]) {
+		// Rule names can't start with a digit but can once underscores are dropped, eg _1.
+		s = "R" + s
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package peg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	grammar, err := os.ReadFile("internal/listexample/list.peg")
+	require.NoError(t, err)
+	want, err := os.ReadFile("internal/listexample/list_peg.go")
+	require.NoError(t, err)
+
+	got, err := Generate(string(grammar), GenerateOptions{Package: "listexample", Source: "list.peg"})
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate ./... to update list_peg.go")
+}
+
+func TestGeneratePrefix(t *testing.T) {
+	got, err := Generate(`key_value <- "a"`, GenerateOptions{Package: "p", Prefix: "KV"})
+	require.NoError(t, err)
+	require.Contains(t, string(got), "// Code generated by peggen. DO NOT EDIT.")
+	require.Contains(t, string(got), "type KVActions interface {\n\tKeyValue(n *goparsify.Result)\n}")
+	require.Contains(t, string(got), "func (BaseKVActions) KeyValue(n *goparsify.Result) {}")
+	require.Contains(t, string(got), "func NewKVParser(actions KVActions) goparsify.Parser {")
+	require.NotContains(t, string(got), "goparsify/peg")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := Generate(`a <- b`, GenerateOptions{Package: "p"})
+	require.EqualError(t, err, "peg: offset 5: undefined rule b")
+
+	_, err = Generate(`a <- "x"`, GenerateOptions{})
+	require.EqualError(t, err, "peg: no package name given")
+
+	_, err = Generate("a_b <- \"x\"\naB <- \"y\"", GenerateOptions{Package: "p"})
+	require.EqualError(t, err, "peg: offset 11: rules a_b and aB would both have an action named AB")
+
+	_, err = Generate(`_ <- "x"`, GenerateOptions{Package: "p"})
+	require.EqualError(t, err, "peg: offset 0: rule _ has no letters to name its action after")
+}
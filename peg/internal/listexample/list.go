@@ -0,0 +1,29 @@
+// Package listexample shows how to use code generated by peggen. TestGenerate checks that
+// list_peg.go is up to date.
+package listexample
+
+//go:generate go run github.com/ijt/goparsify/peg/cmd/peggen list.peg
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ijt/goparsify"
+)
+
+// sum adds up every number in the lists it is given.
+type sum struct {
+	BaseActions
+	total int
+}
+
+func (s *sum) Number(n *goparsify.Result) {
+	i, _ := strconv.Atoi(strings.TrimSpace(n.Token))
+	s.total += i
+}
+
+func total(input string) (int, error) {
+	s := &sum{}
+	_, _, err := goparsify.Run(NewParser(s), input)
+	return s.total, err
+}
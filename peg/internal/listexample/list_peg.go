@@ -0,0 +1,42 @@
+// Code generated by peggen from list.peg. DO NOT EDIT.
+
+package listexample
+
+import (
+	"github.com/ijt/goparsify"
+	"github.com/ijt/goparsify/peg"
+)
+
+// Actions is called with the result of each rule of the grammar as it matches.
+// Embed BaseActions to only implement some of them.
+type Actions interface {
+	List(n *goparsify.Result)
+	Values(n *goparsify.Result)
+	Value(n *goparsify.Result)
+	Number(n *goparsify.Result)
+}
+
+// BaseActions implements Actions without doing anything.
+type BaseActions struct{}
+
+// List is called with each match of list.
+func (BaseActions) List(n *goparsify.Result) {}
+
+// Values is called with each match of values.
+func (BaseActions) Values(n *goparsify.Result) {}
+
+// Value is called with each match of value.
+func (BaseActions) Value(n *goparsify.Result) {}
+
+// Number is called with each match of number.
+func (BaseActions) Number(n *goparsify.Result) {}
+
+// NewParser returns a parser for list, calling actions as rules match.
+func NewParser(actions Actions) goparsify.Parser {
+	var ruleList, ruleValues, ruleValue, ruleNumber goparsify.Parser
+	ruleList = goparsify.Rule("list", goparsify.Seq(goparsify.Exact("["), goparsify.Cut(), goparsify.Maybe(&ruleValues), goparsify.Exact("]"))).Map(actions.List)
+	ruleValues = goparsify.Rule("values", goparsify.Seq(&ruleValue, goparsify.Many(goparsify.Seq(goparsify.Exact(","), &ruleValue)))).Map(actions.Values)
+	ruleValue = goparsify.Rule("value", peg.Choice(&ruleNumber, &ruleList)).Map(actions.Value)
+	ruleNumber = goparsify.Rule("number", peg.Choice(goparsify.Seq(peg.Not(goparsify.Exact("0")), goparsify.Some(goparsify.Chars("0-9", 1, 1))), goparsify.Exact("0"))).Map(actions.Number)
+	return ruleList
+}
@@ -0,0 +1,16 @@
+package listexample
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotal(t *testing.T) {
+	n, err := total("[1, [23, 4], [], 0]")
+	require.NoError(t, err)
+	require.Equal(t, 28, n)
+
+	_, err = total("[01]")
+	require.Error(t, err)
+}
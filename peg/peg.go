@@ -0,0 +1,369 @@
+// Package peg compiles PEG grammars written as text into goparsify parsers at runtime, so
+// grammars can live in config files or be supplied by plugins.
+//
+// A grammar is a list of rules, each a name followed by <- (or =) and an expression:
+//
+//	# comments run to the end of the line
+//	list   <- "[" values? "]"
+//	values <- value ("," value)*
+//	value  <- number / list
+//	number <- [0-9]+
+//
+// Expressions are made of
+//   - "literal" or 'literal', matched exactly, with the escapes StringLit understands
+//   - [a-z_] and [^"], matching a single character in or not in a class, see goparsify.Chars
+//   - . matching any single character
+//   - rule names, and ( ) for grouping
+//   - e? e* and e+ for optional, zero or more and one or more
+//   - &e and !e to look ahead without consuming input
+//   - ~ which is a goparsify.Cut
+//   - e1 e2 for a sequence and e1 / e2 for an ordered choice
+//
+// The first rule is where parsing starts. Like every goparsify parser the compiled rules skip
+// whitespace before each terminal, pass goparsify.WithWhitespace(goparsify.NoWhitespace) to Run
+// to match whitespace explicitly instead.
+//
+// Grammars can also be turned into Go code ahead of time with Generate, or the peggen command
+// in cmd/peggen which wraps it for go:generate.
+package peg
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/ijt/goparsify"
+)
+
+// Actions maps rule names to a function that is called with the Result of each match of the
+// rule, typically to set .Result like goparsify.Map.
+type Actions map[string]func(*Result)
+
+// Compile parses a grammar and returns a parser for its first rule. Every rule is wrapped with
+// goparsify.Rule so results, logs and profiles carry the rule names.
+func Compile(grammar string, actions Actions) (Parser, error) {
+	defs, err := parseGrammar(grammar)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{rules: map[string]*Parser{}}
+	for _, def := range defs {
+		c.rules[def.name] = new(Parser)
+	}
+	for name := range actions {
+		if _, ok := c.rules[name]; !ok {
+			return nil, fmt.Errorf("peg: action for undefined rule %s", name)
+		}
+	}
+
+	for _, def := range defs {
+		p := Rule(def.name, c.compile(def.expr))
+		if action := actions[def.name]; action != nil {
+			p = p.Map(action)
+		}
+		*c.rules[def.name] = p
+	}
+	return *c.rules[defs[0].name], nil
+}
+
+// MustCompile is like Compile but panics if the grammar is invalid. It simplifies
+// initializing package level parsers.
+func MustCompile(grammar string, actions Actions) Parser {
+	p, err := Compile(grammar, actions)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// parseGrammar parses a grammar and checks that every rule is defined exactly once.
+func parseGrammar(grammar string) ([]definition, error) {
+	result, _, err := Run(_grammar, grammar, WithWhitespace(grammarWhitespace))
+	if err != nil {
+		return nil, fmt.Errorf("peg: %w", err)
+	}
+	defs := result.([]definition)
+
+	defined := map[string]bool{}
+	for _, def := range defs {
+		if defined[def.name] {
+			return nil, fmt.Errorf("peg: offset %d: rule %s is defined more than once", def.pos, def.name)
+		}
+		defined[def.name] = true
+	}
+	for _, def := range defs {
+		if err := checkReferences(def.expr, defined); err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+func checkReferences(e expr, defined map[string]bool) error {
+	if e.kind == refExpr && !defined[e.text] {
+		return fmt.Errorf("peg: offset %d: undefined rule %s", e.pos, e.text)
+	}
+	for _, child := range e.children {
+		if err := checkReferences(child, defined); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type compiler struct {
+	rules map[string]*Parser
+}
+
+func (c *compiler) compile(e expr) Parser {
+	var children []Parserish
+	for _, child := range e.children {
+		children = append(children, c.compile(child))
+	}
+
+	switch e.kind {
+	case choiceExpr:
+		return Choice(children...)
+	case sequenceExpr:
+		return Seq(children...)
+	case optionalExpr:
+		return Maybe(children[0])
+	case manyExpr:
+		return Many(children[0])
+	case someExpr:
+		return Some(children[0])
+	case andExpr:
+		return And(children[0])
+	case notExpr:
+		return Not(children[0])
+	case literalExpr:
+		return Exact(e.text)
+	case classExpr:
+		return Chars(e.text, 1, 1)
+	case notClassExpr:
+		return NotChars(e.text, 1, 1)
+	case anyCharExpr:
+		return NotChars("", 1, 1)
+	case cutExpr:
+		return Cut()
+	case refExpr:
+		return Parsify(c.rules[e.text])
+	}
+	panic(fmt.Errorf("unknown expression kind %d", e.kind))
+}
+
+// Choice matches the first of the parsers that matches, like Any, but still tries them at the
+// end of the input, where Any fails straight away, so alternatives that match nothing, like !.,
+// can match there. It implements e1 / e2.
+func Choice(parsers ...Parserish) Parser {
+	alternatives := ParsifyAll(parsers...)
+	any := Any(parsers...)
+	return func(ps *State, node *Result) {
+		m := ps.Mark()
+		any(ps, node)
+		if !ps.Errored() || ps.Committed(m) {
+			return
+		}
+		err := ps.Error
+		ps.Restore(m)
+		ps.WS(ps)
+		atEnd := ps.Pos == len(ps.Input)
+		ps.Restore(m)
+		if atEnd {
+			for _, p := range alternatives {
+				ps.Recover()
+				p(ps, node)
+				if !ps.Errored() || ps.Committed(m) {
+					return
+				}
+				ps.Restore(m)
+			}
+		}
+		ps.Error = err
+	}
+}
+
+// And matches without consuming any input if the given parser matches. It implements &e.
+func And(parser Parserish) Parser {
+	return lookahead(Parsify(parser), true)
+}
+
+// Not matches without consuming any input if the given parser doesn't match. It implements !e.
+func Not(parser Parserish) Parser {
+	return lookahead(Parsify(parser), false)
+}
+
+// lookahead matches without consuming input if p matches, or if it doesn't when want is false.
+func lookahead(p Parser, want bool) Parser {
+	return NewParser("lookahead", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		p(ps, &Result{})
+		matched := !ps.Errored()
+		ps.Recover()
+		ps.Pos = startpos
+		if matched != want {
+			ps.ErrorHere("lookahead")
+		}
+	})
+}
+
+type exprKind int
+
+const (
+	choiceExpr exprKind = iota
+	sequenceExpr
+	optionalExpr
+	manyExpr
+	someExpr
+	andExpr
+	notExpr
+	literalExpr
+	classExpr
+	notClassExpr
+	anyCharExpr
+	cutExpr
+	refExpr
+)
+
+// expr is a node of a parsed grammar.
+type expr struct {
+	kind     exprKind
+	text     string
+	pos      int
+	children []expr
+}
+
+type definition struct {
+	name string
+	pos  int
+	expr expr
+}
+
+// grammarWhitespace skips whitespace and # comments in grammars.
+func grammarWhitespace(ps *State) {
+	for {
+		ASCIIWhitespace(ps)
+		if !strings.HasPrefix(ps.Get(), "#") {
+			return
+		}
+		end := strings.IndexByte(ps.Get(), '\n')
+		if end < 0 {
+			ps.Pos = len(ps.Input)
+			return
+		}
+		ps.Advance(end)
+	}
+}
+
+var (
+	_expression Parser
+
+	_identifier = NamedRegex("rule name", `[A-Za-z_][A-Za-z0-9_]*`)
+	_arrow      = Any("<-", "=")
+
+	// _reference is an identifier that isn't the start of the next definition.
+	_reference = NewParser("rule name", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		_identifier(ps, node)
+		if ps.Errored() {
+			return
+		}
+		endpos := ps.Pos
+		var arrow Result
+		_arrow(ps, &arrow)
+		if !ps.Errored() {
+			ps.Pos = startpos
+			ps.ErrorHere("rule name")
+			return
+		}
+		ps.Recover()
+		ps.Pos = endpos
+		node.Result = expr{kind: refExpr, text: node.Token, pos: node.Start}
+	})
+
+	_literal = Map(StringLit(`"'`), func(n *Result) {
+		n.Result = expr{kind: literalExpr, text: n.Token, pos: n.Start}
+	})
+
+	_class = Map(Regex(`\[(\\.|[^\]\\])*\]`), func(n *Result) {
+		class := n.Token[1 : len(n.Token)-1]
+		if strings.HasPrefix(class, "^") {
+			n.Result = expr{kind: notClassExpr, text: unescapeClass(class[1:]), pos: n.Start}
+			return
+		}
+		n.Result = expr{kind: classExpr, text: unescapeClass(class), pos: n.Start}
+	})
+
+	_anyChar = Bind(".", expr{kind: anyCharExpr})
+	_cut     = Bind("~", expr{kind: cutExpr})
+
+	_group = Seq("(", Cut(), &_expression, ")").Map(func(n *Result) {
+		n.Result = n.Child[2].Result
+	})
+
+	_primary = Any(_literal, _class, _anyChar, _cut, _group, _reference)
+
+	_suffixed = Seq(Maybe(Chars("&!", 1, 1)), _primary, Maybe(Chars("?*+", 1, 1))).Map(func(n *Result) {
+		e := n.Child[1].Result.(expr)
+		switch n.Child[2].Token {
+		case "?":
+			e = expr{kind: optionalExpr, children: []expr{e}}
+		case "*":
+			e = expr{kind: manyExpr, children: []expr{e}}
+		case "+":
+			e = expr{kind: someExpr, children: []expr{e}}
+		}
+		switch n.Child[0].Token {
+		case "&":
+			e = expr{kind: andExpr, children: []expr{e}}
+		case "!":
+			e = expr{kind: notExpr, children: []expr{e}}
+		}
+		n.Result = e
+	})
+
+	_sequence = Some(_suffixed).Map(func(n *Result) {
+		n.Result = collapse(sequenceExpr, n.Child)
+	})
+
+	_definition = Seq(_identifier, _arrow, Cut(), &_expression).Map(func(n *Result) {
+		n.Result = definition{name: n.Child[0].Token, pos: n.Child[0].Start, expr: n.Child[3].Result.(expr)}
+	})
+
+	_grammar = Some(_definition).Map(func(n *Result) {
+		defs := make([]definition, len(n.Child))
+		for i, child := range n.Child {
+			defs[i] = child.Result.(definition)
+		}
+		n.Result = defs
+	})
+)
+
+func init() {
+	_expression = Seq(_sequence, Many(Seq("/", Cut(), _sequence))).Map(func(n *Result) {
+		alternatives := []Result{n.Child[0]}
+		for _, alternative := range n.Child[1].Child {
+			alternatives = append(alternatives, alternative.Child[2])
+		}
+		n.Result = collapse(choiceExpr, alternatives)
+	})
+}
+
+// collapse joins the expressions in children, leaving a single expression on its own.
+func collapse(kind exprKind, children []Result) expr {
+	if len(children) == 1 {
+		return children[0].Result.(expr)
+	}
+	e := expr{kind: kind}
+	for _, child := range children {
+		e.children = append(e.children, child.Result.(expr))
+	}
+	return e
+}
+
+// unescapeClass turns the escapes in a character class into the format goparsify.Chars uses,
+// where a backslash makes the next character literal.
+func unescapeClass(class string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t")
+	return replacer.Replace(class)
+}
@@ -0,0 +1,153 @@
+package peg
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ijt/goparsify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	list, err := Compile(`
+		# a list of numbers, possibly nested
+		list   <- "[" values? "]"
+		values <- value ("," value)*
+		value  <- number / list
+		number = [0-9]+
+	`, nil)
+	require.NoError(t, err)
+
+	_, _, err = goparsify.Run(list, "[1, [23], 4]")
+	require.NoError(t, err)
+
+	_, _, err = goparsify.Run(list, "[1, ]")
+	require.Error(t, err)
+}
+
+func TestActions(t *testing.T) {
+	number, err := Compile(`number <- [0-9]+`, Actions{
+		"number": func(n *goparsify.Result) {
+			var digits string
+			for _, child := range n.Child {
+				digits += child.Token
+			}
+			n.Result, _ = strconv.Atoi(digits)
+		},
+	})
+	require.NoError(t, err)
+
+	result, _, err := goparsify.Run(number, "123")
+	require.NoError(t, err)
+	require.Equal(t, 123, result)
+}
+
+func TestExpressions(t *testing.T) {
+	tests := []struct {
+		grammar string
+		input   string
+		ok      bool
+	}{
+		{`a <- "x" 'y'`, "xy", true},
+		{`a <- "x" / "y"`, "y", true},
+		{`a <- "x" / "y"`, "z", false},
+		{`a <- "x"? "y"`, "y", true},
+		{`a <- "x"* "y"`, "xxxy", true},
+		{`a <- "x"+ "y"`, "y", false},
+		{`a <- [a-c\]]+`, "ab]c", true},
+		{`a <- [^a-c]`, "d", true},
+		{`a <- [^a-c]`, "a", false},
+		{`a <- . .`, "ab", true},
+		{`a <- !"x" .`, "y", true},
+		{`a <- !"x" .`, "x", false},
+		{`a <- &"x" .`, "x", true},
+		{`a <- &"x" .`, "y", false},
+		{`a <- ("x" "y")+`, "xyxy", true},
+		{"a <- b \"y\"\nb <- \"x\"", "xy", true},
+		{`a <- "\""`, `"`, true},
+		{`a <- .`, "", false},
+		{`a <- "a" .`, "a", false},
+		{`a <- "a" !.`, "a", true},
+		{`a <- "a" !.`, "ab", false},
+		{`a <- "a" ("b" / !.)`, "a", true},
+		{`a <- "a" ("b" / !.)`, "ab", true},
+		{`a <- "a" ("b" / "c")`, "a", false},
+	}
+	for _, test := range tests {
+		t.Run(test.grammar+" "+test.input, func(t *testing.T) {
+			p, err := Compile(test.grammar, nil)
+			require.NoError(t, err)
+			_, _, err = goparsify.Run(p, test.input, goparsify.WithWhitespace(goparsify.NoWhitespace))
+			if test.ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	p, err := Compile(`a <- .`, nil)
+	require.NoError(t, err)
+	_, _, err = goparsify.Run(p, "")
+	require.EqualError(t, err, "offset 0: unexpected end of input, expected any character in rule a")
+}
+
+func TestCut(t *testing.T) {
+	p, err := Compile(`
+		stmt <- "let" ~ name / name
+		name <- [a-z]+
+	`, nil)
+	require.NoError(t, err)
+
+	_, _, err = goparsify.Run(p, "letter")
+	require.NoError(t, err)
+
+	_, _, err = goparsify.Run(p, "let 1")
+	require.EqualError(t, err, "offset 4: expected a-z in rule name")
+}
+
+func TestRuleNames(t *testing.T) {
+	p := MustCompile(`
+		pair <- key ":" key
+		key  <- [a-z]+
+	`, nil)
+
+	ps := goparsify.NewState("a:b")
+	result := goparsify.Result{}
+	p(ps, &result)
+	require.False(t, ps.Errored())
+	require.Equal(t, "pair", result.Name)
+	require.Equal(t, "key", result.Child[0].Name)
+	require.Equal(t, "key", result.Child[2].Name)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := map[string]struct {
+		grammar string
+		actions Actions
+		err     string
+	}{
+		"syntax":         {`a <- "x" / )`, nil, "peg: offset 11: expected rule name"},
+		"trailing input": {`a <- "x" )`, nil, "peg: left unparsed: )"},
+		"undefined rule": {`a <- b`, nil, "peg: offset 5: undefined rule b"},
+		"duplicate rule": {"a <- \"x\"\na <- \"y\"", nil, "peg: offset 9: rule a is defined more than once"},
+		"unknown action": {`a <- "x"`, Actions{"b": func(*goparsify.Result) {}}, "peg: action for undefined rule b"},
+		"unclosed group": {`a <- ("x"`, nil, "peg: offset 9: unexpected end of input, expected )"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(test.grammar, test.actions)
+			require.EqualError(t, err, test.err)
+		})
+	}
+
+	require.Panics(t, func() { MustCompile(`a <-`, nil) })
+}
+
+func TestAnalyze(t *testing.T) {
+	p := MustCompile(`expr <- expr "+" num / num
+		num <- [0-9]+`, nil)
+	findings := goparsify.Analyze(p)
+	require.Len(t, findings, 1)
+	require.Equal(t, goparsify.LeftRecursion, findings[0].Kind)
+}
@@ -1,6 +1,10 @@
 package goparsify
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func BenchmarkAny(b *testing.B) {
 	p := Any("hello", "goodbye", "help")
@@ -13,3 +17,95 @@ func BenchmarkAny(b *testing.B) {
 		_, _, _ = Run(p, "help me")
 	}
 }
+
+func BenchmarkDateTimeLongInput(b *testing.B) {
+	// A timestamp that runs into a long word and a long word that isn't one at all.
+	p := DateTime(time.RFC3339, "Jan _2 15:04:05")
+	glued := "2024-03-01T12:30:00Z" + strings.Repeat("x", 100000)
+	garbage := strings.Repeat("9", 100000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = RunPrefix(p, glued)
+		_, _, _ = Run(p, garbage)
+	}
+}
+
+func BenchmarkLexer(b *testing.B) {
+	// whitespace skips # comments, which makes skipping it again at each alternative costly.
+	whitespace := func(s *State) {
+		for {
+			UnicodeWhitespace(s)
+			if !strings.HasPrefix(s.Get(), "#") {
+				return
+			}
+			for s.Pos < len(s.Input) && s.Input[s.Pos] != '\n' {
+				s.Pos++
+			}
+		}
+	}
+	keywords := Any("if", "then", "else", "end", "while", "do", "return")
+	parser := Some(Any(keywords, Chars("a-z"), Chars("0-9")))
+	input := strings.Repeat("if x # the condition\n then return 1\n # otherwise\n else y end\n", 20)
+
+	b.Run("without", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = RunTree(parser, input, WithWhitespace(whitespace))
+		}
+	})
+
+	b.Run("with", func(b *testing.B) {
+		lexer := ExtractLexer(parser)
+		for i := 0; i < b.N; i++ {
+			_, _ = RunTree(parser, input, WithWhitespace(whitespace), WithLexer(lexer))
+		}
+	})
+}
+
+func BenchmarkLexerDispatch(b *testing.B) {
+	// Each statement starts with a keyword of its own, so the lexer goes straight to it.
+	statement := Any(
+		Seq("let", Chars("a-z"), "=", Chars("0-9")),
+		Seq("print", Chars("a-z")),
+		Seq("goto", Chars("0-9")),
+		Seq("if", Chars("a-z"), "then", Chars("0-9")),
+		Seq("return", Chars("a-z")),
+	)
+	parser := Some(statement)
+	input := strings.Repeat("let x = 1 print x if x then 10 goto 20 return x\n", 20)
+
+	b.Run("without", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = RunTree(parser, input)
+		}
+	})
+
+	b.Run("with", func(b *testing.B) {
+		lexer := ExtractLexer(parser)
+		for i := 0; i < b.N; i++ {
+			_, _ = RunTree(parser, input, WithLexer(lexer))
+		}
+	})
+}
+
+func BenchmarkPooling(b *testing.B) {
+	var value Parser
+	list := Seq("[", Many(&value, ","), "]")
+	value = Any(Chars("0-9"), list)
+	input := "[1, [2, 3, [4]], [], 5, 6, 7, 8, 9, 10]"
+
+	b.Run("without", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = RunTree(&value, input)
+		}
+	})
+
+	b.Run("with", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			result, _ := RunTree(&value, input, WithPooling())
+			result.Release()
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package goparsify
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// maxPooledChildren is the capacity of the largest slices of children kept for reuse, which
+// is a power of two.
+const (
+	maxPoolClass      = 10
+	maxPooledChildren = 1 << maxPoolClass
+)
+
+// childPools holds the slices of children released by Result.Release, one pool for each
+// power of two capacity up to maxPooledChildren.
+var childPools [maxPoolClass + 1]sync.Pool
+
+// boxPool holds the pointers the slices are kept in by childPools once they're taken out, so
+// putting slices back doesn't allocate.
+var boxPool sync.Pool
+
+// poolClass returns the pool for slices with room for n children, or -1 if they are too big.
+func poolClass(n int) int {
+	if n > maxPooledChildren {
+		return -1
+	}
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// children returns a slice of n empty children with room for at least capacity, from the
+// pools when the parse uses them, see WithPooling.
+func (s *State) children(n, capacity int) []Result {
+	if !s.cfg().pool {
+		return make([]Result, n, capacity)
+	}
+	return getChildren(n, capacity)
+}
+
+// appendChild adds an empty child to children like append, taking bigger slices from the pools
+// when the parse uses them and giving back the ones outgrown.
+func (s *State) appendChild(children []Result) []Result {
+	if !s.cfg().pool || len(children) < cap(children) {
+		return append(children, Result{})
+	}
+	grown := getChildren(len(children)+1, 2*cap(children))
+	copy(grown, children)
+	putChildren(children)
+	return grown
+}
+
+// getChildren returns a slice of n empty children with room for at least capacity.
+func getChildren(n, capacity int) []Result {
+	class := poolClass(capacity)
+	if class < 0 {
+		return make([]Result, n, capacity)
+	}
+	if box, ok := childPools[class].Get().(*[]Result); ok {
+		children := (*box)[:n]
+		*box = nil
+		boxPool.Put(box)
+		return children
+	}
+	return make([]Result, n, 1<<class)
+}
+
+// putChildren clears children and keeps it for reuse, if its capacity is one the pools hold.
+func putChildren(children []Result) {
+	class := poolClass(cap(children))
+	if class < 0 || cap(children) != 1<<class {
+		return
+	}
+	children = children[:cap(children)]
+	for i := range children {
+		children[i] = Result{}
+	}
+	box, ok := boxPool.Get().(*[]Result)
+	if !ok {
+		box = new([]Result)
+	}
+	*box = children[:0]
+	childPools[class].Put(box)
+}
+
+// Release gives the children of r and everything under them back to be reused by parses run
+// WithPooling, so a server parsing a request doesn't leave garbage behind the size of the tree.
+// Neither r nor anything taken from its children, eg a *Result from Get, may be used afterwards,
+// but tokens and results are left alone. Trees from parses without pooling can be released too,
+// unless a Map put the same children in two places.
+func (r *Result) Release() {
+	for i := range r.Child {
+		r.Child[i].Release()
+	}
+	putChildren(r.Child)
+	r.Child = nil
+}
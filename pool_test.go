@@ -0,0 +1,53 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooling(t *testing.T) {
+	var value Parser
+	list := Seq("[", Many(&value, ","), "]")
+	value = Any(Chars("0-9"), list)
+	input := "[1, [2, 3, [4]], [], " + "5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17]"
+
+	want, err := RunTree(&value, input)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		got, err := RunTree(&value, input, WithPooling())
+		require.NoError(t, err)
+		require.Equal(t, dumpString(want), dumpString(got))
+		got.Release()
+		require.Nil(t, got.Child)
+	}
+}
+
+func TestPoolClass(t *testing.T) {
+	require.Equal(t, 0, poolClass(0))
+	require.Equal(t, 0, poolClass(1))
+	require.Equal(t, 1, poolClass(2))
+	require.Equal(t, 3, poolClass(5))
+	require.Equal(t, 3, poolClass(8))
+	require.Equal(t, maxPoolClass, poolClass(maxPooledChildren))
+	require.Equal(t, -1, poolClass(maxPooledChildren+1))
+}
+
+func TestAppendChild(t *testing.T) {
+	ps := NewState("")
+	ps.config = newRunConfig([]Option{WithPooling()})
+	children := ps.children(0, 5)
+	require.Equal(t, 8, cap(children))
+	for i := 0; i < 9; i++ {
+		children = ps.appendChild(children)
+		children[i].Token = "x"
+	}
+	require.Len(t, children, 9)
+	require.Equal(t, 16, cap(children))
+	require.Equal(t, "x", children[0].Token)
+	require.Equal(t, Result{}, children[:cap(children)][9])
+
+	putChildren(children)
+	require.Equal(t, Result{}, children[0], "released children are cleared")
+}
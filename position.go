@@ -0,0 +1,111 @@
+package goparsify
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Pos is a place in the input, as a byte offset along with the rune offset, line and
+// column it is at. Lines and columns count from 1, with columns counted in runes, so they are
+// what editors and compilers show. State.Pos, the spans of Results and Error.Pos stay byte
+// offsets, which is all parsing needs, and State.Position, Error.Position and LineIndex.Span
+// give the Pos of them.
+type Pos struct {
+	Offset int
+	Rune   int
+	Line   int
+	Column int
+}
+
+// LineIndex works out the Pos of offsets into an input. The lines are only found the
+// first time it's asked for one, and once for all of them.
+type LineIndex struct {
+	input string
+	once  sync.Once
+	// lines holds the offset each line starts at and runes the number of runes before it.
+	lines []int
+	runes []int
+}
+
+// NewLineIndex returns a LineIndex for input, eg to find where the spans of the
+// Results returned by Run on it are.
+func NewLineIndex(input string) *LineIndex {
+	return &LineIndex{input: input}
+}
+
+func (l *LineIndex) build() {
+	l.lines, l.runes = []int{0}, []int{0}
+	runes := 0
+	for i, r := range l.input {
+		runes++
+		if r == '\n' {
+			l.lines = append(l.lines, i+1)
+			l.runes = append(l.runes, runes)
+		}
+	}
+}
+
+// Position returns the Pos of offset, which is clamped to the input.
+func (l *LineIndex) Position(offset int) Pos {
+	l.once.Do(l.build)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(l.input) {
+		offset = len(l.input)
+	}
+	line := sort.Search(len(l.lines), func(i int) bool { return l.lines[i] > offset }) - 1
+	column := utf8.RuneCountInString(l.input[l.lines[line]:offset])
+	return Pos{Offset: offset, Rune: l.runes[line] + column, Line: line + 1, Column: column + 1}
+}
+
+// Span returns the Pos of the start and end of r.
+func (l *LineIndex) Span(r *Result) (start, end Pos) {
+	return l.Position(r.Start), l.Position(r.End)
+}
+
+// lineIndex returns the LineIndex of the input, made the first time it's needed and again when
+// an Include switches to another. The input of a branch limited by WithBranchBudget starts the
+// whole input, so its lines are found in the LineIndex of that.
+func (s *State) lineIndex() *LineIndex {
+	if s.lines == nil || !strings.HasPrefix(s.lines.input, s.Input) {
+		s.lines = NewLineIndex(s.Input)
+	}
+	return s.lines
+}
+
+// Position returns the Pos of Pos, or under RunTokens that of the token at Pos.
+func (s *State) Position() Pos {
+	return s.lineIndex().Position(s.offset(s.Pos))
+}
+
+// indexError keeps the text the error is in with it, for Error.Position.
+func (s *State) indexError() {
+	text := s.Input
+	if n := len(s.Error.sources); n > 0 {
+		text = s.Error.sources[n-1].Input
+	}
+	s.Error.index(text)
+}
+
+// index keeps text as the text the error is in, at pos into it, for Position.
+func (e *Error) index(text string) {
+	e.text, e.at, e.indexed, e.lines = text, e.pos, true, nil
+}
+
+// Position returns the Pos of the error. Like Pos, all of it is counted in the input given to
+// Run, before decoding and normalization, or in the included source the error is in, if it is
+// in one. Errors made by hand have only the Offset.
+func (e *Error) Position() Pos {
+	if !e.indexed {
+		return Pos{Offset: e.pos}
+	}
+	if e.lines == nil {
+		e.lines = NewLineIndex(e.text)
+	}
+	p := e.lines.Position(e.at)
+	p.Offset = e.pos
+	return p
+}
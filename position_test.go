@@ -0,0 +1,75 @@
+package goparsify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineIndex(t *testing.T) {
+	lines := NewLineIndex("ab\nπc\n\nd")
+	require.Equal(t, Pos{Offset: 0, Rune: 0, Line: 1, Column: 1}, lines.Position(0))
+	require.Equal(t, Pos{Offset: 2, Rune: 2, Line: 1, Column: 3}, lines.Position(2))
+	require.Equal(t, Pos{Offset: 3, Rune: 3, Line: 2, Column: 1}, lines.Position(3))
+	require.Equal(t, Pos{Offset: 5, Rune: 4, Line: 2, Column: 2}, lines.Position(5))
+	require.Equal(t, Pos{Offset: 7, Rune: 6, Line: 3, Column: 1}, lines.Position(7))
+	require.Equal(t, Pos{Offset: 9, Rune: 8, Line: 4, Column: 2}, lines.Position(9))
+
+	t.Run("clamps offsets", func(t *testing.T) {
+		require.Equal(t, lines.Position(0), lines.Position(-1))
+		require.Equal(t, lines.Position(9), lines.Position(100))
+	})
+
+	t.Run("spans results", func(t *testing.T) {
+		input := "a\n  bc"
+		node, _ := runParser(input, Seq("a", "bc"))
+		start, end := NewLineIndex(input).Span(&node.Child[1])
+		require.Equal(t, Pos{Offset: 4, Rune: 4, Line: 2, Column: 3}, start)
+		require.Equal(t, Pos{Offset: 6, Rune: 6, Line: 2, Column: 5}, end)
+	})
+}
+
+func TestStatePosition(t *testing.T) {
+	ps := NewState("ab\nπc")
+	ps.Pos = 5
+	require.Equal(t, Pos{Offset: 5, Rune: 4, Line: 2, Column: 2}, ps.Position())
+
+	ps = NewState("int x")
+	ps.tokens = []Token{{Kind: "ident", Text: "int", Offset: 0}, {Kind: "ident", Text: "x", Offset: 4}}
+	ps.Pos = 1
+	require.Equal(t, Pos{Offset: 4, Rune: 4, Line: 1, Column: 5}, ps.Position())
+
+	t.Run("keeps the index of the whole input in a branch window", func(t *testing.T) {
+		ps := NewState("ab\ncd\nef")
+		ps.Pos = 4
+		require.Equal(t, 2, ps.Position().Line)
+		lines := ps.lines
+		ps.Input = ps.Input[:6]
+		require.Equal(t, Pos{Offset: 4, Rune: 4, Line: 2, Column: 2}, ps.Position())
+		require.Same(t, lines, ps.lines)
+
+		ps.Input = "xy\nzz"
+		require.Equal(t, Pos{Offset: 4, Rune: 4, Line: 2, Column: 2}, ps.Position())
+		require.True(t, lines != ps.lines)
+	})
+}
+
+func TestErrorPosition(t *testing.T) {
+	position := func(err error) Pos {
+		var perr *Error
+		require.True(t, errors.As(err, &perr), "%v", err)
+		return perr.Position()
+	}
+
+	_, _, err := Run(Some(Seq("let", Chars("a-z"))), "let π\nlet 1")
+	require.Equal(t, Pos{Offset: 4, Rune: 4, Line: 1, Column: 5}, position(err))
+
+	_, _, err = Run(Seq("a", "b"), "\r\na\r\n\r\nc", WithNormalizedNewlines())
+	require.Equal(t, Pos{Offset: 7, Rune: 7, Line: 4, Column: 1}, position(err))
+
+	_, _, err = Run(Seq("a", "b"), "\ufeffa\nπ c", WithoutBOM())
+	require.Equal(t, Pos{Offset: 5, Rune: 3, Line: 2, Column: 1}, position(err))
+
+	require.Equal(t, Pos{Offset: 3}, (&Error{pos: 3}).Position())
+}
@@ -0,0 +1,73 @@
+package goparsify
+
+// ErrorKey is the annotation in Result.Meta holding the *Error of a result made by
+// Recovering in place of what failed to parse.
+const ErrorKey = "error"
+
+// Recovering runs p, and if it fails skips ahead to where sync matches and carries on from
+// there, so one mistake doesn't stop the rest of the input from being parsed, eg in an editor
+// or linter. The input skipped, from where p started up to sync, becomes an error result in
+// the tree, with the error p failed with under ErrorKey in Meta. Sync itself is left for what
+// comes next to match, so it has to be part of the grammar after p:
+//
+//	statements := Many(Seq(Recovering(statement, ";"), ";"))
+//
+// If sync matches nowhere the rest of the input is skipped, and if there is none left p's error
+// stands, so a list of them ends at the end of the input. The parse succeeds despite the errors
+// recovered from, which RecoveredErrors returns.
+func Recovering(p Parserish, sync Parserish) Parser {
+	parser := Parsify(p)
+	syncParser := Parsify(sync)
+	// Recovering matches the input it skips when p doesn't match.
+	rule := &grammarRule{kind: "Recovering()", withoutChild: true}
+
+	return NewParser("Recovering()", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parser, false)
+			return
+		}
+		m, cut := ps.Mark(), ps.Cut
+		parser(ps, node)
+		if !ps.Errored() {
+			return
+		}
+		err, fatal := ps.Error, ps.fatal
+		ps.Restore(m)
+		ps.Recover()
+		// A Cut in p only held inside it, it mustn't stop the search for sync.
+		ps.Cut = cut
+		ps.WS(ps)
+		start := ps.Pos
+
+		end := ps.length()
+		var match Result
+		if at, found, _ := findNext(ps, syncParser, &match); found {
+			end = at
+		} else if end == start {
+			// There is nothing left to skip, so nothing to recover with.
+			ps.Restore(m)
+			ps.Error, ps.fatal = err, fatal
+			return
+		}
+		ps.Recover()
+		if ps.tokens != nil {
+			err.mapOffsets(ps.tokenOffset)
+		}
+		*node = Result{Token: ps.text(start, end), Start: ps.offset(start), End: ps.offset(end)}
+		node.SetMeta(ErrorKey, &err)
+		ps.Pos = end
+	})
+}
+
+// RecoveredErrors returns the errors Recovering recovered from in the tree under r, in the
+// order they were found.
+func RecoveredErrors(r *Result) []*Error {
+	var errs []*Error
+	Walk(r, func(n *Result, depth int) bool {
+		if err, ok := n.Meta[ErrorKey].(*Error); ok {
+			errs = append(errs, err)
+		}
+		return true
+	})
+	return errs
+}
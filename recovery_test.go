@@ -0,0 +1,71 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovering(t *testing.T) {
+	statement := Seq(Chars("a-z"), "=", Chars("0-9"))
+	statements := Many(Seq(Recovering(statement, ";"), ";"))
+
+	t.Run("without errors", func(t *testing.T) {
+		tree, err := RunTree(statements, "a = 1; b = 2;")
+		require.NoError(t, err)
+		require.Empty(t, RecoveredErrors(&tree))
+	})
+
+	t.Run("skips to the sync point", func(t *testing.T) {
+		input := "a = 1; b = ; c = 3; = 4;"
+		tree, err := RunTree(statements, input)
+		require.NoError(t, err)
+		require.Len(t, tree.Child, 4)
+
+		bad := tree.Child[1].Child[0]
+		require.Equal(t, "b = ", bad.Token)
+		require.Equal(t, 7, bad.Start)
+		require.Equal(t, 11, bad.End)
+		require.Equal(t, "c", tree.Child[2].Child[0].Child[0].Token)
+
+		errs := RecoveredErrors(&tree)
+		require.Len(t, errs, 2)
+		require.Equal(t, "offset 11: expected 0-9", errs[0].Error())
+		require.Equal(t, "offset 20: expected a-z", errs[1].Error())
+		require.Same(t, errs[0], bad.Meta[ErrorKey])
+	})
+
+	t.Run("past a cut", func(t *testing.T) {
+		statement := Seq("let", Cut(), Chars("a-z"), "=", Chars("0-9"))
+		tree, err := RunTree(Many(Seq(Recovering(statement, ";"), ";")), "let x = ; let y = 2;")
+		require.NoError(t, err)
+		require.Len(t, tree.Child, 2)
+		require.Len(t, RecoveredErrors(&tree), 1)
+	})
+
+	t.Run("at offsets into the input", func(t *testing.T) {
+		tree, err := RunTree(statements, "a = 1;\r\nb = ;", WithNormalizedNewlines())
+		require.NoError(t, err)
+		errs := RecoveredErrors(&tree)
+		require.Len(t, errs, 1)
+		require.Equal(t, "offset 12: expected 0-9", errs[0].Error())
+		require.Equal(t, 8, tree.Child[1].Child[0].Start)
+	})
+
+	t.Run("without a sync point", func(t *testing.T) {
+		tree, err := RunTree(Recovering(statement, ";"), "a = b c")
+		require.NoError(t, err)
+		require.Equal(t, "a = b c", tree.Token)
+		require.Equal(t, 7, tree.End)
+	})
+
+	t.Run("at the end of the input", func(t *testing.T) {
+		tree, err := RunTree(Many(Recovering(statement, ";")), "a = 1 b =")
+		require.NoError(t, err)
+		require.Len(t, tree.Child, 2)
+		require.Len(t, RecoveredErrors(&tree), 1)
+
+		_, err = RunTree(Recovering(statement, ";"), "")
+		require.EqualError(t, err, "offset 0: unexpected end of input, expected a-z")
+	})
+}
@@ -1,11 +1,17 @@
 package goparsify
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 )
 
 // TrashResult is used in places where the result isnt wanted, but something needs to be passed in to satisfy the interface.
+//
+// Deprecated: parses running at the same time would all write to it. Pass a Result of your own
+// instead.
 var TrashResult = &Result{}
 
 // Result is the output of a parser. Usually only one of its fields will be set and should be though of
@@ -15,6 +21,43 @@ type Result struct {
 	Token  string
 	Child  []Result
 	Result interface{}
+	// Name is the rule name given with Named, if any.
+	Name string
+	// Kind is the kind given with Kinded, if any, so code walking the tree can switch on its own
+	// constants rather than compare names. 0 means none.
+	Kind int
+	// Start and End are the byte offsets of the input matched by this node, excluding leading whitespace.
+	Start, End int
+	// Meta holds annotations added by WithMeta or by later passes over the tree, eg types or
+	// resolved symbols. It is shared by copies of the Result.
+	Meta map[string]interface{}
+	// Trivia is set by RunTree with WithTrivia, see Reconstruct.
+	Trivia *Trivia
+}
+
+// SetMeta sets the annotation key on r.
+func (r *Result) SetMeta(key string, value interface{}) {
+	if r.Meta == nil {
+		r.Meta = map[string]interface{}{}
+	}
+	r.Meta[key] = value
+}
+
+// GetMeta returns the annotation key of r and whether it is set.
+func (r *Result) GetMeta(key string) (interface{}, bool) {
+	value, ok := r.Meta[key]
+	return value, ok
+}
+
+// Get returns the first child of r with the given name, eg one set by Named or a named group of
+// RegexGroups, or nil if there isn't one.
+func (r *Result) Get(name string) *Result {
+	for i := range r.Child {
+		if r.Child[i].Name == name {
+			return &r.Child[i]
+		}
+	}
+	return nil
 }
 
 // String stringifies a node. This is only called from debug code.
@@ -36,3 +79,110 @@ func (r Result) String() string {
 
 	return r.Token
 }
+
+// Dump writes the tree rooted at r to w, one node per line with children indented below their
+// parent. Each line shows the rule name if there is one, the span of input matched, the token
+// quoted and truncated to keep lines short, and the bound result if any.
+func (r Result) Dump(w io.Writer) {
+	r.dump(w, 0)
+}
+
+func (r Result) dump(w io.Writer, depth int) {
+	fmt.Fprintln(w, strings.Repeat("  ", depth)+r.summary())
+
+	for _, child := range r.Child {
+		child.dump(w, depth+1)
+	}
+}
+
+// summary describes r on a single line, the way Dump does.
+func (r Result) summary() string {
+	var line string
+	if r.Name != "" {
+		line += r.Name + " "
+	}
+	line += fmt.Sprintf("%d..%d %s", r.Start, r.End, truncatedQuote(r.Token, 40))
+	if r.Result != nil {
+		if rs, ok := r.Result.(fmt.Stringer); ok {
+			line += " = " + rs.String()
+		} else {
+			line += fmt.Sprintf(" = %#v", r.Result)
+		}
+	}
+	return line
+}
+
+// MarshalJSON encodes the tree rooted at r as nested objects with the fields name, kind, token,
+// start, end, children and result, leaving out name, kind, children and result when they are empty. Results
+// that can't be encoded as JSON are written as the string String would give them.
+func (r Result) MarshalJSON() ([]byte, error) {
+	node := struct {
+		Name     string          `json:"name,omitempty"`
+		Kind     int             `json:"kind,omitempty"`
+		Token    string          `json:"token"`
+		Start    int             `json:"start"`
+		End      int             `json:"end"`
+		Children []Result        `json:"children,omitempty"`
+		Result   json.RawMessage `json:"result,omitempty"`
+	}{
+		Name:     r.Name,
+		Kind:     r.Kind,
+		Token:    r.Token,
+		Start:    r.Start,
+		End:      r.End,
+		Children: r.Child,
+	}
+	if r.Result != nil {
+		result, err := json.Marshal(r.Result)
+		if err != nil {
+			result, _ = json.Marshal(Result{Result: r.Result}.String())
+		}
+		node.Result = result
+	}
+	return json.Marshal(node)
+}
+
+// SExpr writes the tree rooted at r to w as an S-expression, with each node written as
+// (name "token" children...). Nodes without a name are written with _ as their name.
+func (r Result) SExpr(w io.Writer) {
+	name := r.Name
+	if name == "" {
+		name = "_"
+	}
+	fmt.Fprintf(w, "(%s %s", name, strconv.Quote(r.Token))
+	for _, child := range r.Child {
+		io.WriteString(w, " ")
+		child.SExpr(w)
+	}
+	io.WriteString(w, ")")
+}
+
+// pruneEmptyChildren removes the children that matched nothing but whitespace and carry no
+// other information, see PruneEmpty.
+func (r *Result) pruneEmptyChildren() {
+	children := r.Child[:0]
+	for _, child := range r.Child {
+		if child.Name == "" && child.Kind == 0 && child.Result == nil && child.Meta == nil && len(child.Child) == 0 && strings.TrimSpace(child.Token) == "" {
+			continue
+		}
+		children = append(children, child)
+	}
+	r.Child = children
+}
+
+// spanChildren sets the span of a node built from its children, or an empty span at pos if
+// none of them matched anything.
+func (r *Result) spanChildren(pos int) {
+	r.Start, r.End = pos, pos
+	first := true
+	for _, child := range r.Child {
+		if child.Start == child.End {
+			continue
+		}
+		if first {
+			r.Start = child.Start
+			first = false
+		}
+		r.End = child.End
+	}
+}
@@ -1,7 +1,10 @@
 package goparsify
 
 import (
+	"bytes"
+	"encoding/json"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,3 +16,68 @@ func TestResult_String(t *testing.T) {
 	require.Equal(t, "10", Result{Result: 10}.String())
 	require.Equal(t, "10", Result{Result: big.NewInt(10)}.String())
 }
+
+func TestResult_Dump(t *testing.T) {
+	number := Named("number", Chars("0-9"))
+	sum := Named("sum", Seq(number, Bind("+", "plus"), number))
+
+	result, ps := runParser("1 + 22", sum)
+	require.False(t, ps.Errored())
+
+	buf := &bytes.Buffer{}
+	result.Dump(buf)
+	require.Equal(t, `sum 0..6 "1 + 22"
+  number 0..1 "1"
+  2..3 "+" = "plus"
+  number 4..6 "22"
+`, buf.String())
+}
+
+func TestResult_DumpTruncatesTokens(t *testing.T) {
+	buf := &bytes.Buffer{}
+	Result{Token: strings.Repeat("a", 50) + "\n", End: 51}.Dump(buf)
+	require.Equal(t, `0..51 "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa..."`+"\n", buf.String())
+}
+
+func TestResult_MarshalJSON(t *testing.T) {
+	number := Named("number", Chars("0-9"))
+	sum := Named("sum", Seq(number, Bind("+", "plus"), number))
+
+	result, ps := runParser("1 + 22", sum)
+	require.False(t, ps.Errored())
+
+	b, err := json.Marshal(result)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "sum", "token": "1 + 22", "start": 0, "end": 6,
+		"children": [
+			{"name": "number", "token": "1", "start": 0, "end": 1},
+			{"token": "+", "start": 2, "end": 3, "result": "plus"},
+			{"name": "number", "token": "22", "start": 4, "end": 6}
+		]
+	}`, string(b))
+
+	t.Run("kinds", func(t *testing.T) {
+		b, err := json.Marshal(Result{Token: "x", Kind: 3})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"kind": 3, "token": "x", "start": 0, "end": 0}`, string(b))
+	})
+
+	t.Run("results that arent json are stringified", func(t *testing.T) {
+		b, err := json.Marshal(Result{Result: func() {}})
+		require.NoError(t, err)
+		require.Contains(t, string(b), `"result":"(func())`)
+	})
+}
+
+func TestResult_SExpr(t *testing.T) {
+	number := Named("number", Chars("0-9"))
+	sum := Named("sum", Seq(number, "+", number))
+
+	result, ps := runParser(`1 + 22`, sum)
+	require.False(t, ps.Errored())
+
+	buf := &bytes.Buffer{}
+	result.SExpr(buf)
+	require.Equal(t, `(sum "1 + 22" (number "1") (_ "+") (number "22"))`, buf.String())
+}
@@ -0,0 +1,111 @@
+package goparsify
+
+// RewriteRule changes the results it matches when passed to Rewrite.
+type RewriteRule struct {
+	// Match selects the results the rule applies to.
+	Match func(n *Result) bool
+	// Rewrite returns the results that take the place of a match in its parent: none to
+	// delete it, its children to lift them up a level, or anything else.
+	Rewrite func(n *Result) []Result
+}
+
+// Rewrite cleans up the tree under n in place, eg to drop punctuation or flatten chains of
+// results with a single child. Children are rewritten before their parents, so a rule sees
+// the results under a match as the rules left them. Each result is rewritten by the first rule
+// that matches it. The root itself is only replaced by a rule that turns it into exactly one
+// result, as it has no parent to take more or fewer.
+func Rewrite(n *Result, rules ...RewriteRule) {
+	rewriteChildren(n, rules)
+	if replacement, ok := applyRewrite(n, rules); ok && len(replacement) == 1 {
+		*n = replacement[0]
+	}
+}
+
+func rewriteChildren(n *Result, rules []RewriteRule) {
+	if len(n.Child) == 0 {
+		return
+	}
+	children := make([]Result, 0, len(n.Child))
+	for i := range n.Child {
+		child := &n.Child[i]
+		rewriteChildren(child, rules)
+		if replacement, ok := applyRewrite(child, rules); ok {
+			children = append(children, replacement...)
+			continue
+		}
+		children = append(children, *child)
+	}
+	n.Child = children
+}
+
+func applyRewrite(n *Result, rules []RewriteRule) ([]Result, bool) {
+	for _, rule := range rules {
+		if rule.Match(n) {
+			return rule.Rewrite(n), true
+		}
+	}
+	return nil, false
+}
+
+// DeleteNodes removes the matching results from the tree.
+func DeleteNodes(match func(n *Result) bool) RewriteRule {
+	return RewriteRule{Match: match, Rewrite: func(n *Result) []Result {
+		return nil
+	}}
+}
+
+// LiftNodes replaces the matching results with their children.
+func LiftNodes(match func(n *Result) bool) RewriteRule {
+	return RewriteRule{Match: match, Rewrite: func(n *Result) []Result {
+		return n.Child
+	}}
+}
+
+// CollapseNodes replaces the matching results that have a single child with that child,
+// flattening chains like expr -> term -> number down to the number.
+func CollapseNodes(match func(n *Result) bool) RewriteRule {
+	return RewriteRule{
+		Match: func(n *Result) bool {
+			return len(n.Child) == 1 && match(n)
+		},
+		Rewrite: func(n *Result) []Result {
+			return n.Child
+		},
+	}
+}
+
+// ReplaceNodes replaces the matching results with the result of f.
+func ReplaceNodes(match func(n *Result) bool, f func(n Result) Result) RewriteRule {
+	return RewriteRule{Match: match, Rewrite: func(n *Result) []Result {
+		return []Result{f(*n)}
+	}}
+}
+
+// HasName matches results given one of the names by Named or Rule.
+func HasName(names ...string) func(n *Result) bool {
+	return func(n *Result) bool {
+		for _, name := range names {
+			if n.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasToken matches results whose token is one of tokens, eg punctuation.
+func HasToken(tokens ...string) func(n *Result) bool {
+	return func(n *Result) bool {
+		for _, token := range tokens {
+			if n.Token == token {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AnyNode matches every result.
+func AnyNode(n *Result) bool {
+	return true
+}
@@ -0,0 +1,52 @@
+package goparsify
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	var value Parser
+	number := Named("number", Chars("0-9"))
+	list := Named("list", Seq("[", Many(&value, ","), "]"))
+	value = Any(number, list)
+
+	t.Run("cleans up a tree", func(t *testing.T) {
+		tree, p := runParser("[1, [2]]", value)
+		require.False(t, p.Errored())
+
+		Rewrite(&tree,
+			DeleteNodes(HasToken("[", "]")),
+			LiftNodes(func(n *Result) bool { return n.Name == "" && len(n.Child) > 0 }),
+		)
+
+		buf := &bytes.Buffer{}
+		tree.Dump(buf)
+		require.Equal(t, `list 0..8 "[1, [2]]"
+  number 1..2 "1"
+  list 4..7 "[2]"
+    number 5..6 "2"
+`, buf.String())
+	})
+
+	t.Run("replaces results", func(t *testing.T) {
+		tree := Result{Child: []Result{{Token: "a"}, {Token: "b"}}}
+		Rewrite(&tree, ReplaceNodes(HasToken("b"), func(n Result) Result {
+			n.Token = "B"
+			return n
+		}))
+		require.Equal(t, "B", tree.Child[1].Token)
+	})
+
+	t.Run("only replaces the root with a single result", func(t *testing.T) {
+		tree := Result{Name: "root", Child: []Result{{Token: "a"}, {Token: "b"}}}
+		Rewrite(&tree, LiftNodes(HasName("root")))
+		require.Equal(t, "root", tree.Name)
+
+		tree = Result{Name: "root", Child: []Result{{Token: "a"}}}
+		Rewrite(&tree, CollapseNodes(AnyNode))
+		require.Equal(t, "a", tree.Token)
+	})
+}
@@ -0,0 +1,128 @@
+package goparsify
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+)
+
+// namedRule is what Named and Rule keep about a rule, for Rules and WriteCoverage to find by
+// walking a grammar. Nothing else holds on to it, so it goes away with the grammar.
+type namedRule struct {
+	name     string
+	location string
+	// hits counts matches while coverage is enabled, since the run of StartCoverage in gen.
+	gen  int64
+	hits int64
+}
+
+// RuleInfo describes a rule of a grammar.
+type RuleInfo struct {
+	Name string
+	// Location is the file and line the rule was created on. It is only known for rules created
+	// while coverage is enabled or when built with -tags debug, and is "" otherwise.
+	Location string
+}
+
+// Rule names a parser everywhere the name can show up: it sets the node .Name like Named, and
+// when built with -tags debug it is used as the parser name in logs, hooks and DumpProfile
+// instead of one guessed from the source. Errors found in it say so, eg "offset 3: expected
+// digit in rule number", naming the innermost rule when rules are nested.
+func Rule(name string, parser Parserish) Parser {
+	p := named(name, parser, 1)
+	return newRuleParser(name, func(ps *State, node *Result) {
+		p(ps, node)
+		if ps.Errored() && ps.Error.rule == "" {
+			ps.Error.rule = name
+		}
+	})
+}
+
+// Rules returns the rules of the grammar of p created with Named or Rule, walking it the way
+// ExportEBNF does, in the order they are first reached. Names aren't required to be unique,
+// grammars may well use the same one for different rules.
+func Rules(p Parserish) []RuleInfo {
+	var infos []RuleInfo
+	for _, rule := range grammarRules(p) {
+		infos = append(infos, RuleInfo{Name: rule.name, Location: rule.location})
+	}
+	return infos
+}
+
+// newNamedRule creates the rule kept by Named. skip is the number of stack frames between the
+// caller and the code defining the rule. Looking up the location is slow for a constructor
+// grammars built over and over go through, so it is only done when it can be reported.
+func newNamedRule(name string, skip int) *namedRule {
+	rule := &namedRule{name: name}
+	if debugBuild || atomic.LoadInt32(&coverageEnabled) != 0 {
+		if _, file, line, ok := runtime.Caller(skip + 1); ok {
+			rule.location = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+		}
+	}
+	return rule
+}
+
+// grammarRules returns the rules kept by Named in the grammar of p, in the order they are
+// first reached.
+func grammarRules(p Parserish) []*namedRule {
+	w := &ruleWalker{done: map[*grammarRule]bool{}}
+	ps := NewState("")
+	ps.analysis = w
+	w.child(ps, Parsify(p))
+	return w.rules
+}
+
+// ruleWalker collects the rules kept by Named as the grammar is walked.
+type ruleWalker struct {
+	done  map[*grammarRule]bool
+	rules []*namedRule
+}
+
+func (w *ruleWalker) child(ps *State, p Parser) {
+	if p == nil {
+		return
+	}
+	p(ps, &Result{})
+	ps.Recover()
+}
+
+// visit walks the parsers of rule the first time it is seen, which also stops at recursion.
+func (w *ruleWalker) visit(ps *State, rule *grammarRule, parsers ...Parser) {
+	if w.done[rule] {
+		return
+	}
+	w.done[rule] = true
+	if rule.named != nil {
+		w.rules = append(w.rules, rule.named)
+	}
+	for _, p := range parsers {
+		w.child(ps, p)
+	}
+}
+
+func (w *ruleWalker) seq(ps *State, rule *grammarRule, parsers []Parser) {
+	w.visit(ps, rule, parsers...)
+}
+
+func (w *ruleWalker) any(ps *State, rule *grammarRule, parsers []Parser) {
+	w.visit(ps, rule, parsers...)
+}
+
+func (w *ruleWalker) many(ps *State, rule *grammarRule, min int, op, sep Parser) {
+	w.visit(ps, rule, op, sep)
+}
+
+func (w *ruleWalker) signalSeq(ps *State, rule *grammarRule, noise Parser, signals []Parser) {
+	w.visit(ps, rule, append([]Parser{noise}, signals...)...)
+}
+
+func (w *ruleWalker) wrap(ps *State, rule *grammarRule, p Parser, nullable bool) {
+	w.visit(ps, rule, p)
+}
+
+func (w *ruleWalker) exact(match string) {}
+
+func (w *ruleWalker) chars(description string, first func(b byte) bool, nullable bool) {}
+
+func (w *ruleWalker) terminal(description string) {}
@@ -0,0 +1,55 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRule(t *testing.T) {
+	greeting := Rule("rules greeting", Seq("hello", "world"))
+
+	result, ps := runParser("hello world", greeting)
+	require.False(t, ps.Errored())
+	require.Equal(t, "rules greeting", result.Name)
+
+	_, ps = runParser("goodbye", greeting)
+	require.Equal(t, "hello", ps.Error.expected)
+	require.Equal(t, "rules greeting", ps.Error.Rule())
+
+	t.Run("names the innermost rule in errors", func(t *testing.T) {
+		number := Rule("number", Chars("0-9"))
+		pair := Rule("pair", Seq("(", number, ")"))
+
+		_, _, err := Run(pair, "(a)")
+		require.EqualError(t, err, "offset 1: expected 0-9 in rule number")
+
+		_, _, err = Run(pair, "(1]")
+		require.EqualError(t, err, "offset 2: expected ) in rule pair")
+
+		_, _, err = Run(Seq(Maybe(number), "x"), "y")
+		require.EqualError(t, err, "offset 0: expected x")
+	})
+}
+
+func TestRules(t *testing.T) {
+	var list Parser
+	first := Rule("rules first", Exact("a"))
+	list = Seq(first, Maybe(Named("rules second", Seq("b", &list))), first)
+	Named("rules unused", Exact("c"))
+
+	rules := Rules(&list)
+	require.Len(t, rules, 2)
+	require.Equal(t, "rules first", rules[0].Name)
+	require.Equal(t, "rules second", rules[1].Name)
+	if !debugBuild {
+		// Locations cost too much to look up for every Named outside of coverage.
+		require.Equal(t, "", rules[0].Location)
+	}
+
+	StartCoverage()
+	defer StopCoverage()
+	rules = Rules(Named("rules located", Exact("a")))
+	require.Len(t, rules, 1)
+	require.Regexp(t, `^rules_test.go:\d+$`, rules[0].Location)
+}
@@ -0,0 +1,66 @@
+package goparsify
+
+import "fmt"
+
+// cutScope is a scope made by Scope, the innermost first.
+type cutScope struct {
+	name string
+	next *cutScope
+	// set is the cut the last CutIn for this scope made, and restore the cut to go back to
+	// when the scope ends if no other cut has been made since, along with who made it.
+	set, restore int
+	restoreBy    *cutScope
+}
+
+// Scope runs p as a region that CutIn(name) in it can commit to. A cut made with CutIn only
+// stops backtracking inside the scope: once p has matched or failed the cut is lifted, so the
+// parsers around the scope can try their other options as if it were an ordinary parser. This
+// makes the committed parts of a grammar explicit, eg
+//
+//	stmt := Scope("stmt", Any(Seq("if", CutIn("stmt"), cond, block), Seq("while", CutIn("stmt"), cond, block)))
+//
+// commits to an if statement once "if" is read, without stopping anything outside stmt from
+// backtracking over it. Scopes nest, and CutIn uses the innermost with the name it's given.
+// Cuts made with Cut still extend past the scope.
+func Scope(name string, p Parserish) Parser {
+	parser := Parsify(p)
+	rule := &grammarRule{kind: "Scope()"}
+
+	return NewParser("Scope("+name+")", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.wrap(ps, rule, parser, false)
+			return
+		}
+		scope := &cutScope{name: name, next: ps.cutScopes, set: -1, restore: ps.Cut, restoreBy: ps.cutBy}
+		ps.cutScopes = scope
+		parser(ps, node)
+		ps.cutScopes = scope.next
+		if ps.cutBy == scope && ps.Cut == scope.set {
+			ps.Cut, ps.cutBy = scope.restore, scope.restoreBy
+		}
+	})
+}
+
+// CutIn prevents backtracking beyond this point within the innermost Scope with the given
+// name, like a Cut that ends with the scope. It panics if it isn't run inside such a scope.
+func CutIn(name string) Parser {
+	return NewParser("CutIn("+name+")", func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal("")
+			return
+		}
+		scope := ps.cutScopes
+		for scope != nil && scope.name != name {
+			scope = scope.next
+		}
+		if scope == nil {
+			panic(fmt.Errorf("CutIn(%q) used outside of Scope(%q)", name, name))
+		}
+		if ps.cutBy != scope || ps.Cut != scope.set {
+			// Someone else cut since this scope last did, and that cut has to outlive it.
+			scope.restore, scope.restoreBy = ps.Cut, ps.cutBy
+		}
+		ps.Commit()
+		scope.set, ps.cutBy = ps.Cut, scope
+	})
+}
@@ -0,0 +1,51 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScope(t *testing.T) {
+	decl := Scope("decl", Any(Seq("var", CutIn("decl"), Chars("a-z"), "="), Seq("var", Chars("a-z"))))
+
+	t.Run("cuts inside the scope", func(t *testing.T) {
+		_, ps := runParser("var x", decl)
+		require.Equal(t, "offset 5: expected =", ps.Error.Error())
+		require.Equal(t, 0, ps.Pos)
+	})
+
+	t.Run("lifts the cut outside the scope", func(t *testing.T) {
+		node, ps := runParser("var x", Any(decl, "var x"))
+		require.False(t, ps.Errored())
+		require.Equal(t, "var x", node.Token)
+
+		node, ps = runParser("var x = 1", Any(Seq(decl, "2"), Seq(decl, "1")))
+		require.False(t, ps.Errored())
+		require.Equal(t, "1", node.Child[1].Token)
+	})
+
+	t.Run("keeps plain cuts", func(t *testing.T) {
+		_, ps := runParser("var x", Any(Seq("var", Cut(), decl), "var x"))
+		require.Equal(t, "offset 4: expected var", ps.Error.Error())
+
+		cut := Scope("decl", Seq("var", CutIn("decl"), Cut(), "x"))
+		_, ps = runParser("var y", Any(cut, "var y"))
+		require.True(t, ps.Errored())
+	})
+
+	t.Run("cuts in the innermost scope with the name", func(t *testing.T) {
+		inner := Scope("inner", Seq("(", CutIn("outer"), Scope("outer", Seq("a", CutIn("outer"))), ")"))
+		outer := Scope("outer", Any(inner, "(b"))
+
+		_, ps := runParser("(b", outer)
+		require.Equal(t, "offset 1: expected a", ps.Error.Error())
+
+		_, ps = runParser("(b", Any(outer, "(b"))
+		require.False(t, ps.Errored())
+	})
+
+	t.Run("outside of a scope", func(t *testing.T) {
+		require.Panics(t, func() { runParser("x", Seq("x", CutIn("decl"))) })
+	})
+}
@@ -0,0 +1,104 @@
+package goparsify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Select returns the results in the tree under root, root included, that match selector, in
+// the order they were parsed. Selectors pick out results by the names given with Named or
+// Rule, a little like CSS:
+//
+//	func                    every result named func
+//	func ident              every ident somewhere under a func
+//	func > params > ident   every ident right under a params right under a func
+//	func > *                every named result right under a func
+//
+// Results without a name are looked through, so "right under" means with no other named
+// result in between, whatever the combinators the rules are made of. Select panics if the
+// selector is malformed.
+func Select(root *Result, selector string) []*Result {
+	steps := parseSelector(selector)
+	var ancestors []*Result
+	var matches []*Result
+	var visit func(n *Result)
+	visit = func(n *Result) {
+		if n.Name != "" {
+			if steps[len(steps)-1].matches(n) && matchesAncestors(steps, ancestors) {
+				matches = append(matches, n)
+			}
+			ancestors = append(ancestors, n)
+		}
+		for i := range n.Child {
+			visit(&n.Child[i])
+		}
+		if n.Name != "" {
+			ancestors = ancestors[:len(ancestors)-1]
+		}
+	}
+	visit(root)
+	return matches
+}
+
+// SelectFunc returns the results in the tree under root, root included, for which match
+// returns true, in the order they were parsed.
+func SelectFunc(root *Result, match func(n *Result) bool) []*Result {
+	var matches []*Result
+	Walk(root, func(n *Result, depth int) bool {
+		if match(n) {
+			matches = append(matches, n)
+		}
+		return true
+	})
+	return matches
+}
+
+// selectorStep is one of the names of a selector.
+type selectorStep struct {
+	name string
+	// child is set when the step has to be right under the one before, ie they're separated
+	// by >.
+	child bool
+}
+
+func (s selectorStep) matches(n *Result) bool {
+	return s.name == "*" || s.name == n.Name
+}
+
+func parseSelector(selector string) []selectorStep {
+	var steps []selectorStep
+	child := false
+	for _, field := range strings.Fields(strings.ReplaceAll(selector, ">", " > ")) {
+		if field == ">" {
+			if child || len(steps) == 0 {
+				panic(fmt.Errorf("misplaced > in selector %q", selector))
+			}
+			child = true
+			continue
+		}
+		steps = append(steps, selectorStep{name: field, child: child})
+		child = false
+	}
+	if len(steps) == 0 || child {
+		panic(fmt.Errorf("selector %q doesn't end with a name", selector))
+	}
+	return steps
+}
+
+// matchesAncestors returns whether the steps before the last one, which a result has matched,
+// match the named results above it, the outermost first.
+func matchesAncestors(steps []selectorStep, ancestors []*Result) bool {
+	if len(steps) == 1 {
+		return true
+	}
+	last, rest := steps[len(steps)-1], steps[:len(steps)-1]
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if rest[len(rest)-1].matches(ancestors[i]) && matchesAncestors(rest, ancestors[:i]) {
+			return true
+		}
+		if last.child {
+			break
+		}
+	}
+	return false
+}
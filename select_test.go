@@ -0,0 +1,56 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	ident := Named("ident", Chars("a-z"))
+	params := Named("params", Seq("(", Many(ident, ","), ")"))
+	body := Named("body", Seq("{", Many(ident), "}"))
+	fn := Named("func", Seq("func", ident, params, body))
+	file := Named("file", Some(fn))
+
+	root, err := RunTree(file, "func main(a, b) { x } func f() { y z }")
+	require.NoError(t, err)
+
+	tokens := func(results []*Result) []string {
+		var tokens []string
+		for _, r := range results {
+			tokens = append(tokens, r.Token)
+		}
+		return tokens
+	}
+
+	require.Equal(t, []string{"main", "a", "b", "x", "f", "y", "z"}, tokens(Select(&root, "ident")))
+	require.Equal(t, []string{"main", "a", "b", "x", "f", "y", "z"}, tokens(Select(&root, "func ident")))
+	require.Equal(t, []string{"main", "f"}, tokens(Select(&root, "func > ident")))
+	require.Equal(t, []string{"a", "b"}, tokens(Select(&root, "func > params > ident")))
+	require.Equal(t, []string{"a", "b"}, tokens(Select(&root, "file func>params ident")))
+	require.Empty(t, Select(&root, "params > body"))
+	require.Len(t, Select(&root, "func > *"), 6)
+	require.Len(t, Select(&root, "file"), 1)
+
+	t.Run("spans", func(t *testing.T) {
+		x := Select(&root, "body > ident")[0]
+		require.Equal(t, "x", x.Token)
+		require.Equal(t, 18, x.Start)
+		require.Equal(t, 19, x.End)
+	})
+
+	t.Run("malformed selectors", func(t *testing.T) {
+		for _, selector := range []string{"", "> a", "a >", "a > > b"} {
+			require.Panics(t, func() { Select(&root, selector) }, selector)
+		}
+	})
+}
+
+func TestSelectFunc(t *testing.T) {
+	tree := &Result{Child: []Result{{Token: "a"}, {Token: "bb", Child: []Result{{Token: "cc"}}}}}
+	long := SelectFunc(tree, func(n *Result) bool { return len(n.Token) == 2 })
+	require.Len(t, long, 2)
+	require.Same(t, &tree.Child[1], long[0])
+	require.Equal(t, "cc", long[1].Token)
+}
@@ -0,0 +1,266 @@
+package goparsify
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version as defined by https://semver.org, bound into .Result by Semver.
+type Version struct {
+	Major, Minor, Patch uint64
+	Prerelease          []string
+	Build               []string
+}
+
+// String formats the version without any leading v.
+func (v Version) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 depending on whether v has lower, equal or higher precedence
+// than o. Build metadata is ignored, as the spec requires.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A version without a prerelease is higher than one with.
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		a, aErr := strconv.ParseUint(v.Prerelease[i], 10, 64)
+		b, bErr := strconv.ParseUint(o.Prerelease[i], 10, 64)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareUint(a, b)
+		case aErr == nil:
+			c = -1 // numeric identifiers are lower than alphanumeric ones
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(v.Prerelease[i], o.Prerelease[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// VersionComparator is a single term of a VersionConstraints, eg >=1.2.0, ~1.4 or 3.x.
+type VersionComparator struct {
+	// Op is one of =, !=, >, >=, <, <=, ~ or ^. A missing operator is stored as =.
+	Op      string
+	Version Version
+	// Parts is how many of major, minor and patch were given. The rest were left off or
+	// written as a wildcard (x, X or *), so 3.x has Parts 1 and * has Parts 0.
+	Parts int
+}
+
+// Check reports whether v satisfies the comparator.
+func (c VersionComparator) Check(v Version) bool {
+	lo, hi := c.bounds()
+	switch c.Op {
+	case "=":
+		return c.inRange(v, lo, hi)
+	case "!=":
+		return !c.inRange(v, lo, hi)
+	case ">":
+		if c.Parts == 3 {
+			return v.Compare(lo) > 0
+		}
+		return hi != nil && v.Compare(*hi) >= 0
+	case ">=":
+		return v.Compare(lo) >= 0
+	case "<":
+		return v.Compare(lo) < 0
+	case "<=":
+		if c.Parts == 3 {
+			return v.Compare(lo) <= 0
+		}
+		return hi == nil || v.Compare(*hi) < 0
+	case "~":
+		upper := Version{Major: lo.Major + 1}
+		if c.Parts >= 2 {
+			upper = Version{Major: lo.Major, Minor: lo.Minor + 1}
+		}
+		return v.Compare(lo) >= 0 && v.Compare(upper) < 0
+	case "^":
+		// Allow changes that don't modify the left-most non-zero part.
+		upper := Version{Major: lo.Major + 1}
+		switch {
+		case lo.Major == 0 && c.Parts >= 2 && lo.Minor > 0:
+			upper = Version{Minor: lo.Minor + 1}
+		case lo.Major == 0 && c.Parts == 3 && lo.Minor == 0:
+			upper = Version{Patch: lo.Patch + 1}
+		case lo.Major == 0 && c.Parts == 2:
+			upper = Version{Minor: 1}
+		}
+		return v.Compare(lo) >= 0 && v.Compare(upper) < 0
+	}
+	return false
+}
+
+// bounds returns the lowest version matching the comparator's version and, for partial
+// versions, the first version above it. hi is nil when there is no upper bound, as for *.
+func (c VersionComparator) bounds() (lo Version, hi *Version) {
+	lo = c.Version
+	switch c.Parts {
+	case 0:
+		return lo, nil
+	case 1:
+		return lo, &Version{Major: lo.Major + 1}
+	case 2:
+		return lo, &Version{Major: lo.Major, Minor: lo.Minor + 1}
+	}
+	return lo, nil
+}
+
+func (c VersionComparator) inRange(v Version, lo Version, hi *Version) bool {
+	if c.Parts == 3 {
+		return v.Compare(lo) == 0
+	}
+	return v.Compare(lo) >= 0 && (hi == nil || v.Compare(*hi) < 0)
+}
+
+// VersionConstraints is bound into .Result by VersionConstraint. It's a list of alternatives
+// separated by ||, each of which is a list of comparators that must all hold.
+type VersionConstraints [][]VersionComparator
+
+// Check reports whether v satisfies any of the alternatives.
+func (cs VersionConstraints) Check(v Version) bool {
+	for _, all := range cs {
+		ok := true
+		for _, c := range all {
+			if !c.Check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	semverNum   = `(?:0|[1-9][0-9]*)`
+	semverIdent = `[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*`
+	semverTail  = `(?:-` + semverIdent + `)?(?:\+` + semverIdent + `)?`
+	semverPart  = `(?:0|[1-9][0-9]*|[xX*])`
+)
+
+// Semver matches a semantic version such as 1.2.3, 1.0.0-rc.1+build.5 or v2.0.0 and binds it
+// into .Result as a Version.
+func Semver() Parser {
+	return NewParser("semver", versionImpl("semver", `v?`+semverNum+`\.`+semverNum+`\.`+semverNum+semverTail, false))
+}
+
+// versionImpl matches pattern and turns it into a Version. When partial is set the result is
+// a VersionComparator recording how many parts were given.
+func versionImpl(name, pattern string, partial bool) Parser {
+	re := NoAutoWS(NamedRegex(name, pattern))
+	return func(ps *State, node *Result) {
+		ps.WS(ps)
+		re(ps, node)
+		if ps.Errored() {
+			return
+		}
+
+		tok := strings.TrimPrefix(node.Token, "v")
+		var v Version
+		if i := strings.IndexByte(tok, '+'); i >= 0 {
+			v.Build = strings.Split(tok[i+1:], ".")
+			tok = tok[:i]
+		}
+		if i := strings.IndexByte(tok, '-'); i >= 0 {
+			v.Prerelease = strings.Split(tok[i+1:], ".")
+			tok = tok[:i]
+		}
+
+		parts := 0
+		nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+		for i, part := range strings.Split(tok, ".") {
+			if part == "x" || part == "X" || part == "*" {
+				break
+			}
+			n, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				ps.Pos -= len(node.Token)
+				ps.ErrorHere(name)
+				return
+			}
+			*nums[i] = n
+			parts++
+		}
+
+		if partial {
+			node.Result = VersionComparator{Op: "=", Version: v, Parts: parts}
+		} else {
+			node.Result = v
+		}
+	}
+}
+
+// VersionConstraint matches a version constraint expression in the style used by npm and
+// Cargo, eg `>=1.2.0, <2.0.0 || 3.x`, and binds it into .Result as VersionConstraints.
+//   - comparators are an optional operator (=, !=, >, >=, <, <=, ~ or ^) and a version
+//     that may leave off parts or use x, X or * wildcards
+//   - comparators separated by commas or whitespace must all hold
+//   - groups of comparators separated by || are alternatives
+func VersionConstraint() Parser {
+	op := Regex(`>=|<=|!=|>|<|=|~|\^`)
+	version := versionImpl("version", `v?`+semverPart+`(?:\.`+semverPart+`){0,2}`+semverTail, true)
+	comparator := Seq(Maybe(op), version).Map(func(n *Result) {
+		c := n.Child[1].Result.(VersionComparator)
+		if n.Child[0].Token != "" {
+			c.Op = n.Child[0].Token
+		}
+		n.Result = c
+	})
+	all := Some(comparator, Maybe(","))
+	anyOf := Some(all, "||")
+
+	return NewParser("version constraint", anyOf.Map(func(n *Result) {
+		var cs VersionConstraints
+		for _, group := range n.Child {
+			var all []VersionComparator
+			for _, c := range group.Child {
+				all = append(all, c.Result.(VersionComparator))
+			}
+			cs = append(cs, all)
+		}
+		n.Result = cs
+	}))
+}
@@ -0,0 +1,115 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemver(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		result, p := runParser("1.2.3", Semver())
+		require.Equal(t, Version{Major: 1, Minor: 2, Patch: 3}, result.Result)
+		require.Equal(t, "", p.Get())
+	})
+
+	t.Run("prerelease and build", func(t *testing.T) {
+		result, p := runParser("v1.0.0-rc.1+build.5 next", Semver())
+		v := result.Result.(Version)
+		require.Equal(t, Version{Major: 1, Prerelease: []string{"rc", "1"}, Build: []string{"build", "5"}}, v)
+		require.Equal(t, "1.0.0-rc.1+build.5", v.String())
+		require.Equal(t, " next", p.Get())
+	})
+
+	for _, input := range []string{"1.2", "01.2.3", "x.1.2", "1.2.3.", ""} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			result, p := runParser(input, Semver())
+			if !p.Errored() {
+				require.NotEqual(t, "", p.Get(), "matched %v", result.Result)
+				return
+			}
+			require.Equal(t, "offset 0: expected semver", p.Error.Error())
+			require.Equal(t, 0, p.Pos)
+		})
+	}
+
+	t.Run("overflow", func(t *testing.T) {
+		_, p := runParser("1.2.99999999999999999999", Semver())
+		require.Equal(t, "offset 0: expected semver", p.Error.Error())
+		require.Equal(t, 0, p.Pos)
+	})
+}
+
+func TestVersionCompare(t *testing.T) {
+	// From lowest to highest precedence, as listed in the semver spec.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	versions := make([]Version, len(ordered))
+	for i, s := range ordered {
+		v, _, err := Run(Semver(), s)
+		require.NoError(t, err)
+		versions[i] = v.(Version)
+	}
+	for i := range versions {
+		for j := range versions {
+			require.Equal(t, compareUint(uint64(i), uint64(j)), versions[i].Compare(versions[j]), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+
+	a, _, _ := Run(Semver(), "1.0.0+a")
+	b, _, _ := Run(Semver(), "1.0.0+b")
+	require.Equal(t, 0, a.(Version).Compare(b.(Version)))
+}
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{">=1.2.0, <2.0.0 || 3.x", []string{"1.2.0", "1.9.9", "3.0.0", "3.5.1"}, []string{"1.1.9", "2.0.0", "4.0.0"}},
+		{">= 1.2 < 1.4", []string{"1.2.0", "1.3.7"}, []string{"1.4.0", "1.1.0"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"2.0.0", "1.2.2"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"*", []string{"0.0.1", "99.0.0"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			result, _, err := Run(VersionConstraint(), test.constraint)
+			require.NoError(t, err)
+			cs := result.(VersionConstraints)
+			for _, s := range test.matches {
+				v, _, _ := Run(Semver(), s)
+				require.True(t, cs.Check(v.(Version)), s)
+			}
+			for _, s := range test.rejects {
+				v, _, _ := Run(Semver(), s)
+				require.False(t, cs.Check(v.(Version)), s)
+			}
+		})
+	}
+
+	t.Run("structure", func(t *testing.T) {
+		result, _, err := Run(VersionConstraint(), ">=1.2.0, <2 || 3.x")
+		require.NoError(t, err)
+		require.Equal(t, VersionConstraints{
+			{{Op: ">=", Version: Version{Major: 1, Minor: 2}, Parts: 3}, {Op: "<", Version: Version{Major: 2}, Parts: 1}},
+			{{Op: "=", Version: Version{Major: 3}, Parts: 1}},
+		}, result)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, _, err := Run(VersionConstraint(), ">=")
+		require.Equal(t, "offset 2: unexpected end of input, expected version", err.Error())
+	})
+}
@@ -1,7 +1,9 @@
 package goparsify
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -19,6 +21,54 @@ type State struct {
 	Error Error
 	// Called to determine what to ignore when WS is called, or when WS fires
 	WS VoidParser
+
+	// trace is set when this parse should be logged, see WithTrace.
+	trace *tracer
+	// traceDepth is how many Traces are running.
+	traceDepth int
+	// attempts is set when every parser run should be recorded, see WithAttempts.
+	attempts *attemptRecorder
+	// analysis is set while Analyze or ExportEBNF walk a grammar instead of parsing.
+	analysis grammarWalker
+	// leftRecursion holds the seeds of the LeftRecursive parsers being grown.
+	leftRecursion map[leftRecursionKey]*leftRecursionSeed
+	// user is the state kept for the grammar, see SetUserState.
+	user interface{}
+	// captures holds the text captured by Capture, the latest first.
+	captures *capture
+	// source is the name of the input, see WithSourceName, and includes the sources that
+	// included it, the outermost first.
+	source   string
+	includes []Source
+	// depth is how many references to parsers, eg &value, are being followed, see WithMaxDepth.
+	depth int
+	// branches holds the windows of the branches limited by WithBranchBudget, the innermost
+	// first.
+	branches *branchWindow
+	// columns holds the columns recorded by the ColumnScopes being parsed.
+	columns *columnScope
+	// lines indexes the lines of Input, see Position.
+	lines *LineIndex
+	// lexer is set by WithLexer to skip whitespace once per position.
+	lexer *lexerScan
+	// furthest is the furthest position of the errors recovered from, see IsIncomplete.
+	furthest int
+	// cutScopes holds the Scopes being parsed, for CutIn, and cutBy the one whose CutIn made
+	// the current Cut, or nil if it was made some other way.
+	cutScopes *cutScope
+	cutBy     *cutScope
+	// fatal is set by an error no parser may backtrack out of, see fatalError, until it is
+	// recovered from.
+	fatal bool
+	// tokens is set by RunTokens, which parses them in place of the input and makes Pos an
+	// index into them.
+	tokens []Token
+	// original is the source Input was made from before decoding and normalization, and
+	// offsets maps offsets back to it.
+	original string
+	offsets  offsetMaps
+	// config holds the options of the Run, see cfg.
+	config *runConfig
 }
 
 // ASCIIWhitespace matches any of the standard whitespace characters. It is faster
@@ -34,8 +84,10 @@ func ASCIIWhitespace(s *State) {
 	}
 }
 
-// UnicodeWhitespace matches any unicode space character. Its a little slower
-// than the ascii parser because it matches a rune at a time.
+// UnicodeWhitespace matches any unicode space character, as unicode.IsSpace has them, including
+// the no-break, ideographic and other spaces that text pasted from word processors is full of.
+// It is the default, see WithWhitespace. Its a little slower than the ascii parser because it
+// matches a rune at a time.
 func UnicodeWhitespace(s *State) {
 	for s.Pos < len(s.Input) {
 		r, w := utf8.DecodeRuneInString(s.Get())
@@ -46,6 +98,34 @@ func UnicodeWhitespace(s *State) {
 	}
 }
 
+// HorizontalWhitespace matches spaces and tabs, for grammars where newlines matter.
+func HorizontalWhitespace(s *State) {
+	for s.Pos < len(s.Input) && (s.Input[s.Pos] == ' ' || s.Input[s.Pos] == '\t') {
+		s.Pos++
+	}
+}
+
+// skipContinuations wraps ws to skip the line continuations given too, see
+// WithLineContinuation.
+func skipContinuations(ws VoidParser, continuations []string) VoidParser {
+	return func(s *State) {
+		for {
+			ws(s)
+			skipped := false
+			for _, c := range continuations {
+				if strings.HasPrefix(s.Get(), c) {
+					s.Pos += len(c)
+					skipped = true
+					break
+				}
+			}
+			if !skipped {
+				return
+			}
+		}
+	}
+}
+
 // NoWhitespace disables automatic whitespace matching
 func NoWhitespace(_ *State) {
 }
@@ -58,6 +138,15 @@ func NewState(input string) *State {
 	}
 }
 
+// cfg returns the options the State is parsed with, those of a Run without any if it wasn't
+// started by one.
+func (s *State) cfg() *runConfig {
+	if s.config == nil {
+		return defaultRunConfig
+	}
+	return s.config
+}
+
 // Advance the Pos along by i bytes
 func (s *State) Advance(i int) {
 	s.Pos += i
@@ -90,15 +179,129 @@ func (s *State) Preview(x int) string {
 func (s *State) ErrorHere(expected string) {
 	s.Error.pos = s.Pos
 	s.Error.expected = expected
+	s.Error.message = ""
+	s.Error.rule = ""
+	s.Error.sources = nil
+}
+
+// errorInToken sets the error to the one at pos in the middle of a token starting at start.
+func (s *State) errorInToken(start, pos int, expected string) {
+	s.Error = Error{pos: pos, expected: expected, tokenStart: start, inToken: true}
+}
+
+// ErrorHeref is like ErrorHere with what was expected formatted like fmt.Sprintf, eg
+// ps.ErrorHeref("at most %d digits", max).
+func (s *State) ErrorHeref(format string, args ...interface{}) {
+	s.ErrorHere(fmt.Sprintf(format, args...))
+}
+
+// ErrorHereWithMessage is like ErrorHere but also says what is wrong in a message for people to
+// read, which the error shows in place of what was expected:
+//
+//	ps.ErrorHereWithMessage("port number", fmt.Sprintf("expected port number (0-65535), got %d", port))
+//
+// Expected still returns expected, for the combinators and tools that compare errors by it.
+func (s *State) ErrorHereWithMessage(expected, message string) {
+	s.ErrorHere(expected)
+	s.Error.message = message
 }
 
 // Recover from the current error. Often called by combinators that can match
 // when one of their children succeed, but others have failed.
 func (s *State) Recover() {
+	if s.Error.expected != "" && s.Error.pos > s.furthest {
+		s.furthest = s.Error.pos
+	}
 	s.Error.expected = ""
+	s.Error.message = ""
+	s.Error.rule = ""
+	s.Error.sources = nil
+	s.fatal = false
 }
 
 // Errored returns true if the current parser has failed.
 func (s *State) Errored() bool {
 	return s.Error.expected != ""
 }
+
+// SetUserState replaces the state the grammar keeps during the parse, eg a symbol table of the
+// identifiers declared as types for a grammar that needs to tell them apart from others. A
+// failing Seq puts back the state it started with, so treat it as a value and replace it
+// rather than changing it in place, or changes in branches that were backtracked out of stay.
+// See also UpdateState and PushState.
+func (s *State) SetUserState(state interface{}) {
+	s.user = state
+}
+
+// GetUserState returns the state set by SetUserState, or WithUserState before the parse.
+func (s *State) GetUserState() interface{} {
+	return s.user
+}
+
+// Mark is a saved position of a State to go back to with Restore, see State.Mark.
+type Mark struct {
+	pos      int
+	user     interface{}
+	captures *capture
+}
+
+// Pos is the offset into the input the mark was made at.
+func (m Mark) Pos() int { return m.pos }
+
+// Mark saves the position along with the user state and captures, for a combinator to go
+// back to with Restore if what it tries doesn't match. Together with Fail, Committed and
+// Recover it is all a combinator needs to backtrack the same way the ones in this package do:
+//
+//	m := ps.Mark()
+//	p(ps, node)
+//	if ps.Errored() {
+//		ps.Restore(m)
+//		if ps.Committed(m) {
+//			return // a Cut inside p, the error stands
+//		}
+//		ps.Recover()
+//		// try something else
+//	}
+func (s *State) Mark() Mark {
+	return Mark{pos: s.Pos, user: s.user, captures: s.captures}
+}
+
+// Restore goes back to the position m was made at, putting back the user state and captures
+// from then. It leaves the error and the cut as they are.
+func (s *State) Restore(m Mark) {
+	s.Pos, s.user, s.captures = m.pos, m.user, m.captures
+}
+
+// Fail raises an error at the current position saying what was expected there, like
+// ErrorHere.
+func (s *State) Fail(expected string) {
+	s.ErrorHere(expected)
+}
+
+// Commit prevents backtracking past the current position, like Cut.
+func (s *State) Commit() {
+	s.Cut, s.cutBy = s.Pos, nil
+	if s.branches != nil {
+		s.liftBranches()
+	}
+}
+
+// Committed returns whether a Commit or Cut since m was made forbids backtracking to it, in
+// which case an error should be passed on rather than recovered from. An error from one of the
+// limits, eg WithMaxDepth, forbids backtracking to anywhere.
+func (s *State) Committed(m Mark) bool {
+	return s.cutSince(m.pos)
+}
+
+// cutSince returns whether the parse can't backtrack to pos, as a Commit or Cut went past it
+// or the error is fatal.
+func (s *State) cutSince(pos int) bool {
+	return s.Cut > pos || s.fatal
+}
+
+// fatalError fails the parse at pos with an error no parser backtracks out of to take the
+// input some other way.
+func (s *State) fatalError(pos int, expected string) {
+	s.Error = Error{pos: pos, expected: expected}
+	s.fatal = true
+}
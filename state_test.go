@@ -42,6 +42,18 @@ func TestState_Errors(t *testing.T) {
 	require.Equal(t, "offset 2: expected hello2", ps.Error.Error())
 	require.Equal(t, 2, ps.Error.Pos())
 	require.True(t, ps.Errored())
+
+	ps.ErrorHeref("at most %d digits", 5)
+	require.Equal(t, "offset 2: expected at most 5 digits", ps.Error.Error())
+
+	ps.ErrorHereWithMessage("port number", "expected port number (0-65535), got 99999")
+	require.Equal(t, "offset 2: expected port number (0-65535), got 99999", ps.Error.Error())
+	require.Equal(t, "port number", ps.Error.Expected())
+	require.Equal(t, "expected port number (0-65535), got 99999", ps.Error.Message())
+
+	ps.Recover()
+	ps.ErrorHere("hello")
+	require.Equal(t, "", ps.Error.Message())
 }
 
 func TestState_Preview(t *testing.T) {
@@ -53,9 +65,58 @@ func TestState_Preview(t *testing.T) {
 func TestWhitespaces(t *testing.T) {
 	p := Many(Any("hello", "world", "!"))
 
-	_, _, err := Run(p, "hello world\u2005!", ASCIIWhitespace)
+	_, _, err := Run(p, "hello world\u2005!", WithWhitespace(ASCIIWhitespace))
 	require.Equal(t, "left unparsed: \u2005!", err.Error())
 
-	_, _, err = Run(p, "hello world\u2005!", UnicodeWhitespace)
+	_, _, err = Run(p, "hello world\u2005!", WithWhitespace(UnicodeWhitespace))
+	require.NoError(t, err)
+
+	// Text pasted from word processors is full of no-break and ideographic spaces, which the
+	// default skips.
+	_, _, err = Run(p, "hello\u00a0world\u3000!\u0085")
+	require.NoError(t, err)
+
+	_, _, err = Run(p, "hello\u00a0world", WithWhitespace(ASCIIWhitespace))
+	require.Equal(t, "left unparsed: \u00a0world", err.Error())
+}
+
+func TestState_Mark(t *testing.T) {
+	ps := NewState("foo bar")
+	ps.SetUserState(1)
+	m := ps.Mark()
+
+	ps.Advance(4)
+	ps.SetUserState(2)
+	ps.Fail("baz")
+	require.Equal(t, "offset 4: expected baz", ps.Error.Error())
+	require.False(t, ps.Committed(m))
+
+	ps.Restore(m)
+	require.Equal(t, 0, ps.Pos)
+	require.Equal(t, 0, m.Pos())
+	require.Equal(t, 1, ps.GetUserState())
+	require.True(t, ps.Errored())
+
+	ps.Recover()
+	ps.Advance(1)
+	ps.Commit()
+	require.True(t, ps.Committed(m))
+	require.False(t, ps.Committed(ps.Mark()))
+}
+
+func TestWithLineContinuation(t *testing.T) {
+	word := Chars("a-z")
+	command := Seq(Some(word), "\n")
+
+	_, _, err := Run(command, "echo a \\\n  b\n", WithWhitespace(HorizontalWhitespace))
+	require.EqualError(t, err, "offset 7: expected \n")
+
+	tree, err := RunTree(Seq(command, command), "echo a \\\n  b\nls\\\r\n la\n", WithWhitespace(HorizontalWhitespace), WithLineContinuation())
+	require.NoError(t, err)
+	assertSequence(t, tree.Child[0].Child[0], "echo", "a", "b")
+	assertSequence(t, tree.Child[1].Child[0], "ls", "la")
+
+	tree, err = RunTree(Some(word), "a &&\nb & c", WithLineContinuation("&&\n", "&"))
 	require.NoError(t, err)
+	assertSequence(t, tree, "a", "b", "c")
 }
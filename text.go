@@ -0,0 +1,52 @@
+package goparsify
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// UnmarshalText parses text with parser into v, failing if the result isn't a T. It's for
+// writing the UnmarshalText method of a type parsed with this package, so the type can be used
+// with flag.TextVar, json.Unmarshal, database/sql scanners and anything else that knows about
+// encoding.TextUnmarshaler:
+//
+//	func (v *Version) UnmarshalText(text []byte) error {
+//		return goparsify.UnmarshalText(version, text, v)
+//	}
+func UnmarshalText[T any](parser Parserish, text []byte, v *T) error {
+	result, err := RunAs[T](parser, string(text))
+	if err != nil {
+		return err
+	}
+	*v = result
+	return nil
+}
+
+// Unmarshaled matches token and decodes the text it matched with the UnmarshalText method of
+// T, so types that already know how to read themselves can be used as terminals, eg
+// Unmarshaled[time.Time](Chars("0-9TZ:.+-")). The T will be stored in .Result. If it can't
+// be decoded the error is at the start of the token and says why.
+func Unmarshaled[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](token Parserish) Parser {
+	parser := Parsify(token)
+	var zero T
+	name := fmt.Sprintf("%T", zero)
+
+	return NewParser(name, func(ps *State, node *Result) {
+		m := ps.Mark()
+		parser(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+
+		var value T
+		if err := PT(&value).UnmarshalText([]byte(node.Token)); err != nil {
+			ps.Restore(m)
+			ps.Error = Error{pos: node.Start, expected: fmt.Sprintf("%s (%v)", name, err)}
+			return
+		}
+		node.Result = value
+	})
+}
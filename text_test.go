@@ -0,0 +1,48 @@
+package goparsify
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type point struct{ x, y int64 }
+
+var pointParser = Seq(NumberLit(), ",", NumberLit()).Map(func(n *Result) {
+	n.Result = point{n.Child[0].Result.(int64), n.Child[2].Result.(int64)}
+})
+
+func (p *point) UnmarshalText(text []byte) error {
+	return UnmarshalText(pointParser, text, p)
+}
+
+func TestUnmarshalText(t *testing.T) {
+	var points []point
+	require.NoError(t, json.Unmarshal([]byte(`["1, 2", "3,4"]`), &points))
+	require.Equal(t, []point{{1, 2}, {3, 4}}, points)
+
+	var p point
+	require.EqualError(t, p.UnmarshalText([]byte("1,")), "offset 2: unexpected end of input, expected number")
+
+	var s string
+	require.EqualError(t, UnmarshalText(pointParser, []byte("1,2"), &s), "result is a goparsify.point, not a string")
+}
+
+func TestUnmarshaled(t *testing.T) {
+	timestamp := Unmarshaled[time.Time](Chars("0-9TZ:.+-"))
+
+	result, _, err := Run(Seq("at", timestamp), "at 2024-02-29T12:30:00Z")
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	node, ps := runParser("at 2024-02-29T12:30:00Z!", Seq("at", timestamp))
+	require.False(t, ps.Errored())
+	require.Equal(t, time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC), node.Child[1].Result)
+	require.Equal(t, "!", ps.Get())
+
+	_, ps = runParser("at 2023-02-29T12:30:00Z", Seq("at", timestamp))
+	require.Equal(t, `offset 3: expected time.Time (parsing time "2023-02-29T12:30:00Z": day out of range)`, ps.Error.Error())
+	require.Equal(t, 0, ps.Pos)
+}
@@ -0,0 +1,164 @@
+package goparsify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"text/scanner"
+	"unicode/utf8"
+)
+
+// Token is a token produced by a lexer, for parsing with RunTokens.
+type Token struct {
+	// Kind is the class of the token, eg Ident, Int or a punctuation character like (.
+	Kind string
+	// Text is the input the token was made from.
+	Text string
+	// Offset is the byte offset of the token in the source.
+	Offset int
+}
+
+// TokenSource is a lexer RunTokens takes its tokens from, eg one a codebase already has. Next
+// returns false when there are no more tokens.
+type TokenSource interface {
+	Next() (Token, bool)
+}
+
+// ScannerTokens reads the tokens of s, which must have been initialized with Init. The Kind of
+// identifiers, numbers, strings and comments is what scanner.TokenString calls them, eg Ident or
+// Float, and of any other token the character itself, eg (.
+func ScannerTokens(s *scanner.Scanner) TokenSource {
+	return scannerTokens{s}
+}
+
+type scannerTokens struct {
+	s *scanner.Scanner
+}
+
+func (t scannerTokens) Next() (Token, bool) {
+	tok := t.s.Scan()
+	if tok == scanner.EOF {
+		return Token{}, false
+	}
+	kind := string(tok)
+	if tok < 0 {
+		kind = scanner.TokenString(tok)
+	}
+	return Token{Kind: kind, Text: t.s.TokenText(), Offset: t.s.Position.Offset}, true
+}
+
+// RunTokens is like Run but parses the tokens given by a lexer instead of the source text, eg to
+// move a parser with a lexer of its own over to this package one rule at a time. The combinators
+// work on tokens as they do on text, but the terminals have to be TokenKind and TokenText rather
+// than strings and the likes of Chars. Errors and the spans and tokens of the Results are at
+// offsets into the source the tokens were read from. The tokens are at offsets into the source
+// as the lexer read it, so the options that change it before it is parsed, like WithEncoding or
+// WithNormalizedNewlines, are left to the lexer and make RunTokens fail.
+func RunTokens(parser Parserish, source string, tokens TokenSource, opts ...Option) (result interface{}, err error) {
+	cfg := newRunConfig(opts)
+	cfg.tokens = []Token{}
+	for {
+		tok, ok := tokens.Next()
+		if !ok {
+			break
+		}
+		cfg.tokens = append(cfg.tokens, tok)
+	}
+
+	ret, _, err := run(parser, source, cfg)
+	return ret.Result, err
+}
+
+// errChangesTokenSource is returned by RunTokens for options that would change the source
+// under the offsets of its tokens.
+var errChangesTokenSource = errors.New("RunTokens can't decode or normalize the source, its lexer has to")
+
+// changesInput returns whether cfg changes the input before it is parsed, which RunTokens
+// can't do.
+func (cfg *runConfig) changesInput() bool {
+	return cfg.decoder != nil || cfg.withoutBOM || cfg.newlines || cfg.normalize != nil || cfg.invalidUTF8 == ReplaceInvalidUTF8
+}
+
+// TokenKind matches a token of the given kind when parsing with RunTokens. The text of the token
+// will be stored in .Token.
+func TokenKind(kind string) Parser {
+	return tokenParser(kind, kind+" token", func(tok Token) bool { return tok.Kind == kind })
+}
+
+// TokenText matches a token with the given text, of any kind, when parsing with RunTokens. The
+// text will be stored in .Token.
+func TokenText(text string) Parser {
+	return tokenParser(text, fmt.Sprintf("token %q", text), func(tok Token) bool { return tok.Text == text })
+}
+
+func tokenParser(expected, description string, match func(Token) bool) Parser {
+	return NewParser(expected, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			ps.analysis.terminal(description)
+		}
+		if ps.Pos >= len(ps.tokens) || !match(ps.tokens[ps.Pos]) {
+			ps.ErrorHere(expected)
+			return
+		}
+		tok := ps.tokens[ps.Pos]
+		node.Token = tok.Text
+		node.Start, node.End = tok.Offset, tok.Offset+len(tok.Text)
+		ps.Advance(1)
+	})
+}
+
+// tokenOffset is the offset in the source of the token at i, or the end of the source after
+// the last token.
+func (ps *State) tokenOffset(i int) int {
+	if i < len(ps.tokens) {
+		return ps.tokens[i].Offset
+	}
+	return len(ps.Input)
+}
+
+// length is where the input ends, in tokens with RunTokens.
+func (ps *State) length() int {
+	if ps.tokens != nil {
+		return len(ps.tokens)
+	}
+	return len(ps.Input)
+}
+
+// offset is the offset in the input of pos, which is the index of a token with RunTokens.
+func (ps *State) offset(pos int) int {
+	if ps.tokens != nil {
+		return ps.tokenOffset(pos)
+	}
+	return pos
+}
+
+// pos is the Pos of offset into the input, which under RunTokens is the index of the first
+// token starting there or after it.
+func (ps *State) pos(offset int) int {
+	if ps.tokens == nil {
+		return offset
+	}
+	return sort.Search(len(ps.tokens), func(i int) bool { return ps.tokens[i].Offset >= offset })
+}
+
+// nextWidth is how far the next rune, or token with RunTokens, goes past Pos.
+func (ps *State) nextWidth() int {
+	if ps.tokens != nil {
+		return 1
+	}
+	_, w := utf8.DecodeRuneInString(ps.Get())
+	return w
+}
+
+// text is the input from from to to, which are indices of tokens with RunTokens, in which case
+// it runs from the start of the first token to the end of the last.
+func (ps *State) text(from, to int) string {
+	if ps.tokens == nil {
+		return ps.Input[from:to]
+	}
+	if from >= to {
+		return ""
+	}
+	last := ps.tokens[to-1]
+	return ps.Input[ps.tokens[from].Offset : last.Offset+len(last.Text)]
+}
@@ -0,0 +1,119 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+	"text/scanner"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scan(source string) TokenSource {
+	var s scanner.Scanner
+	s.Init(strings.NewReader(source))
+	return ScannerTokens(&s)
+}
+
+func TestRunTokens(t *testing.T) {
+	var expr Parser
+	call := Seq(TokenKind("Ident"), TokenText("("), Cut(), Some(&expr, TokenText(",")), TokenText(")")).Map(func(n *Result) {
+		var args []interface{}
+		for _, arg := range n.Child[3].Child {
+			args = append(args, arg.Result)
+		}
+		n.Result = map[string]interface{}{n.Child[0].Token: args}
+	})
+	number := TokenKind("Int").Map(func(n *Result) { n.Result = n.Token })
+	expr = Any(call, number)
+
+	t.Run("parses the tokens", func(t *testing.T) {
+		result, err := RunTokens(expr, "max(1, min(2, 3))", scan("max(1, min(2, 3))"))
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"max": []interface{}{"1", map[string]interface{}{"min": []interface{}{"2", "3"}}}}, result)
+	})
+
+	t.Run("reports errors at offsets into the source", func(t *testing.T) {
+		_, err := RunTokens(expr, "max(1, 2 3)", scan("max(1, 2 3)"))
+		require.EqualError(t, err, "offset 9: expected )")
+
+		_, err = RunTokens(expr, "max(1,", scan("max(1,"))
+		require.EqualError(t, err, "offset 6: unexpected end of input, expected )")
+
+		_, err = RunTokens(expr, "1 2", scan("1 2"))
+		require.EqualError(t, err, "left unparsed: 2")
+	})
+
+	t.Run("takes the tokens and spans of sequences from the source", func(t *testing.T) {
+		var seq Result
+		assignment := Seq(TokenKind("Ident"), TokenText("="), TokenKind("Int")).Map(func(n *Result) { seq = *n })
+		_, err := RunTokens(assignment, "  beta = 7 ", scan("  beta = 7 "))
+		require.NoError(t, err)
+		require.Equal(t, "beta = 7", seq.Token)
+		require.Equal(t, 2, seq.Start)
+		require.Equal(t, 10, seq.End)
+
+		_, err = RunTokens(Seq(TokenKind("Ident"), Any(TokenText("="), TokenText(":"))), "beta     ", scan("beta     "))
+		require.EqualError(t, err, "offset 9: unexpected end of input")
+	})
+
+	t.Run("recovers and finds in tokens", func(t *testing.T) {
+		var tree Result
+		keep := func(n *Result) { tree = *n }
+		statement := Seq(TokenKind("Ident"), TokenText("="), TokenKind("Int"))
+		statements := Many(Seq(Recovering(statement, TokenText(";")), TokenText(";"))).Map(keep)
+		source := "a = 1; b = ; c = 3;"
+		_, err := RunTokens(statements, source, scan(source))
+		require.NoError(t, err)
+		require.Len(t, tree.Child, 3)
+		bad := tree.Child[1].Child[0]
+		require.Equal(t, "b =", bad.Token)
+		require.Equal(t, 7, bad.Start)
+		require.Equal(t, 11, bad.End)
+		errs := RecoveredErrors(&tree)
+		require.Len(t, errs, 1)
+		require.Equal(t, "offset 11: expected Int", errs[0].Error())
+
+		_, err = RunTokens(Recovering(statement, TokenText(";")), "", scan(""))
+		require.EqualError(t, err, "offset 0: unexpected end of input, expected Ident")
+
+		_, err = RunTokens(Find(TokenText("x")).Map(keep), "a b  x", scan("a b  x"))
+		require.NoError(t, err)
+		require.Equal(t, "a b", tree.Child[0].Token)
+		require.Equal(t, 5, tree.Child[1].Start)
+		require.Equal(t, 6, tree.End)
+
+		_, err = RunTokens(FindAll(TokenText("x")).Map(keep), "x a x b", scan("x a x b"))
+		require.NoError(t, err)
+		require.Len(t, tree.Child, 2)
+		require.Equal(t, 4, tree.Child[1].Start)
+		require.Equal(t, 7, tree.End)
+	})
+
+	t.Run("limits and traces at offsets into the source", func(t *testing.T) {
+		_, err := RunTokens(Some(TokenKind("Int")), "1 22 333", scan("1 22 333"), WithMaxChildren(2))
+		require.EqualError(t, err, "offset 5: expected at most 2 items")
+
+		var out strings.Builder
+		_, err = RunTokens(Trace("list", Some(TokenKind("Int"))), " 1 22 x", scan(" 1 22 x"), WithTraceOutput(&out), AllowTrailingInput())
+		require.NoError(t, err)
+		require.Equal(t, "list at offset 1: \"1 22 x\"\nlist matched \"1 22\"\n", out.String())
+	})
+
+	t.Run("checks the options like Run", func(t *testing.T) {
+		_, err := RunTokens(expr, "max(1, 2)", scan("max(1, 2)"), WithMaxInputSize(4))
+		require.EqualError(t, err, "offset 4: expected input of at most 4 bytes")
+
+		_, err = RunTokens(expr, "max(1, 2)", scan("max(1, 2)"), WithNormalizedNewlines())
+		require.EqualError(t, err, "RunTokens can't decode or normalize the source, its lexer has to")
+	})
+
+	t.Run("sets spans from the source", func(t *testing.T) {
+		ps := NewState("  a 12")
+		ps.tokens = []Token{{Kind: "Ident", Text: "a", Offset: 2}, {Kind: "Int", Text: "12", Offset: 4}}
+		var result Result
+		Seq(TokenKind("Ident"), TokenKind("Int"))(ps, &result)
+		require.False(t, ps.Errored())
+		require.Equal(t, 2, result.Child[0].Start)
+		require.Equal(t, 6, result.Child[1].End)
+	})
+}
@@ -0,0 +1,120 @@
+package goparsify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tracer writes the indented enter/exit log requested by WithTrace or EnableLogging. It lives
+// on the State so every parse gets its own. Parsers only report to it when built with -tags debug.
+type tracer struct {
+	w io.Writer
+	// vars holds the variable names of the parsers currently running, innermost last.
+	vars []string
+	// pending is the opening line of the innermost parser. It is held back so that parsers
+	// without children print a single line instead of a pair of braces.
+	pending string
+}
+
+func newTracer(w io.Writer) *tracer {
+	return &tracer{w: w}
+}
+
+// name is how a parser is shown in the trace. Parsers created on the same line as their
+// parent, like the parts of a Seq, are shown by what they match instead of repeating the
+// variable name. Parsers whose variable couldn't be found are shown the same way.
+func (t *tracer) name(varName, match string) string {
+	if varName == "" || len(t.vars) > 0 && t.vars[len(t.vars)-1] == varName {
+		return match
+	}
+	return varName
+}
+
+func (t *tracer) enter(ps *State, location, name, varName string) {
+	if t.pending != "" {
+		fmt.Fprint(t.w, t.pending)
+	}
+	t.pending = t.line(ps, location, name+" {")
+	t.vars = append(t.vars, varName)
+}
+
+func (t *tracer) exit(ps *State, location, name string, startPos int, result *Result, took time.Duration) {
+	t.vars = t.vars[:len(t.vars)-1]
+	outcome := t.outcome(ps, startPos, result, took)
+	if t.pending != "" {
+		fmt.Fprint(t.w, t.line(ps, location, name+outcome))
+		t.pending = ""
+	} else {
+		fmt.Fprint(t.w, t.line(ps, location, "} "+name+outcome))
+	}
+}
+
+func (t *tracer) line(ps *State, location, text string) string {
+	return fmt.Sprintf("%s | %-15s | %s%s\n", location, ps.Preview(15), strings.Repeat("  ", len(t.vars)), text)
+}
+
+func (t *tracer) outcome(ps *State, startPos int, result *Result, took time.Duration) string {
+	if ps.Errored() {
+		return fmt.Sprintf(" did not find %s in %s", ps.Error.expected, took)
+	}
+	consumed := ""
+	if ps.Pos > startPos {
+		consumed = ps.text(startPos, ps.Pos)
+	}
+	return fmt.Sprintf(" found %s consuming %s in %s", truncatedQuote(result.String(), 20), truncatedQuote(consumed, 20), took)
+}
+
+func truncatedQuote(s string, length int) string {
+	quoted := strconv.Quote(s)
+	if len(quoted) > length {
+		quoted = quoted[0:length-4] + `..."`
+	}
+	return quoted
+}
+
+// Trace logs each time p is entered and exited, with the text it matched or what it expected
+// instead, to narrow a trace down to the rule being debugged. Unlike WithTrace it works
+// without -tags debug. It writes to the writer given with WithTraceOutput, or to
+// os.Stderr. Traced parsers inside each other are indented:
+//
+//	expr at offset 0: "1+x"
+//	  term at offset 0: "1+x"
+//	  term matched "1"
+//	  term at offset 2: "x"
+//	  term did not find number at offset 2
+//	expr matched "1"
+func Trace(name string, p Parserish) Parser {
+	parser := Parsify(p)
+
+	return func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			parser(ps, node)
+			return
+		}
+		w := ps.cfg().traceOut
+		if w == nil {
+			w = os.Stderr
+		}
+		indent := strings.Repeat("  ", ps.traceDepth)
+		startPos := ps.Pos
+		fmt.Fprintf(w, "%s%s at offset %d: %s\n", indent, name, ps.offset(startPos), truncatedQuote(ps.Input[ps.offset(startPos):], 30))
+
+		ps.traceDepth++
+		parser(ps, node)
+		ps.traceDepth--
+
+		if ps.Errored() {
+			fmt.Fprintf(w, "%s%s did not find %s at offset %d\n", indent, name, ps.Error.expected, ps.offset(ps.Error.pos))
+			return
+		}
+		consumed := ""
+		if ps.Pos > startPos {
+			consumed = ps.text(startPos, ps.Pos)
+		}
+		fmt.Fprintf(w, "%s%s matched %s\n", indent, name, truncatedQuote(consumed, 30))
+	}
+}
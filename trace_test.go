@@ -0,0 +1,67 @@
+package goparsify
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tr := newTracer(buf)
+	ps := NewState("hello world")
+
+	name := tr.name("greeting", "Seq()")
+	tr.enter(ps, "a.go:1", name, "greeting")
+	require.Equal(t, "Seq()", tr.name("greeting", "Seq()"), "children on the same line are named by what they match")
+
+	tr.enter(ps, "a.go:1", "hello", "greeting")
+	ps.Advance(5)
+	tr.exit(ps, "a.go:1", "hello", 0, &Result{Token: "hello"}, time.Microsecond)
+
+	tr.enter(ps, "a.go:2", "name", "name")
+	ps.ErrorHere("bob")
+	tr.exit(ps, "a.go:2", "name", 5, &Result{}, 2*time.Microsecond)
+	tr.exit(ps, "a.go:1", name, 0, &Result{}, 5*time.Microsecond)
+
+	require.Equal(t, ""+
+		"a.go:1 | hello world     | greeting {\n"+
+		"a.go:1 |  world          |   hello found \"hello\" consuming \"hello\" in 1µs\n"+
+		"a.go:2 |  world          |   name did not find bob in 2µs\n"+
+		"a.go:1 |  world          | } greeting did not find bob in 5µs\n",
+		buf.String())
+}
+
+func TestTruncatedQuote(t *testing.T) {
+	require.Equal(t, `"short"`, truncatedQuote("short", 20))
+	require.Equal(t, `"a really lon..."`, truncatedQuote("a really long string", 17))
+}
+
+func TestWithTraceIsPerRun(t *testing.T) {
+	buf := &bytes.Buffer{}
+	_, _, err := Run(Seq("hello", "world"), "hello world", WithTrace(buf))
+	require.NoError(t, err)
+
+	// Other parses are unaffected.
+	ps := NewState("hello")
+	require.Nil(t, ps.trace)
+}
+
+func TestTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	term := Trace("term", NumberLit())
+	expr := Trace("expr", Seq(term, Maybe(Seq("+", term))))
+
+	_, _, err := Run(expr, "1+x", WithTraceOutput(buf))
+	require.Error(t, err)
+	require.Equal(t, ""+
+		"expr at offset 0: \"1+x\"\n"+
+		"  term at offset 0: \"1+x\"\n"+
+		"  term matched \"1\"\n"+
+		"  term at offset 2: \"x\"\n"+
+		"  term did not find number at offset 2\n"+
+		"expr matched \"1\"\n",
+		buf.String())
+}
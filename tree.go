@@ -0,0 +1,72 @@
+package goparsify
+
+// TreeIndex records the parent of every result in a tree, so tools like linters can navigate
+// up and sideways as well as down. Results are identified by their address, so the index must
+// be rebuilt when the tree is changed, eg by Rewrite.
+type TreeIndex struct {
+	root    *Result
+	parents map[*Result]treeLink
+}
+
+type treeLink struct {
+	parent *Result
+	index  int
+}
+
+// NewTreeIndex indexes the tree under root.
+func NewTreeIndex(root *Result) *TreeIndex {
+	t := &TreeIndex{root: root, parents: map[*Result]treeLink{}}
+	Walk(root, func(n *Result, depth int) bool {
+		for i := range n.Child {
+			t.parents[&n.Child[i]] = treeLink{parent: n, index: i}
+		}
+		return true
+	})
+	return t
+}
+
+// Parent returns the result n is a child of, or nil for the root and results not in the tree.
+func (t *TreeIndex) Parent(n *Result) *Result {
+	return t.parents[n].parent
+}
+
+// NextSibling returns the child after n in its parent, or nil if it is the last.
+func (t *TreeIndex) NextSibling(n *Result) *Result {
+	return t.sibling(n, 1)
+}
+
+// PrevSibling returns the child before n in its parent, or nil if it is the first.
+func (t *TreeIndex) PrevSibling(n *Result) *Result {
+	return t.sibling(n, -1)
+}
+
+func (t *TreeIndex) sibling(n *Result, offset int) *Result {
+	link, ok := t.parents[n]
+	if !ok {
+		return nil
+	}
+	i := link.index + offset
+	if i < 0 || i >= len(link.parent.Child) {
+		return nil
+	}
+	return &link.parent.Child[i]
+}
+
+// Ancestors returns the parents of n from the closest up to the root.
+func (t *TreeIndex) Ancestors(n *Result) []*Result {
+	var ancestors []*Result
+	for parent := t.Parent(n); parent != nil; parent = t.Parent(parent) {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// Enclosing returns the closest ancestor of n with the given name, or nil if there isn't one.
+func (t *TreeIndex) Enclosing(n *Result, name string) *Result {
+	for parent := t.Parent(n); parent != nil; parent = t.Parent(parent) {
+		if parent.Name == name {
+			return parent
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreeIndex(t *testing.T) {
+	root := &Result{Name: "root", Child: []Result{
+		{Name: "a", Child: []Result{{Token: "a1"}, {Token: "a2"}}},
+		{Name: "b"},
+	}}
+	a, b := &root.Child[0], &root.Child[1]
+	a1, a2 := &a.Child[0], &a.Child[1]
+
+	index := NewTreeIndex(root)
+
+	require.Nil(t, index.Parent(root))
+	require.Equal(t, root, index.Parent(a))
+	require.Equal(t, a, index.Parent(a2))
+	require.Nil(t, index.Parent(&Result{}))
+
+	require.Equal(t, b, index.NextSibling(a))
+	require.Nil(t, index.NextSibling(b))
+	require.Equal(t, a1, index.PrevSibling(a2))
+	require.Nil(t, index.PrevSibling(a1))
+	require.Nil(t, index.NextSibling(root))
+
+	require.Equal(t, []*Result{a, root}, index.Ancestors(a1))
+	require.Empty(t, index.Ancestors(root))
+
+	require.Equal(t, root, index.Enclosing(a1, "root"))
+	require.Nil(t, index.Enclosing(a1, "b"))
+}
@@ -0,0 +1,96 @@
+package goparsify
+
+import "strings"
+
+// Trivia is the input around a result that isn't part of any of its children, kept by RunTree
+// with WithTrivia so Reconstruct can rebuild the input exactly.
+type Trivia struct {
+	// Leading is the input between the previous sibling, or the start of the parent, and the
+	// result, eg the whitespace skipped before a token or a separator of Many.
+	Leading string
+	// Trailing is the input after the last child up to the end of the result. For the root it
+	// runs to the end of the input.
+	Trailing string
+	// Source is the input matched by a result that can't be rebuilt from its Token or children,
+	// eg a StringLit, whose Token has its quotes and escapes removed. HasSource is set along
+	// with it and Reconstruct writes it instead.
+	Source    string
+	HasSource bool
+}
+
+// Reconstruct writes out the tree under r, turning it back into the input it was parsed from
+// if it came from RunTree with WithTrivia. Results changed since are written as their Token, or
+// their children if they have any, so trees can be edited and written out again keeping the
+// whitespace and separators around the edits, eg by formatters and refactoring tools. Clear
+// HasSource of the results whose Token should be used in place of the input they matched.
+func Reconstruct(r *Result) string {
+	var b strings.Builder
+	reconstruct(&b, r)
+	return b.String()
+}
+
+func reconstruct(b *strings.Builder, r *Result) {
+	if r.Trivia != nil {
+		b.WriteString(r.Trivia.Leading)
+	}
+	switch {
+	case r.Trivia != nil && r.Trivia.HasSource:
+		b.WriteString(r.Trivia.Source)
+	case len(r.Child) == 0:
+		b.WriteString(r.Token)
+	default:
+		for i := range r.Child {
+			reconstruct(b, &r.Child[i])
+		}
+	}
+	if r.Trivia != nil {
+		b.WriteString(r.Trivia.Trailing)
+	}
+}
+
+// addTrivia fills in the Trivia of the tree under root, which matched all of input.
+func addTrivia(root *Result, input string) {
+	if root.Start > root.End || root.End > len(input) {
+		// The parser didn't set a span, there's nothing to go on but the whole input.
+		root.Trivia = &Trivia{Source: input, HasSource: true}
+		return
+	}
+	root.Trivia = &Trivia{Leading: input[:root.Start]}
+	addChildTrivia(root, input)
+	root.Trivia.Trailing += input[root.End:]
+}
+
+// addChildTrivia sets the Trivia of the children of n, whose own span is known to be valid,
+// and its own Source or Trailing.
+func addChildTrivia(n *Result, input string) {
+	source := input[n.Start:n.End]
+	if len(n.Child) == 0 {
+		if n.Token != source {
+			n.Trivia.Source, n.Trivia.HasSource = source, true
+		}
+		return
+	}
+
+	pos := n.Start
+	for i := range n.Child {
+		child := &n.Child[i]
+		if child.Start == child.End {
+			// Empty results like a Maybe that didn't match often have no position at all.
+			child.Trivia = &Trivia{}
+			if len(child.Child) > 0 || child.Token != "" {
+				child.Trivia.HasSource = true
+			}
+			continue
+		}
+		if child.Start < pos || child.End > n.End {
+			// Overlapping or out of order children, probably from a hand written parser.
+			// Fall back to writing the whole input matched.
+			n.Trivia.Source, n.Trivia.HasSource = source, true
+			return
+		}
+		child.Trivia = &Trivia{Leading: input[pos:child.Start]}
+		addChildTrivia(child, input)
+		pos = child.End
+	}
+	n.Trivia.Trailing = input[pos:n.End]
+}
@@ -0,0 +1,68 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconstruct(t *testing.T) {
+	var value Parser
+	list := Seq("[", Many(&value, ","), "]")
+	value = Any(Chars("0-9"), StringLit(`"`), list)
+
+	t.Run("round trips the input", func(t *testing.T) {
+		for _, input := range []string{
+			"1",
+			"  [1, 2 ,3]  ",
+			"[\n\t[], [ 4 ,\"a\\\"b\" ] ]",
+		} {
+			tree, err := RunTree(value, input, WithTrivia())
+			require.NoError(t, err)
+			require.Equal(t, input, Reconstruct(&tree))
+		}
+	})
+
+	t.Run("keeps the trivia around edits", func(t *testing.T) {
+		tree, err := RunTree(value, " [1, 2 ,3] ", WithTrivia())
+		require.NoError(t, err)
+
+		Walk(&tree, func(n *Result, depth int) bool {
+			if n.Token == "2" {
+				n.Token = "22"
+			}
+			return true
+		})
+		require.Equal(t, " [1, 22 ,3] ", Reconstruct(&tree))
+	})
+
+	t.Run("writes tokens without trivia", func(t *testing.T) {
+		tree, err := RunTree(value, " [1, 2] ")
+		require.NoError(t, err)
+		require.Nil(t, tree.Trivia)
+		require.Equal(t, "[12]", Reconstruct(&tree))
+	})
+
+	t.Run("falls back to the input for hand written parsers", func(t *testing.T) {
+		custom := func(ps *State, node *Result) {
+			ps.WS(ps)
+			node.Token = ps.Get()
+			ps.Pos = len(ps.Input)
+		}
+		tree, err := RunTree(Seq("a", custom), "a  bc", WithTrivia())
+		require.NoError(t, err)
+		require.Equal(t, "a  bc", Reconstruct(&tree))
+	})
+}
+
+func TestRunTree(t *testing.T) {
+	tree, err := RunTree(Seq("a", Named("b", "b")), "a b")
+	require.NoError(t, err)
+	require.Equal(t, "b", tree.Child[1].Name)
+
+	_, err = RunTree(Seq("a", "b"), "a c")
+	require.EqualError(t, err, "offset 2: expected b")
+
+	_, err = RunTree("a", "a c")
+	require.EqualError(t, err, "left unparsed: c")
+}
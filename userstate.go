@@ -0,0 +1,85 @@
+package goparsify
+
+// UpdateState replaces the user state with what update returns for it and the result of
+// parser when parser matches, eg to add the name a typedef declares to the set of type names.
+// See State.SetUserState.
+func UpdateState(parser Parserish, update func(state interface{}, n *Result) interface{}) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		p(ps, node)
+		if ps.Errored() || ps.analysis != nil {
+			return
+		}
+		ps.user = update(ps.user, node)
+	}
+}
+
+// PushState runs parser with the user state replaced by what push returns for it, then puts
+// the state from before back whether parser matched or not. It scopes the state to part of
+// the grammar, eg the names declared in a block to the block.
+func PushState(parser Parserish, push func(state interface{}) interface{}) Parser {
+	p := Parsify(parser)
+
+	return func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			p(ps, node)
+			return
+		}
+		user := ps.user
+		ps.user = push(user)
+		p(ps, node)
+		ps.user = user
+	}
+}
+
+// When runs p only if pred holds at the current position, and fails otherwise. pred can look
+// at the user state, eg to allow syntax only in the versions of a language that have it.
+func When(pred func(ps *State) bool, p Parserish) Parser {
+	return predicated("When()", pred, true, p)
+}
+
+// Unless is When the other way round, it runs p only if pred doesn't hold.
+func Unless(pred func(ps *State) bool, p Parserish) Parser {
+	return predicated("Unless()", pred, false, p)
+}
+
+func predicated(name string, pred func(ps *State) bool, want bool, p Parserish) Parser {
+	parser := Parsify(p)
+
+	return NewParser(name, func(ps *State, node *Result) {
+		if ps.analysis != nil {
+			parser(ps, node)
+			return
+		}
+		if pred(ps) != want {
+			// Point at what p would have matched, after the whitespace it would have skipped.
+			pos := ps.Pos
+			ps.WS(ps)
+			ps.ErrorHere(name + " condition")
+			ps.Pos = pos
+			return
+		}
+		parser(ps, node)
+	})
+}
+
+// Resolved matches p and then asks the resolver set with WithResolver whether its Token is a
+// known name of the given kind, failing where p matched with "expected known <kind>" if it
+// isn't. Without a resolver every name is accepted.
+func Resolved(kind string, p Parserish) Parser {
+	parser := Parsify(p)
+	expected := "known " + kind
+
+	return NewParser("Resolved("+kind+")", func(ps *State, node *Result) {
+		startpos := ps.Pos
+		parser(ps, node)
+		if ps.Errored() || ps.analysis != nil || ps.cfg().resolver == nil {
+			return
+		}
+		if !ps.cfg().resolver(kind, node.Token) {
+			ps.Error = Error{pos: node.Start, expected: expected}
+			ps.Pos = startpos
+		}
+	})
+}
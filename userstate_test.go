@@ -0,0 +1,119 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// typeNames is the user state of the typedef grammar below, copied on each change.
+type typeNames map[string]bool
+
+func (t typeNames) with(name string) typeNames {
+	names := typeNames{name: true}
+	for n := range t {
+		names[n] = true
+	}
+	return names
+}
+
+func TestUserState(t *testing.T) {
+	ident := Regex(`[a-z]+`)
+	typeName := func(ps *State, node *Result) {
+		ident(ps, node)
+		if ps.Errored() {
+			return
+		}
+		names, _ := ps.GetUserState().(typeNames)
+		if !names[node.Token] {
+			ps.Pos = node.Start
+			ps.ErrorHere("type name")
+		}
+	}
+	declare := func(state interface{}, n *Result) interface{} {
+		names, _ := state.(typeNames)
+		return names.with(n.Child[1].Token)
+	}
+
+	var stmt Parser
+	typedef := UpdateState(Seq("typedef", ident, ";"), declare)
+	decl := Seq(typeName, ident, ";")
+	expr := Seq(ident, "*", ident, ";")
+	block := PushState(Seq("{", Many(&stmt), "}"), func(state interface{}) interface{} {
+		return state
+	})
+	stmt = Any(typedef, decl, expr, block)
+	program := Many(&stmt)
+
+	t.Run("declared types", func(t *testing.T) {
+		node, ps := runParser("typedef foo; foo x; a * b;", program)
+		require.False(t, ps.Errored())
+		require.Equal(t, "", ps.Get())
+		require.Len(t, node.Child[1].Child, 3)
+		require.Len(t, node.Child[2].Child, 4)
+	})
+
+	t.Run("undeclared types", func(t *testing.T) {
+		_, ps := runParser("foo x;", program)
+		require.Equal(t, "foo x;", ps.Get())
+	})
+
+	t.Run("scoped to a block", func(t *testing.T) {
+		_, ps := runParser("{ typedef foo; foo x; } foo y;", program)
+		require.Equal(t, "foo y;", ps.Get())
+		require.Nil(t, ps.GetUserState())
+	})
+
+	t.Run("undone when backtracking", func(t *testing.T) {
+		declareIdent := UpdateState(ident, func(state interface{}, n *Result) interface{} {
+			names, _ := state.(typeNames)
+			return names.with(n.Token)
+		})
+		_, ps := runParser("foo foo", Seq(Any(Seq(declareIdent, "!"), ident), typeName))
+		require.Equal(t, "offset 4: expected type name", ps.Error.Error())
+	})
+
+	t.Run("initial state", func(t *testing.T) {
+		_, _, err := Run(decl, "size x;", WithUserState(typeNames{"size": true}))
+		require.NoError(t, err)
+	})
+}
+
+func TestWhen(t *testing.T) {
+	type features struct{ arrows bool }
+	arrows := func(ps *State) bool {
+		f, _ := ps.GetUserState().(features)
+		return f.arrows
+	}
+	fn := Any(When(arrows, Seq("x", "=>", "x")), Seq("function", "(", "x", ")"))
+
+	_, _, err := Run(fn, "x => x", WithUserState(features{arrows: true}))
+	require.NoError(t, err)
+	_, _, err = Run(fn, "x => x")
+	require.EqualError(t, err, "offset 0: expected function")
+
+	_, ps := runParser("x", When(arrows, "x"))
+	require.Equal(t, "offset 0: expected When() condition", ps.Error.Error())
+
+	node, ps := runParser("x", Unless(arrows, "x"))
+	require.False(t, ps.Errored())
+	require.Equal(t, "x", node.Token)
+}
+
+func TestResolved(t *testing.T) {
+	columns := map[string]bool{"id": true, "name": true}
+	resolver := WithResolver(func(kind, name string) bool {
+		return kind == "column" && columns[name]
+	})
+	column := Resolved("column", Regex(`[a-z]+`))
+	query := Seq("select", Some(column, ","))
+
+	_, _, err := Run(query, "select id, name", resolver)
+	require.NoError(t, err)
+
+	_, _, err = Run(Seq("select", column, ",", column), "select id, age", resolver)
+	require.EqualError(t, err, "offset 11: expected known column")
+
+	_, _, err = Run(query, "select id, age")
+	require.NoError(t, err)
+}
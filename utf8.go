@@ -0,0 +1,60 @@
+package goparsify
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// UTF8Policy says what to do with input that isn't valid UTF-8, see WithInvalidUTF8.
+type UTF8Policy int
+
+const (
+	// OpaqueUTF8 parses input as it is, the default. Parsers that look at runes, like Chars,
+	// NotChars and Regex, see each byte that isn't part of a valid encoding as a utf8.RuneError
+	// of its own, and parsers comparing strings, like Exact, compare bytes.
+	OpaqueUTF8 UTF8Policy = iota
+	// RejectInvalidUTF8 fails the parse at the first byte that isn't part of a valid encoding,
+	// before any parser is run.
+	RejectInvalidUTF8
+	// ReplaceInvalidUTF8 replaces each byte that isn't part of a valid encoding with U+FFFD before
+	// parsing. Offsets in errors and results are into the input after the replacement.
+	ReplaceInvalidUTF8
+)
+
+// WithInvalidUTF8 sets what to do with input that isn't valid UTF-8.
+func WithInvalidUTF8(policy UTF8Policy) Option {
+	return func(cfg *runConfig) {
+		cfg.invalidUTF8 = policy
+	}
+}
+
+// invalidUTF8 returns the offset of the first byte of s that isn't part of a valid encoding,
+// or -1 if there are none.
+func invalidUTF8(s string) int {
+	for i := 0; i < len(s); {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && w == 1 {
+			return i
+		}
+		i += w
+	}
+	return -1
+}
+
+// replaceInvalidUTF8 replaces each byte of s that isn't part of a valid encoding with U+FFFD.
+func replaceInvalidUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && w == 1 {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(s[i : i+w])
+		}
+		i += w
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package goparsify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithInvalidUTF8(t *testing.T) {
+	parser := Seq("name:", Chars(`\p{L}`))
+	input := "name: caf\xe9"
+
+	t.Run("opaque", func(t *testing.T) {
+		_, err := RunTree(parser, input)
+		require.EqualError(t, err, "left unparsed: \xe9")
+
+		// Each invalid byte is a utf8.RuneError to the parsers looking at runes.
+		tree, err := RunTree(Seq("name:", Chars(`\p{L}�`)), input)
+		require.NoError(t, err)
+		require.Equal(t, "caf\xe9", tree.Child[1].Token)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := RunTree(parser, input, WithInvalidUTF8(RejectInvalidUTF8))
+		require.EqualError(t, err, "offset 9: expected valid UTF-8")
+
+		_, err = RunTree(parser, "name: café", WithInvalidUTF8(RejectInvalidUTF8))
+		require.NoError(t, err)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		tree, err := RunTree(Seq("name:", Chars(`\p{L}�`)), input, WithInvalidUTF8(ReplaceInvalidUTF8))
+		require.NoError(t, err)
+		require.Equal(t, "caf�", tree.Child[1].Token)
+
+		_, err = RunTree(parser, "name: \xff\xfeab", WithInvalidUTF8(ReplaceInvalidUTF8))
+		require.EqualError(t, err, "offset 6: expected \\p{L}")
+	})
+}
@@ -0,0 +1,44 @@
+package goparsify
+
+// Walk calls fn for n and every result under it, parents before their children, with the depth
+// of each below n. Children are skipped when fn returns false. The results are passed by
+// pointer so fn can change them in place.
+func Walk(n *Result, fn func(n *Result, depth int) bool) {
+	walk(n, 0, fn)
+}
+
+func walk(n *Result, depth int, fn func(n *Result, depth int) bool) {
+	if !fn(n, depth) {
+		return
+	}
+	for i := range n.Child {
+		walk(&n.Child[i], depth+1, fn)
+	}
+}
+
+// Visitor is called by Visit on the way into and out of every result of a tree.
+type Visitor interface {
+	// Enter is called before the children of n are visited, with the results from the root of
+	// the tree down to the parent of n. The children are skipped when it returns false.
+	Enter(n *Result, parents []*Result) bool
+	// Exit is called once the children of n have been visited, or skipped.
+	Exit(n *Result, parents []*Result)
+}
+
+// Visit walks the tree under n depth first, calling v on the way into and out of each result.
+// The parents slice is reused between calls, so copy it to keep it.
+func Visit(n *Result, v Visitor) {
+	visit(n, nil, v)
+}
+
+func visit(n *Result, parents []*Result, v Visitor) []*Result {
+	if v.Enter(n, parents) {
+		parents = append(parents, n)
+		for i := range n.Child {
+			parents = visit(&n.Child[i], parents, v)
+		}
+		parents = parents[:len(parents)-1]
+	}
+	v.Exit(n, parents)
+	return parents
+}
@@ -0,0 +1,79 @@
+package goparsify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalk(t *testing.T) {
+	tree := &Result{Token: "root", Child: []Result{
+		{Token: "a", Child: []Result{{Token: "a1"}, {Token: "a2"}}},
+		{Token: "b", Child: []Result{{Token: "b1"}}},
+	}}
+
+	t.Run("visits parents first with their depth", func(t *testing.T) {
+		var seen []string
+		Walk(tree, func(n *Result, depth int) bool {
+			seen = append(seen, strings.Repeat(">", depth)+n.Token)
+			return true
+		})
+		require.Equal(t, []string{"root", ">a", ">>a1", ">>a2", ">b", ">>b1"}, seen)
+	})
+
+	t.Run("prunes children", func(t *testing.T) {
+		var seen []string
+		Walk(tree, func(n *Result, depth int) bool {
+			seen = append(seen, n.Token)
+			return n.Token != "a"
+		})
+		require.Equal(t, []string{"root", "a", "b", "b1"}, seen)
+	})
+
+	t.Run("changes results in place", func(t *testing.T) {
+		tree := &Result{Child: []Result{{Token: "x"}}}
+		Walk(tree, func(n *Result, depth int) bool {
+			n.Token = strings.ToUpper(n.Token)
+			return true
+		})
+		require.Equal(t, "X", tree.Child[0].Token)
+	})
+}
+
+type pathRecorder struct {
+	events []string
+}
+
+func (r *pathRecorder) Enter(n *Result, parents []*Result) bool {
+	var path []string
+	for _, parent := range parents {
+		path = append(path, parent.Token)
+	}
+	r.events = append(r.events, "enter "+strings.Join(append(path, n.Token), "/"))
+	return n.Token != "b"
+}
+
+func (r *pathRecorder) Exit(n *Result, parents []*Result) {
+	r.events = append(r.events, "exit "+n.Token)
+}
+
+func TestVisit(t *testing.T) {
+	tree := &Result{Token: "root", Child: []Result{
+		{Token: "a", Child: []Result{{Token: "a1"}}},
+		{Token: "b", Child: []Result{{Token: "b1"}}},
+	}}
+
+	r := &pathRecorder{}
+	Visit(tree, r)
+	require.Equal(t, []string{
+		"enter root",
+		"enter root/a",
+		"enter root/a/a1",
+		"exit a1",
+		"exit a",
+		"enter root/b",
+		"exit b",
+		"exit root",
+	}, r.events)
+}